@@ -0,0 +1,204 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestAccountDeletion(t *testing.T) {
+	r, authHandler := testutils.SetupAuthHandler()
+	userService := services.NewUserService(testutils.TestDB)
+	middlewares.SetAccountCheck(userService.Active)
+	defer middlewares.SetAccountCheck(nil)
+
+	// ルートの設定
+	r.POST("/auth/login", authHandler.LoginUser)
+	r.POST("/auth/cancel-deletion", authHandler.CancelDeletion)
+	r.GET("/auth/me", middlewares.AuthMiddleware(), authHandler.GetMe)
+	r.DELETE("/users/me", middlewares.AuthMiddleware(), authHandler.DeleteAccount)
+
+	authService := services.NewAuthService(testutils.TestDB)
+	user := models.User{Email: "leaver@example.com", Handle: "leaver", Password: "password123"}
+	assert.NoError(t, authService.SignUp(&user))
+	friend := models.User{Email: "friend@example.com", Handle: "friend", Password: "password123"}
+	assert.NoError(t, authService.SignUp(&friend))
+	token, err := utils.GenerateJWTToken(user)
+	assert.NoError(t, err)
+
+	micropostService := services.NewMicropostService(testutils.TestDB)
+	post := models.Micropost{Title: "Goodbye", UserID: user.ID}
+	assert.NoError(t, micropostService.Create(&post))
+	_, err = services.NewLikeService(testutils.TestDB).Like(friend.ID, post.ID)
+	assert.NoError(t, err)
+	_, err = services.NewFollowService(testutils.TestDB).Follow(friend.ID, user.ID)
+	assert.NoError(t, err)
+	member := models.User{Email: "member@example.com", Handle: "member", Password: "password123"}
+	assert.NoError(t, authService.SignUp(&member))
+	conversationService := services.NewConversationService(testutils.TestDB)
+	group, _, err := conversationService.Start(user.ID, models.ConversationRequest{ParticipantIDs: []uint{friend.ID, member.ID}, Title: "Group"})
+	assert.NoError(t, err)
+	message, err := conversationService.Send(friend.ID, group.ID, models.MessageRequest{Body: "Hi all"})
+	assert.NoError(t, err)
+
+	request := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	credentials := models.LoginRequest{Email: user.Email, Password: "password123"}
+
+	t.Run("delete", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			body           interface{}
+			expectedStatus int
+		}{
+			{name: "Missing Password", body: map[string]string{}, expectedStatus: http.StatusBadRequest},
+			{name: "Wrong Password", body: models.AccountDeletionRequest{Password: "wrong-password"}, expectedStatus: http.StatusForbidden},
+			{name: "Confirmed", body: models.AccountDeletionRequest{Password: "password123"}, expectedStatus: http.StatusAccepted},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := request(http.MethodDelete, "/users/me", token, tt.body)
+				assert.Equal(t, tt.expectedStatus, w.Code)
+			})
+		}
+
+		// 既存のトークンは使えなくなり、ログインすると退会済みと分かる
+		w := request(http.MethodGet, "/auth/me", token, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		w = request(http.MethodPost, "/auth/login", "", credentials)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var response map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, i18n.ErrAccountDeleted, response["code"])
+
+		_, err := micropostService.GetByID(post.ID, friend.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		w := request(http.MethodPost, "/auth/cancel-deletion", "", models.LoginRequest{Email: user.Email, Password: "wrong-password"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = request(http.MethodPost, "/auth/cancel-deletion", "", credentials)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.LoginResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "/auth/me", response.Token, nil).Code)
+
+		microposts, err := micropostService.GetAll(friend.ID)
+		assert.NoError(t, err)
+		assert.Len(t, microposts, 1)
+
+		// 取り消した後は再度の取り消しはできない
+		w = request(http.MethodPost, "/auth/cancel-deletion", "", credentials)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		token = response.Token
+	})
+
+	t.Run("accounts removed by an admin cannot be cancelled", func(t *testing.T) {
+		assert.NoError(t, userService.Delete(friend.ID))
+		w := request(http.MethodPost, "/auth/cancel-deletion", "", models.LoginRequest{Email: friend.Email, Password: "password123"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		w = request(http.MethodPost, "/auth/login", "", models.LoginRequest{Email: friend.Email, Password: "password123"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		_, err := userService.Restore(friend.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("purge after the grace period", func(t *testing.T) {
+		avatar := filepath.Join("uploads", "avatars", "user_leaver_test.png")
+		assert.NoError(t, os.MkdirAll(filepath.Dir(avatar), 0755))
+		assert.NoError(t, os.WriteFile(avatar, []byte("png"), 0644))
+		defer os.Remove(avatar)
+		assert.NoError(t, testutils.TestDB.Model(&user).Update("avatar_path", "/"+filepath.ToSlash(avatar)).Error)
+
+		w := request(http.MethodDelete, "/users/me", token, models.AccountDeletionRequest{Password: "password123"})
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		past := time.Now().Add(-models.TrashRetention - time.Hour)
+		assert.NoError(t, testutils.TestDB.Unscoped().Model(&models.User{}).Where("id = ?", user.ID).Update("deleted_at", past).Error)
+		assert.NoError(t, testutils.TestDB.Unscoped().Model(&models.Micropost{}).Where("user_id = ?", user.ID).Update("deleted_at", past).Error)
+
+		w = request(http.MethodPost, "/auth/cancel-deletion", "", credentials)
+		assert.Equal(t, http.StatusGone, w.Code)
+
+		result, err := services.NewTrashService(testutils.TestDB).Purge(time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Users)
+
+		// 投稿・いいね・フォローとアバター画像も削除される
+		counts := map[string]*gorm.DB{
+			"users":      testutils.TestDB.Unscoped().Model(&models.User{}).Where("id = ?", user.ID),
+			"microposts": testutils.TestDB.Unscoped().Model(&models.Micropost{}).Where("user_id = ?", user.ID),
+			"likes":      testutils.TestDB.Model(&models.Like{}).Where("micropost_id = ?", post.ID),
+			"follows":    testutils.TestDB.Model(&models.Follow{}).Where("followed_id = ?", user.ID),
+		}
+		for table, query := range counts {
+			var count int64
+			assert.NoError(t, query.Count(&count).Error)
+			assert.Zero(t, count, table)
+		}
+		_, err = os.Stat(avatar)
+		assert.True(t, os.IsNotExist(err))
+
+		// 作成したグループの会話と他の参加者のメッセージは残す
+		var conversation models.Conversation
+		assert.NoError(t, testutils.TestDB.First(&conversation, group.ID).Error)
+		assert.Nil(t, conversation.CreatedByID)
+		assert.NoError(t, testutils.TestDB.First(&models.Message{}, message.ID).Error)
+	})
+
+	t.Run("avatar paths outside the upload directory are never removed", func(t *testing.T) {
+		victim := "purge_victim_test.txt"
+		assert.NoError(t, os.WriteFile(victim, []byte("keep"), 0644))
+		defer os.Remove(victim)
+
+		for _, avatarPath := range []string{victim, "/" + victim, "/uploads/avatars/../../" + victim, "/../handlers/" + victim} {
+			assert.NoError(t, utils.RemoveAvatarFile(avatarPath))
+			_, err := os.Stat(victim)
+			assert.NoError(t, err, avatarPath)
+		}
+	})
+
+	t.Run("moderators are purged and their audit log remains", func(t *testing.T) {
+		moderator := models.User{Email: "moderator@example.com", Handle: "moderator", Password: "password123", Role: models.RoleAdmin}
+		assert.NoError(t, testutils.TestDB.Create(&moderator).Error)
+		action := models.ModerationAction{ModeratorID: &moderator.ID, Action: models.ModerationActionDismiss}
+		assert.NoError(t, testutils.TestDB.Create(&action).Error)
+
+		past := time.Now().Add(-models.TrashRetention - time.Hour)
+		assert.NoError(t, testutils.TestDB.Model(&moderator).Update("deleted_at", past).Error)
+
+		result, err := services.NewTrashService(testutils.TestDB).Purge(time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Users)
+
+		assert.NoError(t, testutils.TestDB.First(&action, action.ID).Error)
+		assert.Nil(t, action.ModeratorID)
+	})
+}
@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"go-gin-gorm-minimum/i18n"
 	"go-gin-gorm-minimum/models"
 	"go-gin-gorm-minimum/services"
 	"go-gin-gorm-minimum/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
@@ -24,28 +27,38 @@ func NewAuthHandler(authService *services.AuthService, userService *services.Use
 
 // SignupUser godoc
 // @Summary      Signup user
-// @Description  Signup user with the given information
+// @Description  Signup user with the given information. The handle is optional and generated from the email address when omitted.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
-// @Param        user body models.User true "User object" default({"email":"user1@example.com","password":"password123","role":"user","avatar_path":"/avatars/default.png"})
+// @Param        user body models.User true "User object" default({"email":"user1@example.com","handle":"user1","password":"password123","avatar_path":"/avatars/default.png"})
 // @Success      201  {object}  models.UserResponse
 // @Router       /auth/signup [post]
 func (h *AuthHandler) SignupUser(c *gin.Context) {
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
 		return
 	}
 
-	_, err := h.userService.FindByEmail(user.Email)
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
+	taken, err := h.userService.EmailTaken(user.Email)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToCreateUser)
+		return
+	}
+	if taken {
+		utils.ErrorJSON(c, http.StatusConflict, i18n.ErrEmailAlreadyExists)
 		return
 	}
+	if user.Handle != "" {
+		if taken, err := h.userService.HandleTaken(user.Handle); err == nil && taken {
+			utils.ErrorJSON(c, http.StatusConflict, i18n.ErrHandleAlreadyExists)
+			return
+		}
+	}
 
 	if err := h.authService.SignUp(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToCreateUser)
 		return
 	}
 
@@ -54,29 +67,115 @@ func (h *AuthHandler) SignupUser(c *gin.Context) {
 
 // LoginUser godoc
 // @Summary      Login user
-// @Description  Login user with the given email and password
+// @Description  Login user with the given email and password. An account pending self-service deletion gets 403 account_deleted and can be reactivated with POST /auth/cancel-deletion.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        user body models.LoginRequest true "Login credentials"
 // @Success      200  {object}  models.LoginResponse
+// @Failure      403  {object}  map[string]string
 // @Router       /auth/login [post]
 func (h *AuthHandler) LoginUser(c *gin.Context) {
 	var loginReq models.LoginRequest
 	if err := c.ShouldBindJSON(&loginReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
 		return
 	}
 
 	response, err := h.authService.Login(loginReq.Email, loginReq.Password)
+	if errors.Is(err, services.ErrAccountSuspended) {
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrAccountSuspended)
+		return
+	}
+	if errors.Is(err, services.ErrAccountDeleted) {
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrAccountDeleted)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, utils.ErrInvalidCredentials)
+		utils.ErrorJSON(c, http.StatusUnauthorized, i18n.ErrInvalidCredentials)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CancelDeletion godoc
+// @Summary      Cancel account deletion
+// @Description  Cancel a pending self-service account deletion during the grace period and log in. The account and the microposts deleted with it are restored. Accounts removed by an admin cannot be restored this way.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        user body models.LoginRequest true "Login credentials"
+// @Success      200  {object}  models.LoginResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      410  {object}  map[string]string
+// @Router       /auth/cancel-deletion [post]
+func (h *AuthHandler) CancelDeletion(c *gin.Context) {
+	var loginReq models.LoginRequest
+	if err := c.ShouldBindJSON(&loginReq); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	response, err := h.authService.CancelDeletion(loginReq.Email, loginReq.Password)
+	switch {
+	case errors.Is(err, services.ErrRestoreExpired):
+		utils.ErrorJSON(c, http.StatusGone, i18n.ErrRestoreExpired)
+		return
+	case errors.Is(err, services.ErrAccountSuspended):
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrAccountSuspended)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusUnauthorized, i18n.ErrInvalidCredentials)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// DeleteAccount godoc
+// @Summary      Delete current user's account
+// @Description  Delete the current user's account after re-confirming the password. The account and its microposts are hidden immediately and permanently removed, together with likes, follows, notifications and the uploaded avatar, at purge_at. Until then the deletion can be cancelled with POST /auth/cancel-deletion. Existing tokens stop working right away.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        confirmation body models.AccountDeletionRequest true "Current password"
+// @Success      202  {object}  models.AccountDeletionResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /users/me [delete]
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.AccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	purgeAt, err := h.authService.DeleteAccount(userID, req.Password)
+	switch {
+	case errors.Is(err, services.ErrIncorrectPassword):
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrIncorrectPassword)
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrUserNotFound)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToDeleteUser)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.AccountDeletionResponse{PurgeAt: purgeAt})
+}
+
 // GetMe godoc
 // @Summary      Get current user
 // @Description  get current user information from token
@@ -90,13 +189,13 @@ func (h *AuthHandler) LoginUser(c *gin.Context) {
 func (h *AuthHandler) GetMe(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	if userID == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		utils.ErrorJSON(c, http.StatusUnauthorized, i18n.ErrUnauthorized)
 		return
 	}
 
 	user, err := h.authService.GetUserByID(userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrUserNotFound)
 		return
 	}
 
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BlockHandler struct {
+	blockService *services.BlockService
+}
+
+func NewBlockHandler(blockService *services.BlockService) *BlockHandler {
+	return &BlockHandler{blockService: blockService}
+}
+
+// BlockUser godoc
+// @Summary      Block user
+// @Description  Block the user with the given ID (idempotent). Follows in both directions are removed, and neither user can follow, reply to, mention or message the other until unblocked.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  models.ProfileResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /users/{id}/block [post]
+func (h *BlockHandler) BlockUser(c *gin.Context) {
+	h.updateRelation(c, h.blockService.Block, i18n.ErrFailedToUpdateBlock)
+}
+
+// UnblockUser godoc
+// @Summary      Unblock user
+// @Description  Unblock the user with the given ID (idempotent). Removed follows are not restored.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  models.ProfileResponse
+// @Failure      404  {object}  map[string]string
+// @Router       /users/{id}/block [delete]
+func (h *BlockHandler) UnblockUser(c *gin.Context) {
+	h.updateRelation(c, h.blockService.Unblock, i18n.ErrFailedToUpdateBlock)
+}
+
+// MuteUser godoc
+// @Summary      Mute user
+// @Description  Mute the user with the given ID (idempotent). Their posts, reposts of their posts and their notifications are hidden from the current user's feed, search and notifications. The muted user is not told.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  models.ProfileResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /users/{id}/mute [post]
+func (h *BlockHandler) MuteUser(c *gin.Context) {
+	h.updateRelation(c, h.blockService.Mute, i18n.ErrFailedToUpdateMute)
+}
+
+// UnmuteUser godoc
+// @Summary      Unmute user
+// @Description  Unmute the user with the given ID (idempotent)
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  models.ProfileResponse
+// @Failure      404  {object}  map[string]string
+// @Router       /users/{id}/mute [delete]
+func (h *BlockHandler) UnmuteUser(c *gin.Context) {
+	h.updateRelation(c, h.blockService.Unmute, i18n.ErrFailedToUpdateMute)
+}
+
+func (h *BlockHandler) updateRelation(c *gin.Context, update func(userID, targetID uint) (models.User, error), failure string) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := update(userID, targetID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrUserNotFound)
+		return
+	case errors.Is(err, services.ErrCannotBlockSelf):
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrCannotBlockSelf)
+		return
+	case errors.Is(err, services.ErrCannotMuteSelf):
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrCannotMuteSelf)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, failure)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToProfileResponse())
+}
+
+// GetBlockedUsers godoc
+// @Summary      List blocked users
+// @Description  List the users the current user has blocked, most recent first
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.UserListResponse
+// @Failure      401  {object}  map[string]string
+// @Router       /users/me/blocks [get]
+func (h *BlockHandler) GetBlockedUsers(c *gin.Context) {
+	h.listUsers(c, h.blockService.Blocked)
+}
+
+// GetMutedUsers godoc
+// @Summary      List muted users
+// @Description  List the users the current user has muted, most recent first
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.UserListResponse
+// @Failure      401  {object}  map[string]string
+// @Router       /users/me/mutes [get]
+func (h *BlockHandler) GetMutedUsers(c *gin.Context) {
+	h.listUsers(c, h.blockService.Muted)
+}
+
+func (h *BlockHandler) listUsers(c *gin.Context, list func(userID uint, page models.PageQuery) ([]models.User, int64, error)) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	users, total, err := list(userID, page)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchUsers)
+		return
+	}
+
+	response := models.UserListResponse{Users: make([]models.UserResponse, 0, len(users)), PageMeta: page.Meta(total)}
+	for _, user := range users {
+		response.Users = append(response.Users, user.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
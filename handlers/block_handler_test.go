@@ -0,0 +1,216 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockUser(t *testing.T) {
+	r, blockHandler := testutils.SetupBlockHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.GET("/users/me/blocks", blockHandler.GetBlockedUsers)
+	auth.POST("/users/:id/block", blockHandler.BlockUser)
+	auth.DELETE("/users/:id/block", blockHandler.UnblockUser)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	other := models.User{Email: "other@example.com", Handle: "other", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&other).Error)
+
+	followService := services.NewFollowService(testutils.TestDB)
+	_, err = followService.Follow(user.ID, other.ID)
+	assert.NoError(t, err)
+	_, err = followService.Follow(other.ID, user.ID)
+	assert.NoError(t, err)
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		blocked        bool
+	}{
+		{name: "Block", method: http.MethodPost, path: fmt.Sprintf("/users/%d/block", other.ID), expectedStatus: http.StatusOK, blocked: true},
+		{name: "Block Again", method: http.MethodPost, path: fmt.Sprintf("/users/%d/block", other.ID), expectedStatus: http.StatusOK, blocked: true},
+		{name: "Block Self", method: http.MethodPost, path: fmt.Sprintf("/users/%d/block", user.ID), expectedStatus: http.StatusBadRequest},
+		{name: "Unknown User", method: http.MethodPost, path: "/users/999999/block", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(tt.method, tt.path)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.ProfileResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.blocked, response.BlockedByMe)
+				// お互いのフォローは解除される
+				assert.False(t, response.FollowedByMe)
+				assert.Equal(t, int64(0), response.FollowerCount)
+				assert.Equal(t, int64(0), response.FollowingCount)
+			}
+		})
+	}
+
+	w := request(http.MethodGet, "/users/me/blocks")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list models.UserListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, int64(1), list.Total)
+	assert.Equal(t, other.ID, list.Users[0].ID)
+
+	t.Run("blocks work in both directions", func(t *testing.T) {
+		for _, pair := range [][2]uint{{user.ID, other.ID}, {other.ID, user.ID}} {
+			_, err := followService.Follow(pair[0], pair[1])
+			assert.ErrorIs(t, err, services.ErrBlocked)
+
+			micropostService := services.NewMicropostService(testutils.TestDB)
+			parent := models.Micropost{Title: "Parent", UserID: pair[1]}
+			assert.NoError(t, micropostService.Create(&parent))
+			err = micropostService.CreateReply(parent.ID, &models.Micropost{Title: "Reply", UserID: pair[0]})
+			assert.ErrorIs(t, err, services.ErrBlocked)
+
+			var blocked models.User
+			assert.NoError(t, testutils.TestDB.First(&blocked, pair[1]).Error)
+			mention := models.Micropost{Title: "Hi @" + blocked.Handle, UserID: pair[0]}
+			assert.NoError(t, micropostService.Create(&mention))
+			assert.Empty(t, mention.Mentions)
+
+			conversationService := services.NewConversationService(testutils.TestDB)
+			_, _, err = conversationService.Start(pair[0], models.ConversationRequest{ParticipantIDs: []uint{pair[1]}})
+			assert.ErrorIs(t, err, services.ErrBlocked)
+		}
+	})
+
+	t.Run("group conversations with a blocked pair", func(t *testing.T) {
+		third := models.User{Email: "third@example.com", Handle: "third", Password: "password123"}
+		assert.NoError(t, testutils.TestDB.Create(&third).Error)
+		conversationService := services.NewConversationService(testutils.TestDB)
+
+		_, _, err := conversationService.Start(third.ID, models.ConversationRequest{ParticipantIDs: []uint{user.ID, other.ID}, Title: "Group"})
+		assert.ErrorIs(t, err, services.ErrBlocked)
+
+		// ブロックする前に作られたグループでも、ブロックした相手がいるグループには送れない
+		group := models.Conversation{IsGroup: true, Title: "Before the block", CreatedByID: &third.ID}
+		assert.NoError(t, testutils.TestDB.Omit("Participants").Create(&group).Error)
+		for _, id := range []uint{third.ID, user.ID, other.ID} {
+			assert.NoError(t, testutils.TestDB.Create(&models.ConversationParticipant{ConversationID: group.ID, UserID: id}).Error)
+		}
+		_, err = conversationService.Send(other.ID, group.ID, models.MessageRequest{Body: "Hello"})
+		assert.ErrorIs(t, err, services.ErrBlocked)
+		_, err = conversationService.Send(third.ID, group.ID, models.MessageRequest{Body: "Hello"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("unblock", func(t *testing.T) {
+		w := request(http.MethodDelete, fmt.Sprintf("/users/%d/block", other.ID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.ProfileResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.False(t, response.BlockedByMe)
+
+		_, err := followService.Follow(user.ID, other.ID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestMuteUser(t *testing.T) {
+	r, blockHandler := testutils.SetupBlockHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.GET("/users/me/mutes", blockHandler.GetMutedUsers)
+	auth.POST("/users/:id/mute", blockHandler.MuteUser)
+	auth.DELETE("/users/:id/mute", blockHandler.UnmuteUser)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	muted := models.User{Email: "noisy@example.com", Handle: "noisy", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&muted).Error)
+
+	micropostService := services.NewMicropostService(testutils.TestDB)
+	notificationService := services.NewNotificationService(testutils.TestDB)
+	mine := models.Micropost{Title: "Mine", UserID: user.ID}
+	assert.NoError(t, micropostService.Create(&mine))
+	noisy := models.Micropost{Title: "Noisy keyword", UserID: muted.ID}
+	assert.NoError(t, micropostService.Create(&noisy))
+	_, err = services.NewLikeService(testutils.TestDB).Like(muted.ID, mine.ID)
+	assert.NoError(t, err)
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	visible := func() (feed int, search int64, notifications int64) {
+		microposts, err := micropostService.GetAll(user.ID)
+		assert.NoError(t, err)
+		_, total, _, err := micropostService.Search(user.ID, models.MicropostSearchQuery{Q: "keyword"})
+		assert.NoError(t, err)
+		unread, err := notificationService.UnreadCount(user.ID)
+		assert.NoError(t, err)
+		return len(microposts), total, unread
+	}
+
+	feed, search, notifications := visible()
+	assert.Equal(t, 2, feed)
+	assert.Equal(t, int64(1), search)
+	assert.Equal(t, int64(1), notifications)
+
+	w := request(http.MethodPost, fmt.Sprintf("/users/%d/mute", muted.ID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.ProfileResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.MutedByMe)
+
+	feed, search, notifications = visible()
+	assert.Equal(t, 1, feed)
+	assert.Equal(t, int64(0), search)
+	assert.Equal(t, int64(0), notifications)
+
+	// ミュート中の操作は通知しない（ミュートしても相手の操作は妨げない）
+	repost, err := micropostService.Repost(muted.ID, mine.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, repost)
+	var count int64
+	testutils.TestDB.Model(&models.Notification{}).Where("user_id = ? AND type = ?", user.ID, models.NotificationTypeRepost).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	w = request(http.MethodGet, "/users/me/mutes")
+	var list models.UserListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, int64(1), list.Total)
+
+	w = request(http.MethodPost, fmt.Sprintf("/users/%d/mute", user.ID))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(http.MethodDelete, fmt.Sprintf("/users/%d/mute", muted.ID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	feed, _, notifications = visible()
+	assert.Equal(t, 3, feed)
+	assert.Equal(t, int64(1), notifications)
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ConversationHandler struct {
+	conversationService *services.ConversationService
+}
+
+func NewConversationHandler(conversationService *services.ConversationService) *ConversationHandler {
+	return &ConversationHandler{conversationService: conversationService}
+}
+
+// StartConversation godoc
+// @Summary      Start conversation
+// @Description  Start a private conversation with the given users. With one other user the existing 1:1 conversation is returned (200) if there is one; with two or more a new group conversation is created. Groups have at most 10 participants including the current user.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        conversation body models.ConversationRequest true "Participants"
+// @Success      200  {object}  models.ConversationResponse
+// @Success      201  {object}  models.ConversationResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /conversations [post]
+func (h *ConversationHandler) StartConversation(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+	req.Normalize(userID)
+	if err := req.Validate(); err != nil {
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrInvalidParticipants, models.MaxConversationParticipants-1)
+		return
+	}
+
+	conversation, created, err := h.conversationService.Start(userID, req)
+	switch {
+	case errors.Is(err, models.ErrInvalidParticipants):
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrInvalidParticipants, models.MaxConversationParticipants-1)
+		return
+	case errors.Is(err, services.ErrBlocked):
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrBlocked)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToStartConversation)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, conversation.ToResponse())
+}
+
+// GetConversations godoc
+// @Summary      List conversations
+// @Description  List the current user's conversations, most recent message first, with the last message and the number of unread messages in each. unread_count at the top level is the total over all conversations.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.ConversationListResponse
+// @Failure      401  {object}  map[string]string
+// @Router       /conversations [get]
+func (h *ConversationHandler) GetConversations(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	conversations, total, err := h.conversationService.List(userID, page)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchConversations)
+		return
+	}
+	unread, err := h.conversationService.UnreadCount(userID)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchConversations)
+		return
+	}
+
+	response := models.ConversationListResponse{
+		Conversations: make([]models.ConversationResponse, 0, len(conversations)),
+		UnreadCount:   unread,
+		PageMeta:      page.Meta(total),
+	}
+	for _, conversation := range conversations {
+		response.Conversations = append(response.Conversations, conversation.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUnreadMessageCount godoc
+// @Summary      Count unread messages
+// @Description  Number of unread messages over all of the current user's conversations
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.UnreadCountResponse
+// @Failure      401  {object}  map[string]string
+// @Router       /conversations/unread-count [get]
+func (h *ConversationHandler) GetUnreadMessageCount(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.conversationService.UnreadCount(userID)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchConversations)
+		return
+	}
+	c.JSON(http.StatusOK, models.UnreadCountResponse{UnreadCount: count})
+}
+
+// GetConversation godoc
+// @Summary      Get conversation
+// @Description  Get a conversation the current user participates in. Each participant's last_read_message_id can be used to show read receipts.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Conversation ID"
+// @Success      200  {object}  models.ConversationResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /conversations/{id} [get]
+func (h *ConversationHandler) GetConversation(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	conversation, err := h.conversationService.Get(userID, id)
+	if h.handleError(c, err, i18n.ErrFailedToFetchConversations) {
+		return
+	}
+	c.JSON(http.StatusOK, conversation.ToResponse())
+}
+
+// GetMessages godoc
+// @Summary      List messages
+// @Description  List messages in a conversation, newest first. Pass next_cursor as before to fetch older messages.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      int  true   "Conversation ID"
+// @Param        before  query     int  false  "Only messages with a smaller ID"
+// @Param        limit   query     int  false  "Number of messages (max 100)"
+// @Success      200  {object}  models.MessageListResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /conversations/{id}/messages [get]
+func (h *ConversationHandler) GetMessages(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var query models.CursorQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	messages, hasMore, err := h.conversationService.Messages(userID, id, query)
+	if h.handleError(c, err, i18n.ErrFailedToFetchConversations) {
+		return
+	}
+
+	response := models.MessageListResponse{
+		Messages:   make([]models.MessageResponse, 0, len(messages)),
+		CursorMeta: models.CursorMeta{HasMore: hasMore},
+	}
+	for _, message := range messages {
+		response.Messages = append(response.Messages, message.ToResponse())
+	}
+	if hasMore {
+		next := messages[len(messages)-1].ID
+		response.NextCursor = &next
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SendMessage godoc
+// @Summary      Send message
+// @Description  Send a message to a conversation the current user participates in. The message is delivered to participants over the event stream.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                    true  "Conversation ID"
+// @Param        message  body      models.MessageRequest  true  "Message"
+// @Success      201  {object}  models.MessageResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /conversations/{id}/messages [post]
+func (h *ConversationHandler) SendMessage(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.MessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+	req.Normalize()
+	switch err := req.Validate(); {
+	case errors.Is(err, models.ErrMessageBodyRequired):
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrMessageBodyRequired)
+		return
+	case errors.Is(err, models.ErrMessageBodyTooLong):
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrMessageBodyTooLong, models.MessageBodyMaxLength)
+		return
+	}
+
+	message, err := h.conversationService.Send(userID, id, req)
+	if h.handleError(c, err, i18n.ErrFailedToSendMessage) {
+		return
+	}
+	c.JSON(http.StatusCreated, message.ToResponse())
+}
+
+// MarkConversationRead godoc
+// @Summary      Mark conversation as read
+// @Description  Move the current user's read position to message_id (or to the latest message when omitted). The read position never moves backwards.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int                 true   "Conversation ID"
+// @Param        read  body      models.ReadRequest  false  "Last read message"
+// @Success      200  {object}  models.ConversationResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /conversations/{id}/read [post]
+func (h *ConversationHandler) MarkConversationRead(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ReadRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	conversation, err := h.conversationService.MarkRead(userID, id, req.MessageID)
+	if h.handleError(c, err, i18n.ErrFailedToUpdateConversation) {
+		return
+	}
+	c.JSON(http.StatusOK, conversation.ToResponse())
+}
+
+// handleError は会話に参加していない（または存在しない）場合は 404、ブロックされている場合は 403、
+// それ以外のエラーは 500 を返し、エラーがあれば true を返す
+func (h *ConversationHandler) handleError(c *gin.Context, err error, code string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrConversationNotFound)
+	case errors.Is(err, services.ErrBlocked):
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrBlocked)
+	default:
+		utils.ErrorJSON(c, http.StatusInternalServerError, code)
+	}
+	return true
+}
@@ -0,0 +1,201 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/testutils"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversations(t *testing.T) {
+	r, conversationHandler := testutils.SetupConversationHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.POST("/conversations", conversationHandler.StartConversation)
+	auth.GET("/conversations", conversationHandler.GetConversations)
+	auth.GET("/conversations/unread-count", conversationHandler.GetUnreadMessageCount)
+	auth.GET("/conversations/:id", conversationHandler.GetConversation)
+	auth.GET("/conversations/:id/messages", conversationHandler.GetMessages)
+	auth.POST("/conversations/:id/messages", conversationHandler.SendMessage)
+	auth.POST("/conversations/:id/read", conversationHandler.MarkConversationRead)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	tokens := map[uint]string{user.ID: token}
+	var others []models.User
+	for _, handle := range []string{"alice", "bob", "mallory"} {
+		other := models.User{Email: handle + "@example.com", Handle: handle, Password: "password123"}
+		assert.NoError(t, testutils.TestDB.Create(&other).Error)
+		tokens[other.ID], err = utils.GenerateJWTToken(other)
+		assert.NoError(t, err)
+		others = append(others, other)
+	}
+	alice, bob, mallory := others[0], others[1], others[2]
+
+	request := func(userID uint, method, path string, body interface{}) *httptest.ResponseRecorder {
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+tokens[userID])
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder, v interface{}) {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), v))
+	}
+
+	// 1対1の会話は同じ2人なら再利用する
+	w := request(user.ID, http.MethodPost, "/conversations", gin.H{"participant_ids": []uint{alice.ID}})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var direct models.ConversationResponse
+	decode(w, &direct)
+	assert.False(t, direct.IsGroup)
+	assert.Len(t, direct.Participants, 2)
+
+	w = request(alice.ID, http.MethodPost, "/conversations", gin.H{"participant_ids": []uint{user.ID, alice.ID}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var reused models.ConversationResponse
+	decode(w, &reused)
+	assert.Equal(t, direct.ID, reused.ID)
+
+	t.Run("invalid participants", func(t *testing.T) {
+		tests := []struct {
+			name string
+			ids  []uint
+		}{
+			{"only self", []uint{user.ID}},
+			{"unknown user", []uint{alice.ID, 999999}},
+			{"too many", []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := request(user.ID, http.MethodPost, "/conversations", gin.H{"participant_ids": tt.ids})
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+			})
+		}
+	})
+
+	// メッセージを送信して未読数を確認する
+	messagesPath := fmt.Sprintf("/conversations/%d/messages", direct.ID)
+	var sent []models.MessageResponse
+	for i := 1; i <= 5; i++ {
+		w := request(user.ID, http.MethodPost, messagesPath, gin.H{"body": fmt.Sprintf("message %d", i)})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var message models.MessageResponse
+		decode(w, &message)
+		assert.Equal(t, user.ID, message.Sender.ID)
+		sent = append(sent, message)
+	}
+	w = request(user.ID, http.MethodPost, messagesPath, gin.H{"body": "   "})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(alice.ID, http.MethodGet, "/conversations", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list models.ConversationListResponse
+	decode(w, &list)
+	assert.Equal(t, int64(1), list.Total)
+	assert.Equal(t, int64(5), list.UnreadCount)
+	assert.Equal(t, int64(5), list.Conversations[0].UnreadCount)
+	assert.Equal(t, "message 5", list.Conversations[0].LastMessage.Body)
+
+	// 自分の送信したメッセージは未読にならない
+	w = request(user.ID, http.MethodGet, "/conversations/unread-count", nil)
+	var unread models.UnreadCountResponse
+	decode(w, &unread)
+	assert.Equal(t, int64(0), unread.UnreadCount)
+
+	t.Run("cursor pagination", func(t *testing.T) {
+		w := request(alice.ID, http.MethodGet, messagesPath+"?limit=2", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var page models.MessageListResponse
+		decode(w, &page)
+		assert.Len(t, page.Messages, 2)
+		assert.Equal(t, "message 5", page.Messages[0].Body)
+		assert.True(t, page.HasMore)
+
+		w = request(alice.ID, http.MethodGet, fmt.Sprintf("%s?limit=3&before=%d", messagesPath, *page.NextCursor), nil)
+		decode(w, &page)
+		assert.Len(t, page.Messages, 3)
+		assert.Equal(t, "message 1", page.Messages[2].Body)
+		assert.False(t, page.HasMore)
+		assert.Nil(t, page.NextCursor)
+	})
+
+	t.Run("read receipts", func(t *testing.T) {
+		readPath := fmt.Sprintf("/conversations/%d/read", direct.ID)
+		w := request(alice.ID, http.MethodPost, readPath, gin.H{"message_id": sent[2].ID})
+		assert.Equal(t, http.StatusOK, w.Code)
+		var conversation models.ConversationResponse
+		decode(w, &conversation)
+		assert.Equal(t, int64(2), conversation.UnreadCount)
+
+		// 既読位置は戻らない
+		request(alice.ID, http.MethodPost, readPath, gin.H{"message_id": sent[0].ID})
+		w = request(user.ID, http.MethodGet, fmt.Sprintf("/conversations/%d", direct.ID), nil)
+		decode(w, &conversation)
+		for _, p := range conversation.Participants {
+			if p.ID == alice.ID {
+				assert.Equal(t, sent[2].ID, p.LastReadMessageID)
+			}
+		}
+
+		w = request(alice.ID, http.MethodPost, readPath, nil)
+		decode(w, &conversation)
+		assert.Equal(t, int64(0), conversation.UnreadCount)
+	})
+
+	t.Run("group conversation", func(t *testing.T) {
+		w := request(user.ID, http.MethodPost, "/conversations", gin.H{"participant_ids": []uint{alice.ID, bob.ID}, "title": "trip"})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var group models.ConversationResponse
+		decode(w, &group)
+		assert.True(t, group.IsGroup)
+		assert.Equal(t, "trip", group.Title)
+		assert.Len(t, group.Participants, 3)
+
+		w = request(bob.ID, http.MethodPost, fmt.Sprintf("/conversations/%d/messages", group.ID), gin.H{"body": "hi all"})
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("only participants can access", func(t *testing.T) {
+		id := direct.ID
+		tests := []struct {
+			name   string
+			method string
+			path   string
+			body   interface{}
+		}{
+			{"get", http.MethodGet, fmt.Sprintf("/conversations/%d", id), nil},
+			{"messages", http.MethodGet, fmt.Sprintf("/conversations/%d/messages", id), nil},
+			{"send", http.MethodPost, fmt.Sprintf("/conversations/%d/messages", id), gin.H{"body": "let me in"}},
+			{"read", http.MethodPost, fmt.Sprintf("/conversations/%d/read", id), nil},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := request(mallory.ID, tt.method, tt.path, tt.body)
+				assert.Equal(t, http.StatusNotFound, w.Code)
+			})
+		}
+
+		w := request(mallory.ID, http.MethodGet, "/conversations", nil)
+		var list models.ConversationListResponse
+		decode(w, &list)
+		assert.Equal(t, int64(0), list.Total)
+	})
+}
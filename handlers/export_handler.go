@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ExportHandler struct {
+	exportService *services.ExportService
+}
+
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// CreateExport godoc
+// @Summary      Request data export
+// @Description  Start an export of everything stored about the current user: profile, microposts (including trashed ones), likes, follows, blocks, mutes, sent messages and the uploaded avatar. The archive is a ZIP of JSON and CSV files built in the background; poll the export until its status is completed, then fetch download_url. Only one export can be in progress at a time.
+// @Tags         exports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      202  {object}  models.DataExportResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /exports [post]
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.exportService.Request(userID)
+	switch {
+	case errors.Is(err, services.ErrExportInProgress):
+		utils.ErrorJSON(c, http.StatusConflict, i18n.ErrExportInProgress)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToCreateExport)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, export.ToResponse())
+}
+
+// GetExports godoc
+// @Summary      List data exports
+// @Description  List the current user's data exports, newest first
+// @Tags         exports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.DataExportListResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Router       /exports [get]
+func (h *ExportHandler) GetExports(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	exports, total, err := h.exportService.List(userID, page)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchExports)
+		return
+	}
+
+	response := models.DataExportListResponse{Exports: make([]models.DataExportResponse, 0, len(exports)), PageMeta: page.Meta(total)}
+	for _, export := range exports {
+		response.Exports = append(response.Exports, export.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetExport godoc
+// @Summary      Get data export
+// @Description  Get the status of one of the current user's data exports. download_url is set while the archive can be downloaded.
+// @Tags         exports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Export ID"
+// @Success      200  {object}  models.DataExportResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /exports/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	export, err := h.exportService.Get(id, userID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrExportNotFound)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchExports)
+		return
+	}
+
+	c.JSON(http.StatusOK, export.ToResponse())
+}
+
+// DownloadExport godoc
+// @Summary      Download data export
+// @Description  Download the ZIP archive of a completed data export. Only the owner can download it, and only until expires_at; the archive is deleted afterwards.
+// @Tags         exports
+// @Produce      application/zip
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Export ID"
+// @Success      200  {file}    file
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      410  {object}  map[string]string
+// @Router       /exports/{id}/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	filePath, err := h.exportService.Download(id, userID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrExportNotFound)
+		return
+	case errors.Is(err, services.ErrExportNotReady):
+		utils.ErrorJSON(c, http.StatusConflict, i18n.ErrExportNotReady)
+		return
+	case errors.Is(err, services.ErrExportExpired):
+		utils.ErrorJSON(c, http.StatusGone, i18n.ErrExportExpired)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchExports)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(filePath, fmt.Sprintf("data-export-%d.zip", id))
+}
@@ -0,0 +1,163 @@
+package handlers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataExport(t *testing.T) {
+	r, handler, exportService, queue := testutils.SetupExportHandler(t.TempDir())
+
+	// ルートの設定
+	auth := r.Group("/exports")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.POST("", handler.CreateExport)
+	auth.GET("", handler.GetExports)
+	auth.GET("/:id", handler.GetExport)
+	auth.GET("/:id/download", handler.DownloadExport)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	other := models.User{Email: "other@example.com", Handle: "other", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&other).Error)
+	otherToken, err := utils.GenerateJWTToken(other)
+	assert.NoError(t, err)
+
+	micropostService := services.NewMicropostService(testutils.TestDB)
+	post := models.Micropost{Title: "Hello, \"world\"", Body: "改行を\n含む本文", UserID: user.ID}
+	assert.NoError(t, micropostService.Create(&post))
+	_, err = services.NewFollowService(testutils.TestDB).Follow(user.ID, other.ID)
+	assert.NoError(t, err)
+
+	request := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var export models.DataExportResponse
+	t.Run("request", func(t *testing.T) {
+		w := request(http.MethodPost, "/exports", token)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+		assert.Empty(t, export.DownloadURL)
+
+		assert.Equal(t, models.ExportStatusPending, export.Status)
+
+		processed, err := queue.Drain(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		w = request(http.MethodGet, fmt.Sprintf("/exports/%d", export.ID), token)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+		assert.Equal(t, models.ExportStatusCompleted, export.Status)
+		assert.NotEmpty(t, export.DownloadURL)
+		assert.Positive(t, export.Size)
+
+		w = request(http.MethodGet, "/exports", token)
+		var list models.DataExportListResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		assert.Equal(t, int64(1), list.Total)
+	})
+
+	t.Run("only one export in progress", func(t *testing.T) {
+		pending := models.DataExport{UserID: other.ID, Status: models.ExportStatusRunning}
+		assert.NoError(t, testutils.TestDB.Create(&pending).Error)
+		w := request(http.MethodPost, "/exports", otherToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = request(http.MethodGet, fmt.Sprintf("/exports/%d/download", pending.ID), otherToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("download", func(t *testing.T) {
+		path := fmt.Sprintf("/exports/%d/download", export.ID)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, path, otherToken).Code)
+
+		w := request(http.MethodGet, path, token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+
+		archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		assert.NoError(t, err)
+		files := make(map[string][]byte)
+		for _, f := range archive.File {
+			rc, err := f.Open()
+			assert.NoError(t, err)
+			files[f.Name], _ = io.ReadAll(rc)
+			rc.Close()
+		}
+		for _, name := range []string{"profile.json", "microposts.json", "microposts.csv", "likes.csv", "following.json", "followers.csv", "blocks.csv", "mutes.csv", "messages.csv"} {
+			assert.Contains(t, files, name)
+		}
+
+		var profile models.UserResponse
+		assert.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+		assert.Equal(t, user.Email, profile.Email)
+
+		rows, err := csv.NewReader(bytes.NewReader(files["microposts.csv"])).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, post.Title, rows[1][2])
+		assert.Equal(t, post.Body, rows[1][3])
+
+		var following []map[string]interface{}
+		assert.NoError(t, json.Unmarshal(files["following.json"], &following))
+		assert.Len(t, following, 1)
+		assert.Equal(t, "other", following[0]["handle"])
+	})
+
+	t.Run("avatar outside the upload directory", func(t *testing.T) {
+		// アップロード先の外を指すアバターのパスはサーバーのファイルを含めない
+		forged := models.User{Email: "forged@example.com", Handle: "forged", Password: "password123", AvatarPath: "/../handlers/export_handler_test.go"}
+		assert.NoError(t, testutils.TestDB.Create(&forged).Error)
+		forgedToken, err := utils.GenerateJWTToken(forged)
+		assert.NoError(t, err)
+
+		var forgedExport models.DataExportResponse
+		assert.NoError(t, json.Unmarshal(request(http.MethodPost, "/exports", forgedToken).Body.Bytes(), &forgedExport))
+		_, err = queue.Drain(context.Background())
+		assert.NoError(t, err)
+
+		w := request(http.MethodGet, fmt.Sprintf("/exports/%d/download", forgedExport.ID), forgedToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		assert.NoError(t, err)
+		for _, f := range archive.File {
+			assert.NotContains(t, f.Name, "files/")
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		assert.NoError(t, testutils.TestDB.Model(&models.DataExport{}).Where("id = ?", export.ID).Update("expires_at", past).Error)
+		assert.Equal(t, http.StatusGone, request(http.MethodGet, fmt.Sprintf("/exports/%d/download", export.ID), token).Code)
+
+		count, err := exportService.Cleanup(time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		w := request(http.MethodGet, fmt.Sprintf("/exports/%d", export.ID), token)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+		assert.Equal(t, models.ExportStatusExpired, export.Status)
+	})
+}
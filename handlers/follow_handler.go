@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FollowHandler struct {
+	followService *services.FollowService
+}
+
+func NewFollowHandler(followService *services.FollowService) *FollowHandler {
+	return &FollowHandler{followService: followService}
+}
+
+// FollowUser godoc
+// @Summary      Follow user
+// @Description  Follow the user with the given ID (idempotent) and return their updated profile
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  models.ProfileResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /users/{id}/follow [post]
+func (h *FollowHandler) FollowUser(c *gin.Context) {
+	h.updateFollow(c, h.followService.Follow)
+}
+
+// UnfollowUser godoc
+// @Summary      Unfollow user
+// @Description  Unfollow the user with the given ID (idempotent) and return their updated profile
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  models.ProfileResponse
+// @Failure      404  {object}  map[string]string
+// @Router       /users/{id}/follow [delete]
+func (h *FollowHandler) UnfollowUser(c *gin.Context) {
+	h.updateFollow(c, h.followService.Unfollow)
+}
+
+func (h *FollowHandler) updateFollow(c *gin.Context, update func(followerID, followedID uint) (models.User, error)) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	followedID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := update(userID, followedID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrUserNotFound)
+		return
+	case errors.Is(err, services.ErrCannotFollowSelf):
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrCannotFollowSelf)
+		return
+	case errors.Is(err, services.ErrBlocked):
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrBlocked)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToUpdateFollow)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToProfileResponse())
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type JobHandler struct {
+	jobQueue *services.JobQueue
+}
+
+func NewJobHandler(jobQueue *services.JobQueue) *JobHandler {
+	return &JobHandler{jobQueue: jobQueue}
+}
+
+// GetJobs godoc
+// @Summary      List background jobs
+// @Description  List background jobs, newest first (admin only). Defaults to dead jobs, which have exhausted their retries and stay until retried.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status    query     string  false  "Job status (pending, running, succeeded, dead; default dead)"
+// @Param        type      query     string  false  "Job type"
+// @Param        page      query     int     false  "Page number"
+// @Param        per_page  query     int     false  "Results per page (max 100)"
+// @Success      200  {object}  models.JobListResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /admin/jobs [get]
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	var query models.JobQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	jobs, total, err := h.jobQueue.Jobs(query)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchJobs)
+		return
+	}
+
+	response := models.JobListResponse{Jobs: make([]models.JobResponse, 0, len(jobs)), PageMeta: query.Meta(total)}
+	for _, job := range jobs {
+		response.Jobs = append(response.Jobs, job.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RetryJob godoc
+// @Summary      Retry dead job
+// @Description  Reset a dead job's attempts and queue it to run again (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Job ID"
+// @Success      200  {object}  models.JobResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /admin/jobs/{id}/retry [post]
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.jobQueue.Retry(id)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrJobNotFound)
+		return
+	case errors.Is(err, services.ErrJobNotRetryable):
+		utils.ErrorJSON(c, http.StatusConflict, i18n.ErrJobNotRetryable)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToRetryJob)
+		return
+	}
+
+	c.JSON(http.StatusOK, job.ToResponse())
+}
@@ -0,0 +1,262 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testJobPayload struct {
+	Fail bool `json:"fail"`
+}
+
+func TestJobQueue(t *testing.T) {
+	r, handler, queue := testutils.SetupJobHandler()
+
+	// ルートの設定
+	admin := r.Group("/admin")
+	admin.Use(middlewares.AuthMiddleware(), middlewares.AdminMiddleware())
+	admin.GET("/jobs", handler.GetJobs)
+	admin.POST("/jobs/:id/retry", handler.RetryJob)
+
+	moderator := models.User{Email: "admin@example.com", Handle: "admin", Password: "password123", Role: models.RoleAdmin}
+	assert.NoError(t, testutils.TestDB.Create(&moderator).Error)
+	adminToken, err := utils.GenerateJWTToken(moderator)
+	assert.NoError(t, err)
+	_, userToken, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+
+	var calls atomic.Int32
+	queue.Register("test.echo", services.TypedJob(func(ctx context.Context, job *models.Job, payload testJobPayload) error {
+		calls.Add(1)
+		if payload.Fail {
+			return errors.New("temporary failure")
+		}
+		return nil
+	}))
+
+	request := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	reload := func(job *models.Job) {
+		assert.NoError(t, testutils.TestDB.First(job, job.ID).Error)
+	}
+	// バックオフを待たずに次の試行を実行できるようにする
+	makeDue := func(job *models.Job) {
+		assert.NoError(t, testutils.TestDB.Model(job).Update("run_at", time.Now().Add(-time.Second)).Error)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		job, err := services.EnqueueJob(testutils.TestDB, "test.echo", testJobPayload{}, services.JobOptions{})
+		assert.NoError(t, err)
+
+		processed, err := queue.Drain(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		reload(job)
+		assert.Equal(t, models.JobStatusSucceeded, job.Status)
+		assert.Equal(t, 1, job.Attempts)
+		assert.NotNil(t, job.FinishedAt)
+	})
+
+	t.Run("delayed job waits for run_at", func(t *testing.T) {
+		job, err := services.EnqueueJob(testutils.TestDB, "test.echo", testJobPayload{}, services.JobOptions{RunAt: time.Now().Add(time.Hour)})
+		assert.NoError(t, err)
+
+		processed, err := queue.Drain(context.Background())
+		assert.NoError(t, err)
+		assert.Zero(t, processed)
+
+		makeDue(job)
+		processed, err = queue.Drain(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+	})
+
+	t.Run("unique key", func(t *testing.T) {
+		first, err := services.EnqueueJob(testutils.TestDB, "test.echo", testJobPayload{}, services.JobOptions{UniqueKey: "once", RunAt: time.Now().Add(time.Hour)})
+		assert.NoError(t, err)
+		assert.NotNil(t, first)
+
+		second, err := services.EnqueueJob(testutils.TestDB, "test.echo", testJobPayload{}, services.JobOptions{UniqueKey: "once"})
+		assert.NoError(t, err)
+		assert.Nil(t, second)
+
+		// 完了した後は同じキーで登録できる
+		makeDue(first)
+		_, err = queue.Drain(context.Background())
+		assert.NoError(t, err)
+		third, err := services.EnqueueJob(testutils.TestDB, "test.echo", testJobPayload{}, services.JobOptions{UniqueKey: "once"})
+		assert.NoError(t, err)
+		assert.NotNil(t, third)
+		_, err = queue.Drain(context.Background())
+		assert.NoError(t, err)
+	})
+
+	var dead models.Job
+	t.Run("retries with backoff until dead", func(t *testing.T) {
+		job, err := services.EnqueueJob(testutils.TestDB, "test.echo", testJobPayload{Fail: true}, services.JobOptions{MaxAttempts: 3})
+		assert.NoError(t, err)
+
+		for attempt := 1; attempt <= 3; attempt++ {
+			processed, err := queue.Drain(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 1, processed)
+
+			reload(job)
+			assert.Equal(t, attempt, job.Attempts)
+			assert.Equal(t, "temporary failure", job.LastError)
+			if attempt < 3 {
+				assert.Equal(t, models.JobStatusPending, job.Status)
+				assert.True(t, job.RunAt.After(time.Now()))
+				makeDue(job)
+			}
+		}
+		assert.Equal(t, models.JobStatusDead, job.Status)
+		assert.NotNil(t, job.FinishedAt)
+		dead = *job
+	})
+
+	t.Run("unknown type and bad payload are dead at once", func(t *testing.T) {
+		unknown, err := services.EnqueueJob(testutils.TestDB, "test.unknown", struct{}{}, services.JobOptions{})
+		assert.NoError(t, err)
+		badPayload, err := services.EnqueueJob(testutils.TestDB, "test.echo", []int{1}, services.JobOptions{})
+		assert.NoError(t, err)
+
+		_, err = queue.Drain(context.Background())
+		assert.NoError(t, err)
+		for _, job := range []*models.Job{unknown, badPayload} {
+			reload(job)
+			assert.Equal(t, models.JobStatusDead, job.Status)
+			assert.Equal(t, 1, job.Attempts)
+		}
+	})
+
+	t.Run("list dead jobs", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, request(http.MethodGet, "/admin/jobs", userToken).Code)
+
+		w := request(http.MethodGet, "/admin/jobs", adminToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var list models.JobListResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		assert.Equal(t, int64(3), list.Total)
+
+		w = request(http.MethodGet, "/admin/jobs?type=test.unknown", adminToken)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		assert.Equal(t, int64(1), list.Total)
+
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodGet, "/admin/jobs?status=unknown", adminToken).Code)
+	})
+
+	t.Run("retry dead job", func(t *testing.T) {
+		path := fmt.Sprintf("/admin/jobs/%d/retry", dead.ID)
+		w := request(http.MethodPost, path, adminToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var job models.JobResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		assert.Equal(t, models.JobStatusPending, job.Status)
+		assert.Zero(t, job.Attempts)
+
+		assert.Equal(t, http.StatusConflict, request(http.MethodPost, path, adminToken).Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodPost, "/admin/jobs/999999/retry", adminToken).Code)
+
+		calls.Store(0)
+		processed, err := queue.Drain(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("concurrent workers run each job once", func(t *testing.T) {
+		assert.NoError(t, testutils.TestDB.Exec("DELETE FROM jobs").Error)
+		const jobs = 20
+		for i := 0; i < jobs; i++ {
+			_, err := services.EnqueueJob(testutils.TestDB, "test.echo", testJobPayload{}, services.JobOptions{})
+			assert.NoError(t, err)
+		}
+
+		calls.Store(0)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := queue.Drain(context.Background())
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(jobs), calls.Load())
+		var succeeded int64
+		assert.NoError(t, testutils.TestDB.Model(&models.Job{}).Where("status = ? AND attempts = 1", models.JobStatusSucceeded).Count(&succeeded).Error)
+		assert.Equal(t, int64(jobs), succeeded)
+	})
+
+	t.Run("rescue stale and prune", func(t *testing.T) {
+		stale := models.Job{Type: "test.echo", Payload: "{}", Status: models.JobStatusRunning, Attempts: 1, MaxAttempts: 5, RunAt: time.Now()}
+		assert.NoError(t, testutils.TestDB.Create(&stale).Error)
+		assert.NoError(t, testutils.TestDB.Model(&stale).Update("locked_at", time.Now().Add(-time.Hour)).Error)
+
+		rescued, err := queue.RescueStale(time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rescued)
+		reload(&stale)
+		assert.Equal(t, models.JobStatusPending, stale.Status)
+
+		pruned, err := queue.Prune(time.Now().Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(20), pruned)
+	})
+
+	t.Run("rescued job is not overwritten by the slow worker", func(t *testing.T) {
+		// 実行中に RescueStale で戻され、別のワーカーが取り出した状態にする
+		queue.Register("test.slow", func(ctx context.Context, job *models.Job) error {
+			return testutils.TestDB.Model(&models.Job{}).Where("id = ?", job.ID).
+				Updates(map[string]interface{}{"locked_by": "other-worker", "attempts": job.Attempts + 1}).Error
+		})
+		job, err := services.EnqueueJob(testutils.TestDB, "test.slow", struct{}{}, services.JobOptions{})
+		assert.NoError(t, err)
+
+		_, err = queue.Drain(context.Background())
+		assert.NoError(t, err)
+
+		reload(job)
+		assert.Equal(t, models.JobStatusRunning, job.Status)
+		assert.Equal(t, "other-worker", job.LockedBy)
+		assert.Nil(t, job.FinishedAt)
+	})
+
+	t.Run("retry conflicts with a pending job of the same unique key", func(t *testing.T) {
+		key := "test:unique-retry"
+		dead := models.Job{Type: "test.echo", Payload: "{}", Status: models.JobStatusDead, Attempts: 5, MaxAttempts: 5, RunAt: time.Now(), UniqueKey: &key}
+		assert.NoError(t, testutils.TestDB.Create(&dead).Error)
+		_, err := services.EnqueueJob(testutils.TestDB, "test.echo", testJobPayload{}, services.JobOptions{UniqueKey: key, RunAt: time.Now().Add(time.Hour)})
+		assert.NoError(t, err)
+
+		w := request(http.MethodPost, fmt.Sprintf("/admin/jobs/%d/retry", dead.ID), adminToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		reload(&dead)
+		assert.Equal(t, models.JobStatusDead, dead.Status)
+	})
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type LikeHandler struct {
+	likeService *services.LikeService
+}
+
+func NewLikeHandler(likeService *services.LikeService) *LikeHandler {
+	return &LikeHandler{likeService: likeService}
+}
+
+// LikeMicropost godoc
+// @Summary      Like micropost
+// @Description  Like a micropost (idempotent)
+// @Tags         likes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Micropost ID"
+// @Success      200  {object}  models.LikeStatusResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /microposts/{id}/like [post]
+func (h *LikeHandler) LikeMicropost(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	micropostID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	status, err := h.likeService.Like(userID, micropostID)
+	if err != nil {
+		respondLikeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// UnlikeMicropost godoc
+// @Summary      Unlike micropost
+// @Description  Remove a like from a micropost (idempotent)
+// @Tags         likes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Micropost ID"
+// @Success      200  {object}  models.LikeStatusResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /microposts/{id}/like [delete]
+func (h *LikeHandler) UnlikeMicropost(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	micropostID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	status, err := h.likeService.Unlike(userID, micropostID)
+	if err != nil {
+		respondLikeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// GetLikers godoc
+// @Summary      List likers
+// @Description  List users who liked a micropost, newest first
+// @Tags         likes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int  true   "Micropost ID"
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.UserListResponse
+// @Failure      404  {object}  map[string]string
+// @Router       /microposts/{id}/likes [get]
+func (h *LikeHandler) GetLikers(c *gin.Context) {
+	micropostID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	users, total, err := h.likeService.Likers(micropostID, page)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchLikes)
+		return
+	}
+
+	response := models.UserListResponse{Users: make([]models.UserResponse, 0, len(users)), PageMeta: page.Meta(total)}
+	for _, user := range users {
+		response.Users = append(response.Users, user.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func respondLikeError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+	utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToUpdateLike)
+}
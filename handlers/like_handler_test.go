@@ -0,0 +1,97 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLikeMicropost(t *testing.T) {
+	r, likeHandler := testutils.SetupLikeHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.POST("/microposts/:id/like", likeHandler.LikeMicropost)
+	auth.DELETE("/microposts/:id/like", likeHandler.UnlikeMicropost)
+	auth.GET("/microposts/:id/likes", likeHandler.GetLikers)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	micropost := models.Micropost{Title: "Likeable", UserID: user.ID}
+	assert.NoError(t, testutils.TestDB.Create(&micropost).Error)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedCount  int64
+		expectedLiked  bool
+	}{
+		{name: "Like", method: http.MethodPost, path: fmt.Sprintf("/microposts/%d/like", micropost.ID), expectedStatus: http.StatusOK, expectedCount: 1, expectedLiked: true},
+		{name: "Like Twice Is Idempotent", method: http.MethodPost, path: fmt.Sprintf("/microposts/%d/like", micropost.ID), expectedStatus: http.StatusOK, expectedCount: 1, expectedLiked: true},
+		{name: "Unlike", method: http.MethodDelete, path: fmt.Sprintf("/microposts/%d/like", micropost.ID), expectedStatus: http.StatusOK, expectedCount: 0, expectedLiked: false},
+		{name: "Unknown Micropost", method: http.MethodPost, path: "/microposts/999999/like", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.LikeStatusResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedCount, response.LikeCount)
+				assert.Equal(t, tt.expectedLiked, response.LikedByMe)
+			}
+		})
+	}
+
+	// いいねしたユーザー一覧
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/microposts/%d/like", micropost.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/microposts/%d/likes", micropost.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var likers models.UserListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &likers))
+	assert.Equal(t, int64(1), likers.Total)
+	assert.Equal(t, user.Email, likers.Users[0].Email)
+
+	// ゴミ箱の中のユーザーのいいねは数えない
+	leaver := models.User{Email: "leaver@example.com", Handle: "leaver", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&leaver).Error)
+	_, err = services.NewLikeService(testutils.TestDB).Like(leaver.ID, micropost.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, testutils.TestDB.Delete(&leaver).Error)
+
+	status, err := services.NewLikeService(testutils.TestDB).Status(user.ID, micropost.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), status.LikeCount)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &likers))
+	assert.Equal(t, int64(1), likers.Total)
+	assert.Len(t, likers.Users, 1)
+}
@@ -0,0 +1,132 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentFilter(t *testing.T) {
+	r, handler, _ := testutils.SetupMicropostHandler()
+	services.SetContentFilter(services.NewContentFilter(
+		services.WordFilterRule{Words: models.NewWordFilter(models.ContentActionReject, "スパム")},
+		services.WordFilterRule{Words: models.NewWordFilter(models.ContentActionFlag, "カジノ")},
+		services.LinkLimitRule{Max: 2, Action: models.ContentActionReject},
+		services.DuplicateRule{Window: time.Hour, Limit: 2, Action: models.ContentActionShadowHide},
+	))
+	defer services.SetContentFilter(nil)
+
+	// ルートの設定
+	r.POST("/microposts", middlewares.AuthMiddleware(), handler.CreateMicropost)
+	r.PUT("/microposts/:id", middlewares.AuthMiddleware(), handler.UpdateMicropost)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	viewer := models.User{Email: "viewer@example.com", Handle: "viewer", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&viewer).Error)
+
+	request := func(method, path string, body models.MicropostRequest) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name           string
+		request        models.MicropostRequest
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "Clean", request: models.MicropostRequest{Title: "こんにちは"}, expectedStatus: http.StatusCreated},
+		{name: "Banned Word In Half Width Kana", request: models.MicropostRequest{Title: "ｽﾊﾟﾑ配信"}, expectedStatus: http.StatusUnprocessableEntity, expectedCode: i18n.ErrContentRejected},
+		{name: "Banned Word In Hiragana", request: models.MicropostRequest{Title: "Hi", Body: "すぱむです"}, expectedStatus: http.StatusUnprocessableEntity, expectedCode: i18n.ErrContentRejected},
+		{name: "Too Many Links", request: models.MicropostRequest{Title: "Links", Body: "https://a.example https://b.example https://c.example"}, expectedStatus: http.StatusUnprocessableEntity, expectedCode: i18n.ErrTooManyLinks},
+		{name: "Flagged Word", request: models.MicropostRequest{Title: "カジノの話"}, expectedStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(http.MethodPost, "/microposts", tt.request)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedCode, response["code"])
+			}
+		})
+	}
+
+	micropostService := services.NewMicropostService(testutils.TestDB)
+	moderationService := services.NewModerationService(testutils.TestDB)
+
+	t.Run("flagged posts are published and queued for review", func(t *testing.T) {
+		microposts, err := micropostService.GetAll(viewer.ID)
+		assert.NoError(t, err)
+		assert.Len(t, microposts, 2)
+
+		reports, total, err := moderationService.Queue(models.ReportQuery{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Nil(t, reports[0].ReporterID)
+		assert.Equal(t, models.ContentRuleBannedWord, reports[0].Reason)
+	})
+
+	var shadowed models.MicropostResponse
+	t.Run("duplicates are shadow hidden", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			w := request(http.MethodPost, "/microposts", models.MicropostRequest{Title: "Follow me", Body: "今すぐフォロー"})
+			assert.Equal(t, http.StatusCreated, w.Code)
+		}
+		// 文字種と空白の違いは同じ内容とみなす
+		w := request(http.MethodPost, "/microposts", models.MicropostRequest{Title: "ＦＯＬＬＯＷ  me", Body: "今すぐふぉろー"})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &shadowed))
+
+		// 投稿者本人には見え、他のユーザーには見えない
+		mine, err := micropostService.GetAll(user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, mine, 5)
+		others, err := micropostService.GetAll(viewer.ID)
+		assert.NoError(t, err)
+		assert.Len(t, others, 4)
+		_, err = micropostService.GetByID(shadowed.ID, viewer.ID)
+		assert.Error(t, err)
+		_, err = micropostService.GetByID(shadowed.ID, user.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("edits are screened", func(t *testing.T) {
+		w := request(http.MethodPut, fmt.Sprintf("/microposts/%d", shadowed.ID), models.MicropostRequest{Title: "スパム"})
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("dismissing an automatic report restores the post", func(t *testing.T) {
+		admin := models.User{Email: "admin@example.com", Handle: "admin", Password: "password123", Role: models.RoleAdmin}
+		assert.NoError(t, testutils.TestDB.Create(&admin).Error)
+
+		var report models.Report
+		assert.NoError(t, testutils.TestDB.Where("micropost_id = ?", shadowed.ID).First(&report).Error)
+		assert.Equal(t, models.ContentRuleDuplicate, report.Reason)
+		_, err := moderationService.Resolve(admin.ID, report.ID, models.ModerationRequest{Action: models.ModerationActionDismiss})
+		assert.NoError(t, err)
+
+		_, err = micropostService.GetByID(shadowed.ID, viewer.ID)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,224 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrafts(t *testing.T) {
+	r, handler, _ := testutils.SetupMicropostHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.GET("/microposts", handler.GetMicroposts)
+	auth.POST("/microposts/drafts", handler.CreateDraft)
+	auth.GET("/microposts/drafts", handler.GetDrafts)
+	auth.GET("/microposts/drafts/:id", handler.GetDraft)
+	auth.PUT("/microposts/drafts/:id", handler.UpdateDraft)
+	auth.DELETE("/microposts/drafts/:id", handler.DeleteDraft)
+	auth.POST("/microposts/drafts/:id/publish", handler.PublishDraft)
+	auth.GET("/microposts/:id", handler.GetMicropost)
+	auth.PUT("/microposts/:id", handler.UpdateMicropost)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	other := models.User{Email: "other@example.com", Handle: "other", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&other).Error)
+	otherToken, err := utils.GenerateJWTToken(other)
+	assert.NoError(t, err)
+
+	request := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	timeline := func(token string) []models.MicropostResponse {
+		var response []models.MicropostResponse
+		assert.NoError(t, json.Unmarshal(request(http.MethodGet, "/microposts", token, nil).Body.Bytes(), &response))
+		return response
+	}
+
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	var draft, scheduled models.MicropostResponse
+
+	t.Run("create", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			body           interface{}
+			expectedStatus int
+			expectedState  string
+		}{
+			{name: "Draft", body: map[string]interface{}{"title": "Draft #go"}, expectedStatus: http.StatusCreated, expectedState: models.MicropostStatusDraft},
+			{name: "Scheduled", body: map[string]interface{}{"title": "Scheduled", "publish_at": future}, expectedStatus: http.StatusCreated, expectedState: models.MicropostStatusScheduled},
+			{name: "Past", body: map[string]interface{}{"title": "Late", "publish_at": past}, expectedStatus: http.StatusBadRequest},
+			{name: "Missing Title", body: map[string]interface{}{"body": "no title"}, expectedStatus: http.StatusBadRequest},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := request(http.MethodPost, "/microposts/drafts", token, tt.body)
+				assert.Equal(t, tt.expectedStatus, w.Code)
+				if tt.expectedStatus != http.StatusCreated {
+					return
+				}
+				var response models.MicropostResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedState, response.Status)
+				// ハッシュタグは公開するときに抽出する
+				assert.Empty(t, response.Hashtags)
+				if tt.expectedState == models.MicropostStatusDraft {
+					draft = response
+				} else {
+					scheduled = response
+					assert.NotNil(t, response.PublishAt)
+				}
+			})
+		}
+	})
+
+	t.Run("hidden until published", func(t *testing.T) {
+		assert.Empty(t, timeline(token))
+		for _, id := range []uint{draft.ID, scheduled.ID} {
+			assert.Equal(t, http.StatusNotFound, request(http.MethodGet, fmt.Sprintf("/microposts/%d", id), token, nil).Code)
+			assert.Equal(t, http.StatusNotFound, request(http.MethodPut, fmt.Sprintf("/microposts/%d", id), token, map[string]string{"title": "Edited"}).Code)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		w := request(http.MethodGet, "/microposts/drafts", token, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.MicropostListResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(2), response.Total)
+		// 予約投稿が先
+		assert.Equal(t, scheduled.ID, response.Microposts[0].ID)
+		assert.Equal(t, draft.ID, response.Microposts[1].ID)
+
+		w = request(http.MethodGet, "/microposts/drafts", otherToken, nil)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Zero(t, response.Total)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, fmt.Sprintf("/microposts/drafts/%d", draft.ID), otherToken, nil).Code)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			token          string
+			body           interface{}
+			expectedStatus int
+			expectedState  string
+		}{
+			{name: "Other User", token: otherToken, body: map[string]interface{}{"title": "Mine"}, expectedStatus: http.StatusNotFound},
+			{name: "Past", token: token, body: map[string]interface{}{"title": "Draft", "publish_at": past}, expectedStatus: http.StatusBadRequest},
+			{name: "Schedule", token: token, body: map[string]interface{}{"title": "Draft #go", "body": "hello", "publish_at": future}, expectedStatus: http.StatusOK, expectedState: models.MicropostStatusScheduled},
+			{name: "Unschedule", token: token, body: map[string]interface{}{"title": "Draft #go", "body": "hello"}, expectedStatus: http.StatusOK, expectedState: models.MicropostStatusDraft},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := request(http.MethodPut, fmt.Sprintf("/microposts/drafts/%d", draft.ID), tt.token, tt.body)
+				assert.Equal(t, tt.expectedStatus, w.Code)
+				if tt.expectedStatus != http.StatusOK {
+					return
+				}
+				var response models.MicropostResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedState, response.Status)
+				assert.Equal(t, "hello", response.Body)
+			})
+		}
+	})
+
+	t.Run("publish", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, request(http.MethodPost, fmt.Sprintf("/microposts/drafts/%d/publish", draft.ID), otherToken, nil).Code)
+
+		w := request(http.MethodPost, fmt.Sprintf("/microposts/drafts/%d/publish", draft.ID), token, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.MicropostResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, models.MicropostStatusPublished, response.Status)
+		assert.Equal(t, []string{"go"}, response.Hashtags)
+		assert.True(t, response.CreatedAt.After(draft.CreatedAt))
+
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, fmt.Sprintf("/microposts/%d", draft.ID), otherToken, nil).Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodPost, fmt.Sprintf("/microposts/drafts/%d/publish", draft.ID), token, nil).Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, fmt.Sprintf("/microposts/drafts/%d", draft.ID), token, nil).Code)
+	})
+
+	t.Run("scheduler", func(t *testing.T) {
+		microposts := services.NewMicropostService(testutils.TestDB)
+		count, err := microposts.PublishScheduled(time.Now())
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+
+		count, err = microposts.PublishScheduled(future.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		posts := timeline(otherToken)
+		assert.Len(t, posts, 2)
+		assert.Equal(t, scheduled.ID, posts[0].ID)
+		assert.Nil(t, posts[0].PublishAt)
+
+		// 2回目は公開済みなので何もしない
+		count, err = microposts.PublishScheduled(future.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+
+		var events int64
+		testutils.TestDB.Model(&models.OutboxEvent{}).Where("type = ?", models.EventMicropostCreated).Count(&events)
+		assert.Equal(t, int64(2), events)
+	})
+
+	t.Run("scheduler skips suspended users", func(t *testing.T) {
+		w := request(http.MethodPost, "/microposts/drafts", token, map[string]interface{}{"title": "Later", "publish_at": future})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response models.MicropostResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		assert.NoError(t, testutils.TestDB.Model(user).Update("suspended_at", time.Now()).Error)
+		count, err := services.NewMicropostService(testutils.TestDB).PublishScheduled(future.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, fmt.Sprintf("/microposts/drafts/%d", response.ID), token, nil).Code)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := request(http.MethodPost, "/microposts/drafts", otherToken, map[string]interface{}{"title": "Discard me"})
+		var response models.MicropostResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		path := fmt.Sprintf("/microposts/drafts/%d", response.ID)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, path, token, nil).Code)
+		assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, path, otherToken, nil).Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, path, otherToken, nil).Code)
+
+		// ゴミ箱には移さない
+		var count int64
+		testutils.TestDB.Unscoped().Model(&models.Micropost{}).Where("id = ?", response.ID).Count(&count)
+		assert.Zero(t, count)
+	})
+}
@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
+	"go-gin-gorm-minimum/i18n"
 	"go-gin-gorm-minimum/models"
 	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type MicropostHandler struct {
@@ -19,34 +24,43 @@ func NewMicropostHandler(micropostService *services.MicropostService) *Micropost
 
 // CreateMicropost godoc
 // @Summary      Create new micropost
-// @Description  Create a new micropost with the given title
+// @Description  Create a new micropost with the given title and body (body length is counted in Unicode characters). Posts are screened by the content filter: rejected posts return 422, and flagged posts are published but queued for moderator review.
 // @Tags         microposts
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        micropost body models.MicropostRequest true "Micropost object"
 // @Success      201  {object}  models.MicropostResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      422  {object}  map[string]string
 // @Router       /microposts [post]
 func (h *MicropostHandler) CreateMicropost(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	if userID == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		utils.ErrorJSON(c, http.StatusUnauthorized, i18n.ErrUnauthorized)
 		return
 	}
 
-	var req models.MicropostRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	req, ok := bindMicropostRequest(c)
+	if !ok {
 		return
 	}
 
 	micropost := models.Micropost{
 		Title:  req.Title,
+		Body:   req.Body,
 		UserID: userID.(uint),
 	}
 
 	if err := h.micropostService.Create(&micropost); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create micropost"})
+		if respondAccountUnavailable(c, err) {
+			return
+		}
+		if respondContentRejected(c, err) {
+			return
+		}
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToCreateMicropost)
 		return
 	}
 
@@ -60,15 +74,21 @@ func (h *MicropostHandler) CreateMicropost(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {array}   models.Micropost
+// @Success      200  {array}   models.MicropostResponse
 // @Router       /microposts [get]
 func (h *MicropostHandler) GetMicroposts(c *gin.Context) {
-	microposts, err := h.micropostService.GetAll()
+	viewerID, _ := currentUserID(c)
+	microposts, err := h.micropostService.GetAll(viewerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch microposts"})
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchMicroposts)
 		return
 	}
-	c.JSON(http.StatusOK, microposts)
+
+	response := make([]models.MicropostResponse, 0, len(microposts))
+	for _, micropost := range microposts {
+		response = append(response, micropost.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // GetMicropost godoc
@@ -79,15 +99,801 @@ func (h *MicropostHandler) GetMicroposts(c *gin.Context) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "Micropost ID"
-// @Success      200  {object}  models.Micropost
+// @Success      200  {object}  models.MicropostResponse
 // @Failure      404  {object}  map[string]string
 // @Router       /microposts/{id} [get]
 func (h *MicropostHandler) GetMicropost(c *gin.Context) {
-	micropost, err := h.micropostService.GetByID(c.Param("id"))
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	viewerID, _ := currentUserID(c)
+	micropost, err := h.micropostService.GetByID(id, viewerID)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, micropost.ToResponse())
+}
+
+// UpdateMicropost godoc
+// @Summary      Update micropost
+// @Description  Update the title and body of the current user's micropost. Hashtags are re-extracted from the new text. Plain reposts cannot be edited. When the content changes, the previous content is kept as a revision and edited_at is set.
+// @Tags         microposts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path  int                     true  "Micropost ID"
+// @Param        micropost  body  models.MicropostRequest true  "Micropost object"
+// @Success      200  {object}  models.MicropostResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      422  {object}  map[string]string
+// @Router       /microposts/{id} [put]
+func (h *MicropostHandler) UpdateMicropost(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := bindMicropostRequest(c)
+	if !ok {
+		return
+	}
+
+	micropost, err := h.micropostService.Update(id, userID, req)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	case errors.Is(err, services.ErrForbidden):
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrForbidden)
+		return
+	case respondContentRejected(c, err):
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToUpdateMicropost)
+		return
+	}
+
+	c.JSON(http.StatusOK, micropost.ToResponse())
+}
+
+// DeleteMicropost godoc
+// @Summary      Delete micropost
+// @Description  Move a micropost to the trash (author or admin only). Trashed posts can be restored until they are purged after the retention period. Plain reposts are removed permanently, as with undoing a repost.
+// @Tags         microposts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Micropost ID"
+// @Success      204
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /microposts/{id} [delete]
+func (h *MicropostHandler) DeleteMicropost(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := h.micropostService.Delete(id, userID, isAdmin(c))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	case errors.Is(err, services.ErrForbidden):
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrForbidden)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToDeleteMicropost)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetTrash godoc
+// @Summary      List trashed microposts
+// @Description  List the current user's deleted microposts, most recently deleted first. purge_at is when each post will be removed permanently.
+// @Tags         microposts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.MicropostListResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Router       /microposts/trash [get]
+func (h *MicropostHandler) GetTrash(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	microposts, total, err := h.micropostService.Trash(userID, page)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchMicroposts)
+		return
+	}
+
+	response := models.MicropostListResponse{Microposts: make([]models.MicropostResponse, 0, len(microposts)), PageMeta: page.Meta(total)}
+	for _, micropost := range microposts {
+		response.Microposts = append(response.Microposts, micropost.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RestoreMicropost godoc
+// @Summary      Restore micropost
+// @Description  Restore a trashed micropost (author or admin only). Posts past the retention period cannot be restored. Posts deleted together with their author are restored by restoring the user.
+// @Tags         microposts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Micropost ID"
+// @Success      200  {object}  models.MicropostResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      410  {object}  map[string]string
+// @Router       /microposts/{id}/restore [post]
+func (h *MicropostHandler) RestoreMicropost(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	micropost, err := h.micropostService.Restore(id, userID, isAdmin(c))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	case errors.Is(err, services.ErrForbidden):
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrForbidden)
+		return
+	case errors.Is(err, services.ErrRestoreExpired):
+		utils.ErrorJSON(c, http.StatusGone, i18n.ErrRestoreExpired)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToRestore)
+		return
+	}
+
+	c.JSON(http.StatusOK, micropost.ToResponse())
+}
+
+// CreateDraft godoc
+// @Summary      Create draft
+// @Description  Save a micropost as a draft, or schedule it when publish_at (a future time) is given. Drafts and scheduled posts are only visible to their author through the drafts endpoints; hashtags, mentions and the content filter are applied when the post is published.
+// @Tags         drafts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        draft  body  models.DraftRequest  true  "Draft object"
+// @Success      201  {object}  models.MicropostResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /microposts/drafts [post]
+func (h *MicropostHandler) CreateDraft(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindDraftRequest(c)
+	if !ok {
+		return
+	}
+
+	draft, err := h.micropostService.CreateDraft(userID, req)
+	if err != nil {
+		if respondAccountUnavailable(c, err) {
+			return
+		}
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToCreateMicropost)
+		return
+	}
+
+	c.JSON(http.StatusCreated, draft.ToResponse())
+}
+
+// GetDrafts godoc
+// @Summary      List drafts
+// @Description  List the current user's drafts and scheduled posts: scheduled posts first by publish_at, then drafts by most recently updated.
+// @Tags         drafts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.MicropostListResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Router       /microposts/drafts [get]
+func (h *MicropostHandler) GetDrafts(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	drafts, total, err := h.micropostService.Drafts(userID, page)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchMicroposts)
+		return
+	}
+
+	response := models.MicropostListResponse{Microposts: make([]models.MicropostResponse, 0, len(drafts)), PageMeta: page.Meta(total)}
+	for _, draft := range drafts {
+		response.Microposts = append(response.Microposts, draft.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDraft godoc
+// @Summary      Get draft
+// @Description  Get one of the current user's drafts or scheduled posts. Other users' drafts and published posts return 404.
+// @Tags         drafts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Micropost ID"
+// @Success      200  {object}  models.MicropostResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /microposts/drafts/{id} [get]
+func (h *MicropostHandler) GetDraft(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	draft, err := h.micropostService.Draft(id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchMicroposts)
+		return
+	}
+
+	c.JSON(http.StatusOK, draft.ToResponse())
+}
+
+// UpdateDraft godoc
+// @Summary      Update draft
+// @Description  Replace the title, body and publish_at of a draft or scheduled post. Setting publish_at schedules the post; omitting it turns a scheduled post back into a draft. Posts that have already been published return 404.
+// @Tags         drafts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path  int                  true  "Micropost ID"
+// @Param        draft  body  models.DraftRequest  true  "Draft object"
+// @Success      200  {object}  models.MicropostResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /microposts/drafts/{id} [put]
+func (h *MicropostHandler) UpdateDraft(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := bindDraftRequest(c)
+	if !ok {
+		return
+	}
+
+	draft, err := h.micropostService.UpdateDraft(id, userID, req)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToUpdateMicropost)
+		return
+	}
+
+	c.JSON(http.StatusOK, draft.ToResponse())
+}
+
+// DeleteDraft godoc
+// @Summary      Delete draft
+// @Description  Permanently delete a draft or scheduled post. Unpublished posts are not moved to the trash.
+// @Tags         drafts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Micropost ID"
+// @Success      204
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /microposts/drafts/{id} [delete]
+func (h *MicropostHandler) DeleteDraft(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := h.micropostService.DeleteDraft(id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Record not found!"})
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToDeleteMicropost)
 		return
 	}
+	c.Status(http.StatusNoContent)
+}
+
+// PublishDraft godoc
+// @Summary      Publish draft
+// @Description  Publish a draft or scheduled post immediately. The post is screened by the content filter like a new post, and its created_at becomes the time of publication.
+// @Tags         drafts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Micropost ID"
+// @Success      200  {object}  models.MicropostResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      422  {object}  map[string]string
+// @Router       /microposts/drafts/{id}/publish [post]
+func (h *MicropostHandler) PublishDraft(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	micropost, err := h.micropostService.PublishDraft(id, userID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	case respondAccountUnavailable(c, err):
+		return
+	case respondContentRejected(c, err):
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToPublishMicropost)
+		return
+	}
+
+	c.JSON(http.StatusOK, micropost.ToResponse())
+}
+
+// CreateReply godoc
+// @Summary      Reply to micropost
+// @Description  Create a reply to the micropost with the given ID
+// @Tags         microposts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path  int                     true  "Parent micropost ID"
+// @Param        micropost  body  models.MicropostRequest true  "Reply object"
+// @Success      201  {object}  models.MicropostResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      422  {object}  map[string]string
+// @Router       /microposts/{id}/replies [post]
+func (h *MicropostHandler) CreateReply(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	parentID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := bindMicropostRequest(c)
+	if !ok {
+		return
+	}
+
+	reply := models.Micropost{
+		Title:  req.Title,
+		Body:   req.Body,
+		UserID: userID,
+	}
+	if err := h.micropostService.CreateReply(parentID, &reply); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+			return
+		}
+		if errors.Is(err, services.ErrBlocked) {
+			utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrBlocked)
+			return
+		}
+		if respondAccountUnavailable(c, err) {
+			return
+		}
+		if respondContentRejected(c, err) {
+			return
+		}
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToCreateMicropost)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reply.ToResponse())
+}
+
+// GetThread godoc
+// @Summary      Get micropost thread
+// @Description  Get a micropost with its ancestors (root first) and a page of its descendants in depth-first order
+// @Tags         microposts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int  true   "Micropost ID"
+// @Param        page      query     int  false  "Page number of descendants"
+// @Param        per_page  query     int  false  "Descendants per page (max 100)"
+// @Success      200  {object}  models.ThreadResponse
+// @Failure      404  {object}  map[string]string
+// @Router       /microposts/{id}/thread [get]
+func (h *MicropostHandler) GetThread(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	viewerID, _ := currentUserID(c)
+	thread, err := h.micropostService.Thread(id, viewerID, page)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchMicroposts)
+		return
+	}
+
+	response := models.ThreadResponse{
+		Ancestors:   make([]models.MicropostResponse, 0, len(thread.Ancestors)),
+		Micropost:   thread.Micropost.ToResponse(),
+		Descendants: make([]models.ThreadReply, 0, len(thread.Descendants)),
+		PageMeta:    page.Meta(thread.Total),
+	}
+	for _, ancestor := range thread.Ancestors {
+		response.Ancestors = append(response.Ancestors, ancestor.ToResponse())
+	}
+	for _, node := range thread.Descendants {
+		response.Descendants = append(response.Descendants, models.ThreadReply{
+			MicropostResponse: node.Micropost.ToResponse(),
+			Depth:             node.Depth,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRevisions godoc
+// @Summary      List micropost revisions
+// @Description  List the revisions of a micropost, newest first. Version 1 is the content at publication and each edit adds a version; title_diff and body_diff are character-level diffs from the previous version. Admins can also view the history of hidden and trashed posts.
+// @Tags         microposts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int  true   "Micropost ID"
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.MicropostRevisionListResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /microposts/{id}/revisions [get]
+func (h *MicropostHandler) GetRevisions(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	revisions, before, total, err := h.micropostService.Revisions(id, userID, isAdmin(c), page)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchRevisions)
+		return
+	}
+
+	response := models.MicropostRevisionListResponse{Revisions: make([]models.MicropostRevisionResponse, 0, len(revisions)), PageMeta: page.Meta(total)}
+	for i := range revisions {
+		previous := before
+		if i+1 < len(revisions) {
+			previous = &revisions[i+1]
+		}
+		response.Revisions = append(response.Revisions, revisions[i].ToResponse(previous))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Repost godoc
+// @Summary      Repost or quote micropost
+// @Description  Repost a micropost. With an empty body (or no title/body) this is a plain repost, allowed once per user; with a title or body it becomes a quote post. Reposting a plain repost targets its original.
+// @Tags         microposts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path  int                   true   "Original micropost ID"
+// @Param        repost  body  models.RepostRequest  false  "Optional commentary"
+// @Success      201  {object}  models.MicropostResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      422  {object}  map[string]string
+// @Router       /microposts/{id}/repost [post]
+func (h *MicropostHandler) Repost(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	originalID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.RepostRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		respondMicropostValidationError(c, err)
+		return
+	}
+
+	var micropost *models.Micropost
+	var err error
+	if req.IsQuote() {
+		micropost, err = h.micropostService.Quote(&models.Micropost{Title: req.Title, Body: req.Body, UserID: userID}, originalID)
+	} else {
+		micropost, err = h.micropostService.Repost(userID, originalID)
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	case errors.Is(err, services.ErrAlreadyReposted):
+		utils.ErrorJSON(c, http.StatusConflict, i18n.ErrAlreadyReposted)
+		return
+	case respondAccountUnavailable(c, err):
+		return
+	case respondContentRejected(c, err):
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToCreateMicropost)
+		return
+	}
+
+	c.JSON(http.StatusCreated, micropost.ToResponse())
+}
+
+// Unrepost godoc
+// @Summary      Undo repost
+// @Description  Remove the current user's plain repost of a micropost (idempotent). Quote posts are not affected.
+// @Tags         microposts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Original micropost ID"
+// @Success      204
+// @Failure      404  {object}  map[string]string
+// @Router       /microposts/{id}/repost [delete]
+func (h *MicropostHandler) Unrepost(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	originalID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := h.micropostService.Unrepost(userID, originalID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToDeleteMicropost)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// bindMicropostRequest はリクエストを読み込んで正規化・検証し、失敗時は 400 を返す
+func bindMicropostRequest(c *gin.Context) (models.MicropostRequest, bool) {
+	var req models.MicropostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return req, false
+	}
+
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		respondMicropostValidationError(c, err)
+		return req, false
+	}
+	return req, true
+}
+
+// bindDraftRequest は下書きのリクエストを読み込んで正規化・検証し、失敗時は 400 を返す
+func bindDraftRequest(c *gin.Context) (models.DraftRequest, bool) {
+	var req models.DraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return req, false
+	}
+
+	req.Normalize()
+	if err := req.Validate(time.Now()); err != nil {
+		respondMicropostValidationError(c, err)
+		return req, false
+	}
+	return req, true
+}
+
+// respondAccountUnavailable は利用停止中・削除済みのユーザーの操作なら 403 を返す
+func respondAccountUnavailable(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrAccountSuspended):
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrAccountSuspended)
+	case errors.Is(err, services.ErrAccountDeleted):
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrAccountDeleted)
+	default:
+		return false
+	}
+	return true
+}
+
+// respondContentRejected は自動モデレーションで拒否された投稿なら 422 を返す（どの語に該当したかは返さない）
+func respondContentRejected(c *gin.Context, err error) bool {
+	var rejected *services.ContentRejectedError
+	if !errors.As(err, &rejected) {
+		return false
+	}
+	switch rejected.Verdict.Rule {
+	case models.ContentRuleLinkLimit:
+		utils.ErrorJSON(c, http.StatusUnprocessableEntity, i18n.ErrTooManyLinks)
+	case models.ContentRuleDuplicate:
+		utils.ErrorJSON(c, http.StatusUnprocessableEntity, i18n.ErrDuplicateContent)
+	default:
+		utils.ErrorJSON(c, http.StatusUnprocessableEntity, i18n.ErrContentRejected)
+	}
+	return true
+}
+
+func respondMicropostValidationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrMicropostTitleRequired):
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrMicropostTitleRequired)
+	case errors.Is(err, models.ErrMicropostBodyTooLong):
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrMicropostBodyTooLong, models.MicropostBodyMaxLength)
+	case errors.Is(err, models.ErrInvalidPublishAt):
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrInvalidPublishAt)
+	default:
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrInvalidRequest)
+	}
+}
+
+// SearchMicroposts godoc
+// @Summary      Search microposts
+// @Description  Full-text search over titles and bodies ranked by relevance. Queries containing Japanese/CJK text use trigram substring matching instead of tsvector tokens. Snippets are HTML-escaped with matches wrapped in <mark>.
+// @Tags         microposts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q         query     string  true   "Search query"
+// @Param        user_id   query     int     false  "Filter by author"
+// @Param        from      query     string  false  "Created at or after (RFC3339)"
+// @Param        to        query     string  false  "Created at or before (RFC3339)"
+// @Param        page      query     int     false  "Page number"
+// @Param        per_page  query     int     false  "Results per page (max 100)"
+// @Success      200  {object}  models.MicropostSearchResponse
+// @Failure      400  {object}  map[string]string
+// @Router       /microposts/search [get]
+func (h *MicropostHandler) SearchMicroposts(c *gin.Context) {
+	var query models.MicropostSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	query.Q = models.NormalizeText(query.Q, false)
+	if query.Q == "" {
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrSearchQueryRequired)
+		return
+	}
+
+	viewerID, _ := currentUserID(c)
+	hits, total, strategy, err := h.micropostService.Search(viewerID, query)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchMicroposts)
+		return
+	}
+
+	results := make([]models.MicropostSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, models.MicropostSearchResult{
+			MicropostResponse: hit.Micropost.ToResponse(),
+			Rank:              hit.Rank,
+			Snippet:           hit.Snippet,
+		})
+	}
 
-	c.JSON(http.StatusOK, micropost)
+	c.JSON(http.StatusOK, models.MicropostSearchResponse{
+		Results:  results,
+		Strategy: strategy,
+		PageMeta: query.Meta(total),
+	})
 }
@@ -10,7 +10,9 @@ import (
 	"go-gin-gorm-minimum/testutils"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"go-gin-gorm-minimum/middlewares"
 
@@ -53,6 +55,37 @@ func TestCreateMicropost(t *testing.T) {
 				assert.NotEmpty(t, response.CreatedAt)
 			},
 		},
+		{
+			name:      "Japanese Body Counted In Characters",
+			setupAuth: true,
+			request: models.MicropostRequest{
+				Title: "  日本語の投稿\u0007 ",
+				Body:  strings.Repeat("あ", models.MicropostBodyMaxLength),
+			},
+			expectedStatus: http.StatusCreated,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response models.MicropostResponse
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "日本語の投稿", response.Title)
+				assert.Equal(t, models.MicropostBodyMaxLength, utf8.RuneCountInString(response.Body))
+			},
+		},
+		{
+			name:      "Body Too Long",
+			setupAuth: true,
+			request: models.MicropostRequest{
+				Title: "Test Micropost",
+				Body:  strings.Repeat("あ", models.MicropostBodyMaxLength+1),
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]string
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "micropost_body_too_long", response["code"])
+			},
+		},
 		{
 			name:      "Unauthorized Access",
 			setupAuth: false,
@@ -114,3 +147,55 @@ func TestCreateMicropost(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateMicropost(t *testing.T) {
+	r, micropostHandler, _ := testutils.SetupMicropostHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.PUT("/microposts/:id", micropostHandler.UpdateMicropost)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	other := models.User{Email: "other@example.com", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&other).Error)
+
+	micropostService := services.NewMicropostService(testutils.TestDB)
+	micropost := models.Micropost{Title: "Original", Body: "#before", UserID: user.ID}
+	assert.NoError(t, micropostService.Create(&micropost))
+	othersPost := models.Micropost{Title: "Others", UserID: other.ID}
+	assert.NoError(t, micropostService.Create(&othersPost))
+
+	tests := []struct {
+		name             string
+		id               uint
+		body             map[string]string
+		expectedStatus   int
+		expectedHashtags []string
+	}{
+		{name: "Update Retags", id: micropost.ID, body: map[string]string{"title": "Edited", "body": "#after #日本語"}, expectedStatus: http.StatusOK, expectedHashtags: []string{"after", "日本語"}},
+		{name: "Missing Title", id: micropost.ID, body: map[string]string{"body": "本文"}, expectedStatus: http.StatusBadRequest},
+		{name: "Not Owner", id: othersPost.ID, body: map[string]string{"title": "Hijack"}, expectedStatus: http.StatusForbidden},
+		{name: "Not Found", id: 999999, body: map[string]string{"title": "Missing"}, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/microposts/%d", tt.id), bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.MicropostResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.ElementsMatch(t, tt.expectedHashtags, response.Hashtags)
+			}
+		})
+	}
+}
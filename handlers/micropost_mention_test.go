@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMicropostMentions(t *testing.T) {
+	r, micropostHandler, _ := testutils.SetupMicropostHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.POST("/microposts", micropostHandler.CreateMicropost)
+	auth.PUT("/microposts/:id", micropostHandler.UpdateMicropost)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	alice := models.User{Email: "alice@example.com", Handle: "alice", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&alice).Error)
+
+	send := func(method, path string, body map[string]string) models.MicropostResponse {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var response models.MicropostResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	// 存在しないハンドル、メールアドレス、自分自身へのメンションは通知しない
+	created := send(http.MethodPost, "/microposts", map[string]string{
+		"title": "Hello",
+		"body":  "@Alice さん、@unknown と alice@example.com と @testuser",
+	})
+	assert.Len(t, created.Mentions, 2)
+	assert.Equal(t, alice.ID, created.Mentions[0].UserID)
+	assert.Equal(t, models.TextEntity{Field: "body", Start: 0, End: 6, Text: "Alice"}, created.Mentions[0].TextEntity)
+	assert.Equal(t, user.ID, created.Mentions[1].UserID)
+
+	var count int64
+	testutils.TestDB.Model(&models.Notification{}).Where("user_id = ? AND type = ?", alice.ID, models.NotificationTypeMention).Count(&count)
+	assert.Equal(t, int64(1), count)
+	testutils.TestDB.Model(&models.Notification{}).Where("user_id = ?", user.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	// 更新しても既にメンション済みのユーザーには再通知しない
+	updated := send(http.MethodPut, fmt.Sprintf("/microposts/%d", created.ID), map[string]string{
+		"title": "Hello again",
+		"body":  "@alice",
+	})
+	assert.Len(t, updated.Mentions, 1)
+	testutils.TestDB.Model(&models.Notification{}).Where("user_id = ?", alice.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepost(t *testing.T) {
+	r, handler, _ := testutils.SetupMicropostHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.GET("/microposts", handler.GetMicroposts)
+	auth.POST("/microposts/:id/repost", handler.Repost)
+	auth.DELETE("/microposts/:id/repost", handler.Unrepost)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	original := models.Micropost{Title: "Original", Kind: models.MicropostKindPost, UserID: user.ID}
+	assert.NoError(t, testutils.TestDB.Create(&original).Error)
+
+	send := func(method string, id uint, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, fmt.Sprintf("/microposts/%d/repost", id), &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// コメントなしの再投稿
+	w := send(http.MethodPost, original.ID, nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var repost models.MicropostResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &repost))
+	assert.Equal(t, models.MicropostKindRepost, repost.Kind)
+	assert.Equal(t, original.ID, repost.RepostOf.ID)
+	assert.Equal(t, user.Email, repost.RepostedBy.Email)
+
+	// 重複した再投稿は 409
+	w = send(http.MethodPost, original.ID, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// 引用は何度でもできる
+	w = send(http.MethodPost, original.ID, models.RepostRequest{Title: "Must read"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var quote models.MicropostResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &quote))
+	assert.Equal(t, models.MicropostKindQuote, quote.Kind)
+	assert.Nil(t, quote.RepostedBy)
+
+	// 一覧では元投稿に再投稿数と引用数が付く
+	req := httptest.NewRequest(http.MethodGet, "/microposts", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var list []models.MicropostResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list, 3)
+	for _, m := range list {
+		if m.ID == original.ID {
+			assert.Equal(t, int64(1), m.RepostCount)
+			assert.Equal(t, int64(1), m.QuoteCount)
+			assert.True(t, m.RepostedByMe)
+		}
+	}
+
+	// 再投稿の取り消し
+	w = send(http.MethodDelete, original.ID, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = send(http.MethodPost, original.ID, nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
@@ -0,0 +1,151 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevisions(t *testing.T) {
+	r, handler, _ := testutils.SetupMicropostHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.GET("/microposts/:id", handler.GetMicropost)
+	auth.PUT("/microposts/:id", handler.UpdateMicropost)
+	auth.DELETE("/microposts/:id", handler.DeleteMicropost)
+	auth.GET("/microposts/:id/revisions", handler.GetRevisions)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	other := models.User{Email: "other@example.com", Handle: "other", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&other).Error)
+	otherToken, err := utils.GenerateJWTToken(other)
+	assert.NoError(t, err)
+	moderator := models.User{Email: "admin@example.com", Handle: "admin", Password: "password123", Role: models.RoleAdmin}
+	assert.NoError(t, testutils.TestDB.Create(&moderator).Error)
+	adminToken, err := utils.GenerateJWTToken(moderator)
+	assert.NoError(t, err)
+
+	micropostService := services.NewMicropostService(testutils.TestDB)
+	post := models.Micropost{Title: "Hello", Body: "今日は晴れです", UserID: user.ID}
+	assert.NoError(t, micropostService.Create(&post))
+
+	request := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	revisions := func(path, token string) models.MicropostRevisionListResponse {
+		w := request(http.MethodGet, path, token, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.MicropostRevisionListResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+	path := fmt.Sprintf("/microposts/%d", post.ID)
+
+	t.Run("original", func(t *testing.T) {
+		response := revisions(path+"/revisions", otherToken)
+		assert.Equal(t, int64(1), response.Total)
+		assert.Equal(t, 1, response.Revisions[0].Version)
+		assert.Equal(t, []models.DiffSegment{{Op: models.DiffInsert, Text: "今日は晴れです"}}, response.Revisions[0].BodyDiff)
+
+		var micropost models.MicropostResponse
+		assert.NoError(t, json.Unmarshal(request(http.MethodGet, path, otherToken, nil).Body.Bytes(), &micropost))
+		assert.Nil(t, micropost.EditedAt)
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			body            map[string]string
+			expectedVersion int
+		}{
+			{name: "Body", body: map[string]string{"title": "Hello", "body": "今日は雨です"}, expectedVersion: 2},
+			{name: "Unchanged", body: map[string]string{"title": "Hello", "body": "今日は雨です"}, expectedVersion: 2},
+			{name: "Title", body: map[string]string{"title": "Hello again", "body": "今日は雨です"}, expectedVersion: 3},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := request(http.MethodPut, path, token, tt.body)
+				assert.Equal(t, http.StatusOK, w.Code)
+				var micropost models.MicropostResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &micropost))
+				assert.NotNil(t, micropost.EditedAt)
+
+				response := revisions(path+"/revisions", otherToken)
+				assert.Equal(t, int64(tt.expectedVersion), response.Total)
+				assert.Equal(t, tt.expectedVersion, response.Revisions[0].Version)
+				assert.Equal(t, tt.body["title"], response.Revisions[0].Title)
+			})
+		}
+	})
+
+	t.Run("diffs", func(t *testing.T) {
+		response := revisions(path+"/revisions", otherToken)
+		assert.Equal(t, []models.DiffSegment{
+			{Op: models.DiffEqual, Text: "Hello"},
+			{Op: models.DiffInsert, Text: " again"},
+		}, response.Revisions[0].TitleDiff)
+		assert.Equal(t, []models.DiffSegment{
+			{Op: models.DiffEqual, Text: "今日は"},
+			{Op: models.DiffDelete, Text: "晴れ"},
+			{Op: models.DiffInsert, Text: "雨"},
+			{Op: models.DiffEqual, Text: "です"},
+		}, response.Revisions[1].BodyDiff)
+
+		// ページの最後の版も1つ前の版からの差分になる
+		response = revisions(path+"/revisions?page=1&per_page=2", otherToken)
+		assert.Len(t, response.Revisions, 2)
+		assert.Equal(t, 2, response.Revisions[1].Version)
+		assert.Equal(t, models.DiffDelete, response.Revisions[1].BodyDiff[1].Op)
+	})
+
+	t.Run("legacy post", func(t *testing.T) {
+		// 履歴を残す前に作成した投稿は、最初の編集で編集前の内容を版 1 にする
+		legacy := models.Micropost{Title: "Legacy", UserID: user.ID, Kind: models.MicropostKindPost, Status: models.MicropostStatusPublished}
+		assert.NoError(t, testutils.TestDB.Create(&legacy).Error)
+		legacyPath := fmt.Sprintf("/microposts/%d", legacy.ID)
+		assert.Zero(t, revisions(legacyPath+"/revisions", token).Total)
+
+		assert.Equal(t, http.StatusOK, request(http.MethodPut, legacyPath, token, map[string]string{"title": "Legacy edited"}).Code)
+		response := revisions(legacyPath+"/revisions", token)
+		assert.Equal(t, int64(2), response.Total)
+		assert.Equal(t, "Legacy", response.Revisions[1].Title)
+	})
+
+	t.Run("deleted post", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, path, token, nil).Code)
+
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, path+"/revisions", otherToken, nil).Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, path+"/revisions", token, nil).Code)
+		assert.Equal(t, int64(3), revisions(path+"/revisions", adminToken).Total)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/microposts/999999/revisions", adminToken, nil).Code)
+		// 数値でない ID は SQL の条件として扱わない
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/microposts/id%20%3E%200", otherToken, nil).Code)
+	})
+}
@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchMicroposts(t *testing.T) {
+	r, handler, _ := testutils.SetupMicropostHandler()
+
+	// ルートの設定
+	r.GET("/microposts/search", middlewares.AuthMiddleware(), handler.SearchMicroposts)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+
+	posts := []models.Micropost{
+		{Title: "Weekend hiking", Body: "Climbing the mountain near Tokyo", UserID: user.ID},
+		{Title: "東京の天気", Body: "今日は東京で雨が降りました", UserID: user.ID},
+		{Title: "Cooking", Body: "Made <b>curry</b> tonight", UserID: user.ID},
+	}
+	for i := range posts {
+		assert.NoError(t, testutils.TestDB.Create(&posts[i]).Error)
+	}
+
+	tests := []struct {
+		name             string
+		query            string
+		expectedStatus   int
+		expectedStrategy string
+		expectedTitles   []string
+		expectedSnippet  string
+	}{
+		{
+			name:             "English Full Text",
+			query:            "mountain",
+			expectedStatus:   http.StatusOK,
+			expectedStrategy: "fulltext",
+			expectedTitles:   []string{"Weekend hiking"},
+			expectedSnippet:  "<mark>mountain</mark>",
+		},
+		{
+			name:             "Japanese Substring",
+			query:            "東京",
+			expectedStatus:   http.StatusOK,
+			expectedStrategy: "trigram",
+			expectedTitles:   []string{"東京の天気"},
+			expectedSnippet:  "<mark>東京</mark>",
+		},
+		{
+			name:             "Snippet Is Escaped",
+			query:            "curry",
+			expectedStatus:   http.StatusOK,
+			expectedStrategy: "fulltext",
+			expectedTitles:   []string{"Cooking"},
+			expectedSnippet:  "&lt;b&gt;",
+		},
+		{
+			name:           "Empty Query",
+			query:          "  ",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/microposts/search?q="+url.QueryEscape(tt.query), nil)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response models.MicropostSearchResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedStrategy, response.Strategy)
+			assert.Equal(t, int64(len(tt.expectedTitles)), response.Total)
+			for i, title := range tt.expectedTitles {
+				assert.Equal(t, title, response.Results[i].Title)
+				assert.Contains(t, response.Results[i].Snippet, tt.expectedSnippet)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMicropostThread(t *testing.T) {
+	r, handler, _ := testutils.SetupMicropostHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.POST("/microposts/:id/replies", handler.CreateReply)
+	auth.GET("/microposts/:id/thread", handler.GetThread)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	root := models.Micropost{Title: "Root", UserID: user.ID}
+	assert.NoError(t, testutils.TestDB.Create(&root).Error)
+
+	reply := func(parentID uint, title string) (int, models.MicropostResponse) {
+		body, _ := json.Marshal(models.MicropostRequest{Title: title})
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/microposts/%d/replies", parentID), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var response models.MicropostResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	getThread := func(id uint) models.ThreadResponse {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/microposts/%d/thread", id), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var thread models.ThreadResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &thread))
+		return thread
+	}
+
+	status, first := reply(root.ID, "First reply")
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, root.ID, *first.ParentID)
+
+	status, nested := reply(first.ID, "Nested reply")
+	assert.Equal(t, http.StatusCreated, status)
+
+	status, _ = reply(root.ID, "Second reply")
+	assert.Equal(t, http.StatusCreated, status)
+
+	status, _ = reply(999999, "Orphan")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// ルートから見たスレッド
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/microposts/%d/thread", root.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var thread models.ThreadResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &thread))
+	assert.Empty(t, thread.Ancestors)
+	assert.Equal(t, int64(2), thread.Micropost.ReplyCount)
+	assert.Equal(t, int64(3), thread.Total)
+	if assert.Len(t, thread.Descendants, 3) {
+		assert.Equal(t, "First reply", thread.Descendants[0].Title)
+		assert.Equal(t, "Nested reply", thread.Descendants[1].Title)
+		assert.Equal(t, 2, thread.Descendants[1].Depth)
+		assert.Equal(t, "Second reply", thread.Descendants[2].Title)
+	}
+
+	// 深い返信から見たスレッド
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/microposts/%d/thread", nested.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &thread))
+	if assert.Len(t, thread.Ancestors, 2) {
+		assert.Equal(t, "Root", thread.Ancestors[0].Title)
+		assert.Equal(t, "First reply", thread.Ancestors[1].Title)
+	}
+	assert.Empty(t, thread.Descendants)
+
+	// 閲覧者から見えない返信はその子孫ごと除き、見えない起点は見つからない
+	other := models.User{Email: "other@example.com", Handle: "other", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&other).Error)
+	blockedReply := models.Micropost{Title: "Blocked reply", UserID: other.ID, ParentID: &root.ID}
+	assert.NoError(t, testutils.TestDB.Create(&blockedReply).Error)
+	_, err = services.NewBlockService(testutils.TestDB).Block(user.ID, other.ID)
+	assert.NoError(t, err)
+	_, hidden := reply(nested.ID, "Hidden reply")
+	assert.NoError(t, testutils.TestDB.Model(&models.Micropost{}).Where("id = ?", hidden.ID).Update("hidden_at", time.Now()).Error)
+
+	thread = getThread(root.ID)
+	assert.Equal(t, int64(3), thread.Total)
+	assert.Len(t, thread.Descendants, 3)
+
+	thread = getThread(nested.ID)
+	assert.Empty(t, thread.Descendants)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/microposts/%d/thread", hidden.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
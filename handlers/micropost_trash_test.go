@@ -0,0 +1,183 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/handlers"
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrash(t *testing.T) {
+	r, handler, _ := testutils.SetupMicropostHandler()
+	userHandler := handlers.NewUserHandler(services.NewUserService(testutils.TestDB))
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.GET("/microposts/trash", handler.GetTrash)
+	auth.GET("/microposts/:id", handler.GetMicropost)
+	auth.DELETE("/microposts/:id", handler.DeleteMicropost)
+	auth.POST("/microposts/:id/restore", handler.RestoreMicropost)
+	admin := r.Group("/admin")
+	admin.Use(middlewares.AuthMiddleware(), middlewares.AdminMiddleware())
+	admin.DELETE("/users/:id", userHandler.DeleteUser)
+	admin.POST("/users/:id/restore", userHandler.RestoreUser)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	other := models.User{Email: "other@example.com", Handle: "other", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&other).Error)
+	otherToken, err := utils.GenerateJWTToken(other)
+	assert.NoError(t, err)
+	moderator := models.User{Email: "admin@example.com", Handle: "admin", Password: "password123", Role: models.RoleAdmin}
+	assert.NoError(t, testutils.TestDB.Create(&moderator).Error)
+	adminToken, err := utils.GenerateJWTToken(moderator)
+	assert.NoError(t, err)
+
+	micropostService := services.NewMicropostService(testutils.TestDB)
+	post := models.Micropost{Title: "Hello", UserID: user.ID}
+	assert.NoError(t, micropostService.Create(&post))
+	expired := models.Micropost{Title: "Old", UserID: user.ID}
+	assert.NoError(t, micropostService.Create(&expired))
+	repost, err := micropostService.Repost(other.ID, post.ID)
+	assert.NoError(t, err)
+
+	request := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("delete", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			path           string
+			token          string
+			expectedStatus int
+		}{
+			{name: "Not Author", path: fmt.Sprintf("/microposts/%d", post.ID), token: otherToken, expectedStatus: http.StatusForbidden},
+			{name: "Author", path: fmt.Sprintf("/microposts/%d", post.ID), token: token, expectedStatus: http.StatusNoContent},
+			{name: "Already Deleted", path: fmt.Sprintf("/microposts/%d", post.ID), token: token, expectedStatus: http.StatusNotFound},
+			{name: "Admin", path: fmt.Sprintf("/microposts/%d", expired.ID), token: adminToken, expectedStatus: http.StatusNoContent},
+			{name: "Plain Repost", path: fmt.Sprintf("/microposts/%d", repost.ID), token: otherToken, expectedStatus: http.StatusNoContent},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := request(http.MethodDelete, tt.path, tt.token)
+				assert.Equal(t, tt.expectedStatus, w.Code)
+			})
+		}
+
+		w := request(http.MethodGet, fmt.Sprintf("/microposts/%d", post.ID), token)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		// コメントなしの再投稿はゴミ箱に残さない
+		var count int64
+		testutils.TestDB.Unscoped().Model(&models.Micropost{}).Where("id = ?", repost.ID).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("trash", func(t *testing.T) {
+		w := request(http.MethodGet, "/microposts/trash", token)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.MicropostListResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(2), response.Total)
+		assert.Equal(t, expired.ID, response.Microposts[0].ID)
+		assert.NotNil(t, response.Microposts[0].DeletedAt)
+		assert.NotNil(t, response.Microposts[0].PurgeAt)
+
+		w = request(http.MethodGet, "/microposts/trash", otherToken)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Zero(t, response.Total)
+	})
+
+	// 保存期間を過ぎた状態にする
+	past := time.Now().Add(-models.TrashRetention - time.Hour)
+	assert.NoError(t, testutils.TestDB.Unscoped().Model(&models.Micropost{}).Where("id = ?", expired.ID).Update("deleted_at", past).Error)
+
+	t.Run("restore", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			path           string
+			token          string
+			expectedStatus int
+		}{
+			{name: "Not Author", path: fmt.Sprintf("/microposts/%d/restore", post.ID), token: otherToken, expectedStatus: http.StatusForbidden},
+			{name: "Expired", path: fmt.Sprintf("/microposts/%d/restore", expired.ID), token: token, expectedStatus: http.StatusGone},
+			{name: "Author", path: fmt.Sprintf("/microposts/%d/restore", post.ID), token: token, expectedStatus: http.StatusOK},
+			{name: "Not Deleted", path: fmt.Sprintf("/microposts/%d/restore", post.ID), token: token, expectedStatus: http.StatusNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := request(http.MethodPost, tt.path, tt.token)
+				assert.Equal(t, tt.expectedStatus, w.Code)
+			})
+		}
+
+		w := request(http.MethodGet, fmt.Sprintf("/microposts/%d", post.ID), otherToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		result, err := services.NewTrashService(testutils.TestDB).Purge(time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Microposts)
+
+		var count int64
+		testutils.TestDB.Unscoped().Model(&models.Micropost{}).Where("id = ?", expired.ID).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("delete and restore user", func(t *testing.T) {
+		path := fmt.Sprintf("/admin/users/%d", user.ID)
+		assert.Equal(t, http.StatusForbidden, request(http.MethodDelete, path, otherToken).Code)
+		assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, path, adminToken).Code)
+
+		// 削除されたユーザーの投稿は見えず、ユーザーは投稿できない
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, fmt.Sprintf("/microposts/%d", post.ID), otherToken).Code)
+		assert.ErrorIs(t, micropostService.Create(&models.Micropost{Title: "Still here", UserID: user.ID}), services.ErrAccountDeleted)
+
+		// ユーザーと一緒に削除された投稿は個別に復元できない
+		assert.Equal(t, http.StatusNotFound, request(http.MethodPost, fmt.Sprintf("/microposts/%d/restore", post.ID), adminToken).Code)
+
+		// メールアドレスとハンドルは予約されたまま
+		taken, err := services.NewUserService(testutils.TestDB).EmailTaken(user.Email)
+		assert.NoError(t, err)
+		assert.True(t, taken)
+
+		assert.Equal(t, http.StatusOK, request(http.MethodPost, path+"/restore", adminToken).Code)
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, fmt.Sprintf("/microposts/%d", post.ID), otherToken).Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodPost, path+"/restore", adminToken).Code)
+	})
+
+	t.Run("purge user", func(t *testing.T) {
+		past := time.Now().Add(-models.TrashRetention - time.Hour)
+		assert.NoError(t, testutils.TestDB.Model(&models.Micropost{}).Where("user_id = ?", other.ID).Update("deleted_at", past).Error)
+		assert.NoError(t, testutils.TestDB.Model(&other).Update("deleted_at", past).Error)
+
+		result, err := services.NewTrashService(testutils.TestDB).Purge(time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Users)
+
+		var count int64
+		testutils.TestDB.Unscoped().Model(&models.User{}).Where("id = ?", other.ID).Count(&count)
+		assert.Zero(t, count)
+	})
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ModerationHandler struct {
+	moderationService *services.ModerationService
+}
+
+func NewModerationHandler(moderationService *services.ModerationService) *ModerationHandler {
+	return &ModerationHandler{moderationService: moderationService}
+}
+
+// ReportMicropost godoc
+// @Summary      Report micropost
+// @Description  Report a micropost to the moderators. A user can have one open report per post.
+// @Tags         reports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      int                   true  "Micropost ID"
+// @Param        report  body      models.ReportRequest  true  "Reason"
+// @Success      201  {object}  models.ReportResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /microposts/{id}/report [post]
+func (h *ModerationHandler) ReportMicropost(c *gin.Context) {
+	h.createReport(c, h.moderationService.ReportMicropost, i18n.ErrRecordNotFound)
+}
+
+// ReportUser godoc
+// @Summary      Report user
+// @Description  Report a user to the moderators. A user can have one open report per reported user.
+// @Tags         reports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      int                   true  "User ID"
+// @Param        report  body      models.ReportRequest  true  "Reason"
+// @Success      201  {object}  models.ReportResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /users/{id}/report [post]
+func (h *ModerationHandler) ReportUser(c *gin.Context) {
+	h.createReport(c, h.moderationService.ReportUser, i18n.ErrUserNotFound)
+}
+
+func (h *ModerationHandler) createReport(c *gin.Context, create func(reporterID, targetID uint, req models.ReportRequest) (*models.Report, error), notFound string) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+	req.Normalize()
+
+	report, err := create(userID, targetID, req)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, notFound)
+		return
+	case errors.Is(err, services.ErrCannotReportSelf):
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrCannotReportSelf)
+		return
+	case errors.Is(err, services.ErrAlreadyReported):
+		utils.ErrorJSON(c, http.StatusConflict, i18n.ErrAlreadyReported)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToCreateReport)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report.ToResponse())
+}
+
+// GetReports godoc
+// @Summary      Moderation queue
+// @Description  List reports by status (admin only). Open reports are listed oldest first, resolved reports most recently resolved first. report_count is the number of open reports on the same target.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status    query     string  false  "Report status (open, dismissed, actioned; default open)"
+// @Param        page      query     int     false  "Page number"
+// @Param        per_page  query     int     false  "Results per page (max 100)"
+// @Success      200  {object}  models.ReportListResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /admin/reports [get]
+func (h *ModerationHandler) GetReports(c *gin.Context) {
+	var query models.ReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	reports, total, err := h.moderationService.Queue(query)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchReports)
+		return
+	}
+
+	response := models.ReportListResponse{Reports: make([]models.ReportResponse, 0, len(reports)), PageMeta: query.Meta(total)}
+	for _, report := range reports {
+		response.Reports = append(response.Reports, report.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ResolveReport godoc
+// @Summary      Resolve report
+// @Description  Act on an open report (admin only): dismiss it, hide the reported post, or suspend the reported user. Hiding a post resolves all open reports on that post; suspending a user resolves all open reports on the user and their posts. Dismissing a report filed by the content filter restores the post if the filter hid it. Every decision is recorded in the audit trail.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      int                       true  "Report ID"
+// @Param        action  body      models.ModerationRequest  true  "Moderation action"
+// @Success      200  {object}  models.ReportResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /admin/reports/{id}/actions [post]
+func (h *ModerationHandler) ResolveReport(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+	req.Note = models.NormalizeText(req.Note, true)
+
+	report, err := h.moderationService.Resolve(userID, id, req)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrReportNotFound)
+		return
+	case errors.Is(err, services.ErrReportResolved):
+		utils.ErrorJSON(c, http.StatusConflict, i18n.ErrReportResolved)
+		return
+	case errors.Is(err, services.ErrInvalidModerationAction):
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrInvalidModerationAction)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToResolveReport)
+		return
+	}
+
+	c.JSON(http.StatusOK, report.ToResponse())
+}
+
+// GetModerationActions godoc
+// @Summary      Moderation audit trail
+// @Description  List moderator decisions, newest first (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.ModerationActionListResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /admin/moderation-actions [get]
+func (h *ModerationHandler) GetModerationActions(c *gin.Context) {
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	actions, total, err := h.moderationService.Actions(page)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchReports)
+		return
+	}
+
+	response := models.ModerationActionListResponse{Actions: make([]models.ModerationActionResponse, 0, len(actions)), PageMeta: page.Meta(total)}
+	for _, action := range actions {
+		response.Actions = append(response.Actions, action.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,195 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestModeration(t *testing.T) {
+	r, moderationHandler := testutils.SetupModerationHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.POST("/microposts/:id/report", moderationHandler.ReportMicropost)
+	auth.POST("/users/:id/report", moderationHandler.ReportUser)
+	admin := r.Group("/admin")
+	admin.Use(middlewares.AuthMiddleware(), middlewares.AdminMiddleware())
+	admin.GET("/reports", moderationHandler.GetReports)
+	admin.POST("/reports/:id/actions", moderationHandler.ResolveReport)
+	admin.GET("/moderation-actions", moderationHandler.GetModerationActions)
+
+	reporter, reporterToken, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	hashed, err := utils.HashPassword("password123")
+	assert.NoError(t, err)
+	spammer := models.User{Email: "spammer@example.com", Handle: "spammer", Password: hashed}
+	assert.NoError(t, testutils.TestDB.Create(&spammer).Error)
+	moderator := models.User{Email: "admin@example.com", Handle: "admin", Password: "password123", Role: models.RoleAdmin}
+	assert.NoError(t, testutils.TestDB.Create(&moderator).Error)
+	adminToken, err := utils.GenerateJWTToken(moderator)
+	assert.NoError(t, err)
+
+	micropostService := services.NewMicropostService(testutils.TestDB)
+	spam := models.Micropost{Title: "Buy now", UserID: spammer.ID}
+	assert.NoError(t, micropostService.Create(&spam))
+	other := models.Micropost{Title: "Another ad", UserID: spammer.ID}
+	assert.NoError(t, micropostService.Create(&other))
+
+	request := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			b, _ := json.Marshal(body)
+			reader = bytes.NewReader(b)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("report", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			path           string
+			token          string
+			body           interface{}
+			expectedStatus int
+		}{
+			{name: "Report Micropost", path: fmt.Sprintf("/microposts/%d/report", spam.ID), token: reporterToken, body: models.ReportRequest{Reason: "spam", Comment: "  宣伝です  "}, expectedStatus: http.StatusCreated},
+			{name: "Duplicate", path: fmt.Sprintf("/microposts/%d/report", spam.ID), token: reporterToken, body: models.ReportRequest{Reason: "spam"}, expectedStatus: http.StatusConflict},
+			{name: "Another Reporter", path: fmt.Sprintf("/microposts/%d/report", spam.ID), token: adminToken, body: models.ReportRequest{Reason: "other"}, expectedStatus: http.StatusCreated},
+			{name: "Report User", path: fmt.Sprintf("/users/%d/report", spammer.ID), token: reporterToken, body: models.ReportRequest{Reason: "harassment"}, expectedStatus: http.StatusCreated},
+			{name: "Report Self", path: fmt.Sprintf("/users/%d/report", reporter.ID), token: reporterToken, body: models.ReportRequest{Reason: "spam"}, expectedStatus: http.StatusBadRequest},
+			{name: "Invalid Reason", path: fmt.Sprintf("/microposts/%d/report", other.ID), token: reporterToken, body: models.ReportRequest{Reason: "boring"}, expectedStatus: http.StatusBadRequest},
+			{name: "Unknown Micropost", path: "/microposts/999999/report", token: reporterToken, body: models.ReportRequest{Reason: "spam"}, expectedStatus: http.StatusNotFound},
+			{name: "Unknown User", path: "/users/999999/report", token: reporterToken, body: models.ReportRequest{Reason: "spam"}, expectedStatus: http.StatusNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := request(http.MethodPost, tt.path, tt.token, tt.body)
+				assert.Equal(t, tt.expectedStatus, w.Code)
+				if tt.expectedStatus == http.StatusCreated {
+					var response models.ReportResponse
+					assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+					assert.Equal(t, models.ReportStatusOpen, response.Status)
+					assert.Equal(t, spammer.ID, response.TargetUser.ID)
+				}
+			})
+		}
+	})
+
+	var queue models.ReportListResponse
+	t.Run("queue requires admin", func(t *testing.T) {
+		w := request(http.MethodGet, "/admin/reports", reporterToken, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = request(http.MethodGet, "/admin/reports", adminToken, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &queue))
+		assert.Equal(t, int64(3), queue.Total)
+		// 古い順に並び、同じ投稿への通報数が付く
+		assert.Equal(t, models.ReportTargetMicropost, queue.Reports[0].TargetType)
+		assert.Equal(t, int64(2), queue.Reports[0].ReportCount)
+		assert.Equal(t, "宣伝です", queue.Reports[0].Comment)
+		assert.Equal(t, models.ReportTargetUser, queue.Reports[2].TargetType)
+	})
+
+	resolve := func(reportID uint, action string) *httptest.ResponseRecorder {
+		path := "/admin/reports/" + strconv.FormatUint(uint64(reportID), 10) + "/actions"
+		return request(http.MethodPost, path, adminToken, models.ModerationRequest{Action: action, Note: "checked"})
+	}
+
+	t.Run("hide post", func(t *testing.T) {
+		w := resolve(queue.Reports[2].ID, models.ModerationActionHidePost)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = resolve(queue.Reports[0].ID, models.ModerationActionHidePost)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.ReportResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, models.ReportStatusActioned, response.Status)
+		assert.NotNil(t, response.ResolvedAt)
+
+		// 同じ投稿への通報もまとめて措置済みになる
+		w = resolve(queue.Reports[1].ID, models.ModerationActionDismiss)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		microposts, err := micropostService.GetAll(reporter.ID)
+		assert.NoError(t, err)
+		assert.Len(t, microposts, 1)
+		_, err = micropostService.GetByID(spam.ID, reporter.ID)
+		assert.Error(t, err)
+		// 投稿者本人には見える
+		_, err = micropostService.GetByID(spam.ID, spammer.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("suspend user", func(t *testing.T) {
+		w := resolve(queue.Reports[2].ID, models.ModerationActionSuspendUser)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		microposts, err := micropostService.GetAll(reporter.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, microposts)
+		_, err = micropostService.GetByID(other.ID, reporter.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		err = micropostService.Create(&models.Micropost{Title: "Still here", UserID: spammer.ID})
+		assert.ErrorIs(t, err, services.ErrAccountSuspended)
+		_, err = services.NewAuthService(testutils.TestDB).Login(spammer.Email, "password123")
+		assert.ErrorIs(t, err, services.ErrAccountSuspended)
+	})
+
+	t.Run("dismiss and unknown report", func(t *testing.T) {
+		w := request(http.MethodPost, fmt.Sprintf("/microposts/%d/report", other.ID), reporterToken, models.ReportRequest{Reason: "spam"})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var report models.ReportResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+
+		w = resolve(report.ID, models.ModerationActionDismiss)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, models.ReportStatusDismissed, report.Status)
+
+		w = resolve(999999, models.ModerationActionDismiss)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = request(http.MethodGet, "/admin/reports?status=dismissed", adminToken, nil)
+		var dismissed models.ReportListResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &dismissed))
+		assert.Equal(t, int64(1), dismissed.Total)
+	})
+
+	t.Run("audit trail", func(t *testing.T) {
+		w := request(http.MethodGet, "/admin/moderation-actions", adminToken, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.ModerationActionListResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(3), response.Total)
+		// 新しい順
+		assert.Equal(t, models.ModerationActionDismiss, response.Actions[0].Action)
+		assert.Equal(t, models.ModerationActionSuspendUser, response.Actions[1].Action)
+		assert.Equal(t, models.ModerationActionHidePost, response.Actions[2].Action)
+		assert.Equal(t, moderator.ID, response.Actions[2].Moderator.ID)
+		assert.Equal(t, "checked", response.Actions[2].Note)
+	})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// GetNotifications godoc
+// @Summary      List notifications
+// @Description  List the current user's notifications, newest first. Notifications of the same kind on the same post (and all new followers) are grouped, e.g. "A and 2 others liked your post"; read and unread notifications are grouped separately. Messages are localized.
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.NotificationListResponse
+// @Failure      401  {object}  map[string]string
+// @Router       /notifications [get]
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	groups, total, err := h.notificationService.List(userID, page)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchNotifications)
+		return
+	}
+	unread, err := h.notificationService.UnreadCount(userID)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchNotifications)
+		return
+	}
+
+	locale := i18n.FromContext(c)
+	response := models.NotificationListResponse{
+		Notifications: make([]models.NotificationResponse, 0, len(groups)),
+		UnreadCount:   unread,
+		PageMeta:      page.Meta(total),
+	}
+	for _, group := range groups {
+		response.Notifications = append(response.Notifications, group.ToResponse(notificationMessage(locale, group)))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUnreadCount godoc
+// @Summary      Count unread notifications
+// @Description  Number of unread notification groups (as shown in the list)
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.UnreadCountResponse
+// @Failure      401  {object}  map[string]string
+// @Router       /notifications/unread-count [get]
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	h.respondUnreadCount(c, userID)
+}
+
+// MarkRead godoc
+// @Summary      Mark notification as read
+// @Description  Mark the notification and every unread notification grouped with it as read
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Notification ID (the id of a list entry)"
+// @Success      200  {object}  models.UnreadCountResponse
+// @Failure      404  {object}  map[string]string
+// @Router       /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := h.notificationService.MarkRead(userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToUpdateNotifications)
+		return
+	}
+	h.respondUnreadCount(c, userID)
+}
+
+// MarkAllRead godoc
+// @Summary      Mark all notifications as read
+// @Description  Mark every unread notification of the current user as read
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.UnreadCountResponse
+// @Failure      401  {object}  map[string]string
+// @Router       /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.MarkAllRead(userID); err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToUpdateNotifications)
+		return
+	}
+	h.respondUnreadCount(c, userID)
+}
+
+func (h *NotificationHandler) respondUnreadCount(c *gin.Context, userID uint) {
+	unread, err := h.notificationService.UnreadCount(userID)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchNotifications)
+		return
+	}
+	c.JSON(http.StatusOK, models.UnreadCountResponse{UnreadCount: unread})
+}
+
+// notificationMessage は「○○さんと他2人が…」形式の表示用メッセージを組み立てる
+func notificationMessage(locale string, group models.NotificationGroup) string {
+	name := ""
+	if len(group.Actors) > 0 {
+		name = group.Actors[0].Name()
+	}
+	key := "notification." + group.Type
+	if group.ActorCount > 1 {
+		return i18n.T(locale, key+".others", name, group.ActorCount-1)
+	}
+	return i18n.T(locale, key, name)
+}
@@ -0,0 +1,145 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifications(t *testing.T) {
+	r, notificationHandler := testutils.SetupNotificationHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.GET("/notifications", notificationHandler.GetNotifications)
+	auth.GET("/notifications/unread-count", notificationHandler.GetUnreadCount)
+	auth.POST("/notifications/read-all", notificationHandler.MarkAllRead)
+	auth.POST("/notifications/:id/read", notificationHandler.MarkRead)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	micropost := models.Micropost{Title: "Popular", UserID: user.ID}
+	assert.NoError(t, testutils.TestDB.Create(&micropost).Error)
+
+	likeService := services.NewLikeService(testutils.TestDB)
+	followService := services.NewFollowService(testutils.TestDB)
+	var actors []models.User
+	for _, handle := range []string{"alice", "bob", "carol"} {
+		actor := models.User{Email: handle + "@example.com", Handle: handle, Password: "password123"}
+		assert.NoError(t, testutils.TestDB.Create(&actor).Error)
+		actors = append(actors, actor)
+		_, err := likeService.Like(actor.ID, micropost.ID)
+		assert.NoError(t, err)
+	}
+	_, err = followService.Follow(actors[0].ID, user.ID)
+	assert.NoError(t, err)
+	// 自分の投稿へのいいねは通知しない
+	_, err = likeService.Like(user.ID, micropost.ID)
+	assert.NoError(t, err)
+
+	request := func(method, path, locale string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if locale != "" {
+			req.Header.Set("Accept-Language", locale)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	list := func(locale string) models.NotificationListResponse {
+		w := request(http.MethodGet, "/notifications", locale)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.NotificationListResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	// いいね3件は1件にまとめる
+	response := list("en")
+	assert.Equal(t, int64(2), response.Total)
+	assert.Equal(t, int64(2), response.UnreadCount)
+	follow, like := response.Notifications[0], response.Notifications[1]
+	assert.Equal(t, models.NotificationTypeFollow, follow.Type)
+	assert.Equal(t, "@alice followed you", follow.Message)
+	assert.Equal(t, models.NotificationTypeLike, like.Type)
+	assert.Equal(t, int64(3), like.ActorCount)
+	assert.Len(t, like.Actors, 3)
+	assert.Equal(t, "@carol and 2 others liked your post", like.Message)
+	assert.Equal(t, "@carolさんと他2人があなたの投稿にいいねしました", list("ja").Notifications[1].Message)
+
+	// いいねの取り消しで通知も消える
+	_, err = likeService.Unlike(actors[1].ID, micropost.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), list("").Notifications[1].ActorCount)
+
+	// グループ単位で既読にする
+	w := request(http.MethodPost, fmt.Sprintf("/notifications/%d/read", like.ID), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"unread_count":1}`, w.Body.String())
+
+	w = request(http.MethodPost, "/notifications/999999/read", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request(http.MethodPost, "/notifications/read-all", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"unread_count":0}`, w.Body.String())
+
+	response = list("")
+	for _, notification := range response.Notifications {
+		assert.False(t, notification.Unread)
+	}
+}
+
+// TestUnfollowRetractsOnlyItsNotification はフォロー解除で解除した相手への通知だけを削除することを確認する
+func TestUnfollowRetractsOnlyItsNotification(t *testing.T) {
+	testutils.SetupNotificationHandler()
+
+	var users []models.User
+	for _, handle := range []string{"alice", "bob", "carol"} {
+		user := models.User{Email: handle + "@example.com", Handle: handle, Password: "password123"}
+		assert.NoError(t, testutils.TestDB.Create(&user).Error)
+		users = append(users, user)
+	}
+	a, b, c := users[0], users[1], users[2]
+
+	followService := services.NewFollowService(testutils.TestDB)
+	for _, followed := range []models.User{b, c} {
+		_, err := followService.Follow(a.ID, followed.ID)
+		assert.NoError(t, err)
+	}
+	_, err := followService.Unfollow(a.ID, b.ID)
+	assert.NoError(t, err)
+	// フォローしていない相手の解除は何もしない
+	_, err = followService.Unfollow(a.ID, b.ID)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		userID   uint
+		expected int64
+	}{
+		{name: "Unfollowed", userID: b.ID, expected: 0},
+		{name: "Still Followed", userID: c.ID, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var count int64
+			testutils.TestDB.Model(&models.Notification{}).
+				Where("type = ? AND user_id = ? AND actor_id = ?", models.NotificationTypeFollow, tt.userID, a.ID).
+				Count(&count)
+			assert.Equal(t, tt.expected, count)
+		})
+	}
+}
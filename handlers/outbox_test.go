@@ -0,0 +1,181 @@
+package handlers_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestOutbox(t *testing.T) {
+	r, authHandler := testutils.SetupAuthHandler()
+
+	// ルートの設定
+	r.POST("/auth/signup", authHandler.SignupUser)
+
+	signup := func(email string) {
+		body := `{"email":"` + email + `","password":"password123"}`
+		req := httptest.NewRequest(http.MethodPost, "/auth/signup", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+	eventTypes := func() []string {
+		var types []string
+		assert.NoError(t, testutils.TestDB.Model(&models.OutboxEvent{}).Order("id").Pluck("type", &types).Error)
+		return types
+	}
+
+	t.Run("events are recorded with the change", func(t *testing.T) {
+		signup("alice@example.com")
+		var alice models.User
+		assert.NoError(t, testutils.TestDB.Where("email = ?", "alice@example.com").First(&alice).Error)
+
+		micropostService := services.NewMicropostService(testutils.TestDB)
+		post := models.Micropost{Title: "Hello", Body: "world", UserID: alice.ID}
+		assert.NoError(t, micropostService.Create(&post))
+		assert.Equal(t, []string{models.EventUserSignedUp, models.EventMicropostCreated}, eventTypes())
+
+		var event models.OutboxEvent
+		assert.NoError(t, testutils.TestDB.Where("type = ?", models.EventMicropostCreated).First(&event).Error)
+		assert.Equal(t, post.ID, event.AggregateID)
+		var payload models.MicropostCreated
+		assert.NoError(t, json.Unmarshal([]byte(event.Payload), &payload))
+		assert.Equal(t, alice.ID, payload.UserID)
+		assert.Equal(t, "world", payload.Body)
+
+		// 失敗した変更のイベントは残らない
+		now := time.Now()
+		assert.NoError(t, testutils.TestDB.Model(&alice).Update("suspended_at", &now).Error)
+		assert.Error(t, micropostService.Create(&models.Micropost{Title: "Hello", Body: "again", UserID: alice.ID}))
+		assert.NoError(t, testutils.TestDB.Model(&alice).Update("suspended_at", nil).Error)
+		assert.Len(t, eventTypes(), 2)
+	})
+
+	t.Run("dispatch resumes from offsets", func(t *testing.T) {
+		dispatcher := services.NewEventDispatcher(testutils.TestDB)
+
+		var signups []uint
+		dispatcher.Subscribe("signups", func(ctx context.Context, tx *gorm.DB, event *models.OutboxEvent) error {
+			signups = append(signups, event.AggregateID)
+			return nil
+		}, models.EventUserSignedUp)
+
+		fail := true
+		var all []string
+		dispatcher.Subscribe("flaky", func(ctx context.Context, tx *gorm.DB, event *models.OutboxEvent) error {
+			if event.Type == models.EventMicropostCreated && fail {
+				return errors.New("subscriber unavailable")
+			}
+			all = append(all, event.Type)
+			return nil
+		})
+
+		var buf bytes.Buffer
+		dispatcher.AddSink("log", services.NewJSONLinesSink(&buf), models.EventMicropostCreated)
+
+		delivered, err := dispatcher.Dispatch(context.Background())
+		assert.ErrorContains(t, err, "flaky")
+		assert.Equal(t, 3, delivered)
+		assert.Len(t, signups, 1)
+		assert.Equal(t, []string{models.EventUserSignedUp}, all)
+
+		var line struct {
+			Type    string                  `json:"type"`
+			Payload models.MicropostCreated `json:"payload"`
+		}
+		scanner := bufio.NewScanner(&buf)
+		assert.True(t, scanner.Scan())
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		assert.Equal(t, models.EventMicropostCreated, line.Type)
+		assert.Equal(t, "world", line.Payload.Body)
+		assert.False(t, scanner.Scan())
+
+		// 失敗したイベントから再開し、配信済みのイベントは送らない
+		fail = false
+		signup("bob@example.com")
+		delivered, err = dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, delivered)
+		assert.Len(t, signups, 2)
+		assert.Equal(t, []string{models.EventUserSignedUp, models.EventMicropostCreated, models.EventUserSignedUp}, all)
+		assert.Empty(t, buf.String())
+
+		delivered, err = dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		assert.Zero(t, delivered)
+
+		// 全員に配信したイベントだけ削除する
+		pruned, err := dispatcher.Prune(time.Now().Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), pruned)
+		assert.Empty(t, eventTypes())
+	})
+
+	t.Run("handler writes commit with the offset", func(t *testing.T) {
+		dispatcher := services.NewEventDispatcher(testutils.TestDB)
+		dispatcher.Subscribe("welcome", func(ctx context.Context, tx *gorm.DB, event *models.OutboxEvent) error {
+			var payload models.UserSignedUp
+			if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+				return err
+			}
+			if err := tx.Model(&models.User{}).Where("id = ?", payload.UserID).Update("bio", "welcome").Error; err != nil {
+				return err
+			}
+			if payload.Handle == "carol" {
+				return errors.New("rolled back")
+			}
+			return nil
+		}, models.EventUserSignedUp)
+
+		signup("carol@example.com")
+		_, err := dispatcher.Dispatch(context.Background())
+		assert.Error(t, err)
+
+		var carol models.User
+		assert.NoError(t, testutils.TestDB.Where("email = ?", "carol@example.com").First(&carol).Error)
+		assert.Empty(t, carol.Bio)
+	})
+	t.Run("events committed out of order are not skipped", func(t *testing.T) {
+		var followed []uint
+		dispatcher := services.NewEventDispatcher(testutils.TestDB)
+		dispatcher.Subscribe("follows", func(ctx context.Context, tx *gorm.DB, event *models.OutboxEvent) error {
+			var payload models.UserFollowed
+			if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+				return err
+			}
+			followed = append(followed, payload.FollowedID)
+			return nil
+		}, models.EventUserFollowed)
+
+		// 先に書き込んだトランザクションがあとからコミットされる
+		slow := testutils.TestDB.Begin()
+		assert.NoError(t, services.RecordEvent(slow, models.UserFollowed{FollowerID: 1, FollowedID: 1}))
+		assert.NoError(t, testutils.TestDB.Transaction(func(tx *gorm.DB) error {
+			return services.RecordEvent(tx, models.UserFollowed{FollowerID: 1, FollowedID: 2})
+		}))
+
+		_, err := dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, followed)
+
+		assert.NoError(t, slow.Commit().Error)
+		_, err = dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{1, 2}, followed)
+	})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID は AuthMiddleware が設定したログインユーザーの ID を返す
+func currentUserID(c *gin.Context) (uint, bool) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		return 0, false
+	}
+	id, ok := userID.(uint)
+	return id, ok
+}
+
+// requireUserID は未ログインなら 401 を返して false を返す
+func requireUserID(c *gin.Context) (uint, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.ErrorJSON(c, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+	return userID, ok
+}
+
+// isAdmin はログインユーザーが管理者かどうかを返す
+func isAdmin(c *gin.Context) bool {
+	return c.GetString("role") == models.RoleAdmin
+}
+
+// idParam はパスパラメータを ID として解釈し、不正なら 404 を返して false を返す
+func idParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return 0, false
+	}
+	return uint(id), true
+}
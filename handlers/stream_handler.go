@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-gin-gorm-minimum/realtime"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// ストリームの接続維持のためのコメント送信間隔（プロキシのアイドルタイムアウト対策）
+const streamHeartbeat = 25 * time.Second
+
+type StreamHandler struct {
+	hub *realtime.Hub
+}
+
+func NewStreamHandler(hub *realtime.Hub) *StreamHandler {
+	return &StreamHandler{hub: hub}
+}
+
+// Stream godoc
+// @Summary      Stream real-time events
+// @Description  Server-Sent Events stream of new microposts from the current user and followed users (event "micropost", data is a MicropostResponse without viewer-specific counts), new notifications for the current user (event "notification") and new direct messages in the current user's conversations (event "message", data is a MessageResponse). When data is omitted the client should fetch the resource by id. A comment line is sent periodically to keep the connection open.
+// @Tags         stream
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Success      200  {object}  realtime.Event
+// @Failure      401  {object}  map[string]string
+// @Router       /stream [get]
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	sub := h.hub.Subscribe(userID)
+	defer h.hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// nginx などのリバースプロキシにバッファリングさせない
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case event, ok := <-sub.C:
+			if !ok {
+				return false
+			}
+			c.Render(-1, sse.Event{
+				Id:    event.Type + "-" + strconv.FormatUint(uint64(event.ID), 10),
+				Event: event.Type,
+				Data:  event,
+			})
+			return true
+		}
+	})
+}
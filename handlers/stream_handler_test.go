@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/handlers"
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/realtime"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream(t *testing.T) {
+	r, _ := testutils.SetupUserHandler()
+
+	hub := realtime.NewHub(realtime.NewMemoryBroker(), services.NewFollowerAudience(testutils.TestDB))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+	services.SetEventPublisher(hub)
+	defer services.SetEventPublisher(nil)
+
+	// ルートの設定
+	r.GET("/stream", middlewares.AuthMiddleware(), handlers.NewStreamHandler(hub).Stream)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	followed := models.User{Email: "followed@example.com", Handle: "followed", Password: "password123"}
+	stranger := models.User{Email: "stranger@example.com", Handle: "stranger", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&followed).Error)
+	assert.NoError(t, testutils.TestDB.Create(&stranger).Error)
+	_, err = services.NewFollowService(testutils.TestDB).Follow(user.ID, followed.ID)
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/stream", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// フォローしていないユーザーの投稿は届かず、フォロー中のユーザーの投稿といいねの通知が届く
+	micropostService := services.NewMicropostService(testutils.TestDB)
+	assert.NoError(t, micropostService.Create(&models.Micropost{Title: "Not for you", UserID: stranger.ID}))
+	post := models.Micropost{Title: "Hello followers", UserID: followed.ID}
+	assert.NoError(t, micropostService.Create(&post))
+	mine := models.Micropost{Title: "Mine", UserID: user.ID}
+	assert.NoError(t, micropostService.Create(&mine))
+	_, err = services.NewLikeService(testutils.TestDB).Like(followed.ID, mine.ID)
+	assert.NoError(t, err)
+
+	events := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "event:") || strings.HasPrefix(line, "data:") {
+				events <- line
+			}
+		}
+		close(events)
+	}()
+
+	var received []string
+	timeout := time.After(2 * time.Second)
+	for len(received) < 6 {
+		select {
+		case line := <-events:
+			received = append(received, line)
+		case <-timeout:
+			t.Fatalf("timed out, received %v", received)
+		}
+	}
+
+	assert.Equal(t, "event:micropost", received[0])
+	assert.Contains(t, received[1], "Hello followers")
+	assert.Equal(t, "event:micropost", received[2])
+	assert.Contains(t, received[3], "Mine")
+	assert.Equal(t, "event:notification", received[4])
+	assert.Contains(t, received[5], `"type":"like"`)
+}
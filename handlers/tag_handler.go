@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	tagService       *services.TagService
+	micropostService *services.MicropostService
+}
+
+func NewTagHandler(tagService *services.TagService, micropostService *services.MicropostService) *TagHandler {
+	return &TagHandler{tagService: tagService, micropostService: micropostService}
+}
+
+// GetTagMicroposts godoc
+// @Summary      List microposts by hashtag
+// @Description  List microposts tagged with the given hashtag, newest first. The name is matched after normalization (leading '#', case and full-width forms are ignored).
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name      path      string  true   "Hashtag (without #)"
+// @Param        page      query     int     false  "Page number"
+// @Param        per_page  query     int     false  "Results per page (max 100)"
+// @Success      200  {object}  models.MicropostListResponse
+// @Failure      404  {object}  map[string]string
+// @Router       /tags/{name}/microposts [get]
+func (h *TagHandler) GetTagMicroposts(c *gin.Context) {
+	name := models.NormalizeTagName(c.Param("name"))
+	if name == "" {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	viewerID, _ := currentUserID(c)
+	microposts, total, err := h.micropostService.GetByTag(name, viewerID, page)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchMicroposts)
+		return
+	}
+
+	response := models.MicropostListResponse{Microposts: make([]models.MicropostResponse, 0, len(microposts)), PageMeta: page.Meta(total)}
+	for _, micropost := range microposts {
+		response.Microposts = append(response.Microposts, micropost.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTrendingTags godoc
+// @Summary      List trending hashtags
+// @Description  Hashtags used in microposts created within the sliding window, ordered by distinct authors and then by post count
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        window  query     string  false  "Sliding window as a Go duration (default 24h, max 168h)"
+// @Param        limit   query     int     false  "Number of tags (default 10, max 50)"
+// @Success      200  {object}  models.TrendingTagsResponse
+// @Failure      400  {object}  map[string]string
+// @Router       /tags/trending [get]
+func (h *TagHandler) GetTrendingTags(c *gin.Context) {
+	var query models.TrendingTagQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	window, err := query.Duration()
+	if errors.Is(err, models.ErrInvalidTrendingWindow) {
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrInvalidTrendingWindow, models.MaxTrendingWindow.String())
+		return
+	}
+
+	tags, err := h.tagService.Trending(window, query.Count())
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchTags)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TrendingTagsResponse{Window: window.String(), Tags: tags})
+}
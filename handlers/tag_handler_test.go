@@ -0,0 +1,127 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTagMicroposts(t *testing.T) {
+	r, tagHandler := testutils.SetupTagHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.GET("/tags/:name/microposts", tagHandler.GetTagMicroposts)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+
+	micropostService := services.NewMicropostService(testutils.TestDB)
+	for _, body := range []string{"#日本語 の練習", "#Go と #日本語", "タグなし"} {
+		assert.NoError(t, micropostService.Create(&models.Micropost{Title: "Tagged", Body: body, UserID: user.ID}))
+	}
+
+	tests := []struct {
+		name          string
+		tag           string
+		expectedTotal int64
+	}{
+		{name: "Japanese Tag", tag: "日本語", expectedTotal: 2},
+		{name: "Normalized Name", tag: "ＧＯ", expectedTotal: 1},
+		{name: "Unknown Tag", tag: "unknown", expectedTotal: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/tags/"+url.PathEscape(tt.tag)+"/microposts", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response models.MicropostListResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedTotal, response.Total)
+			for _, micropost := range response.Microposts {
+				assert.Contains(t, micropost.Hashtags, models.NormalizeTagName(tt.tag))
+			}
+		})
+	}
+}
+
+func TestGetTrendingTags(t *testing.T) {
+	r, tagHandler := testutils.SetupTagHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.GET("/tags/trending", tagHandler.GetTrendingTags)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+
+	micropostService := services.NewMicropostService(testutils.TestDB)
+	for _, body := range []string{"#gin #gorm", "#gin"} {
+		assert.NoError(t, micropostService.Create(&models.Micropost{Title: "Trending", Body: body, UserID: user.ID}))
+	}
+
+	// 非表示にされた投稿・自動モデレーションで隠した投稿と利用停止中のユーザーの投稿は数えない
+	spammer := models.User{Email: "spammer@example.com", Handle: "spammer", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&spammer).Error)
+	moderated := []models.Micropost{
+		{Title: "Spam", Body: "#hidden", UserID: user.ID},
+		{Title: "Spam", Body: "#shadow", UserID: user.ID},
+		{Title: "Spam", Body: "#suspended #gorm", UserID: spammer.ID},
+	}
+	for i := range moderated {
+		assert.NoError(t, micropostService.Create(&moderated[i]))
+	}
+	assert.NoError(t, testutils.TestDB.Model(&moderated[0]).Update("hidden_at", time.Now()).Error)
+	assert.NoError(t, testutils.TestDB.Model(&moderated[1]).Update("shadow_hidden", true).Error)
+	assert.NoError(t, testutils.TestDB.Model(&spammer).Update("suspended_at", time.Now()).Error)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedTags   []string
+	}{
+		{name: "Default Window", query: "", expectedStatus: http.StatusOK, expectedTags: []string{"gin", "gorm"}},
+		{name: "Limit", query: "?limit=1", expectedStatus: http.StatusOK, expectedTags: []string{"gin"}},
+		{name: "Invalid Window", query: "?window=1y", expectedStatus: http.StatusBadRequest},
+		{name: "Window Too Long", query: "?window=720h", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/tags/trending"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.TrendingTagsResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				names := make([]string, 0, len(response.Tags))
+				for _, tag := range response.Tags {
+					names = append(names, tag.Name)
+				}
+				assert.Equal(t, tt.expectedTags, names)
+			}
+		})
+	}
+}
@@ -1,15 +1,18 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"os"
 	"path/filepath"
 
+	"go-gin-gorm-minimum/i18n"
 	"go-gin-gorm-minimum/models"
 	"go-gin-gorm-minimum/services"
 	"go-gin-gorm-minimum/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type UserHandler struct {
@@ -32,7 +35,7 @@ func NewUserHandler(userService *services.UserService) *UserHandler {
 func (h *UserHandler) GetUsers(c *gin.Context) {
 	users, err := h.userService.FindAll()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchUsers)
 		return
 	}
 
@@ -45,21 +48,161 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 
 // GetUser godoc
 // @Summary      Get user by ID
-// @Description  get user by ID
+// @Description  get user profile by ID, including post and follower counts
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "User ID"
-// @Success      200  {object}  models.UserResponse
+// @Success      200  {object}  models.ProfileResponse
 // @Failure      404  {object}  map[string]string
 // @Router       /users/{id} [get]
 func (h *UserHandler) GetUser(c *gin.Context) {
-	user, err := h.userService.FindByID(c.Param("id"))
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	viewerID, _ := currentUserID(c)
+	user, err := h.userService.Profile(id, viewerID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Record not found!"})
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, user.ToProfileResponse())
+}
+
+// GetUserByHandle godoc
+// @Summary      Get user by handle
+// @Description  get user profile by handle (case-insensitive, a leading @ is ignored), including post and follower counts
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        handle  path      string  true  "User handle"
+// @Success      200  {object}  models.ProfileResponse
+// @Failure      404  {object}  map[string]string
+// @Router       /users/by-handle/{handle} [get]
+func (h *UserHandler) GetUserByHandle(c *gin.Context) {
+	viewerID, _ := currentUserID(c)
+	user, err := h.userService.ProfileByHandle(c.Param("handle"), viewerID)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrRecordNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, user.ToProfileResponse())
+}
+
+// UpdateProfile godoc
+// @Summary      Update current user's profile
+// @Description  Partially update the handle, display name, bio, location and website. Omitted fields are left unchanged; an empty string clears a field (except the handle).
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        profile body models.ProfileUpdateRequest true "Profile fields to update"
+// @Success      200  {object}  models.ProfileResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /users/me [patch]
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ProfileUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrInvalidWebsite)
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(userID, req)
+	switch {
+	case errors.Is(err, services.ErrHandleTaken):
+		utils.ErrorJSON(c, http.StatusConflict, i18n.ErrHandleAlreadyExists)
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrUserNotFound)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToUpdateUser)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToProfileResponse())
+}
+
+// DeleteUser godoc
+// @Summary      Delete user
+// @Description  Move a user and their microposts to the trash (admin only). The user can no longer sign in or post, and their content disappears from every listing. The account can be restored until it is purged after the retention period.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "User ID"
+// @Success      204
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /admin/users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := h.userService.Delete(id)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrUserNotFound)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToDeleteUser)
 		return
 	}
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreUser godoc
+// @Summary      Restore user
+// @Description  Restore a trashed user together with the microposts deleted with them (admin only). Accounts past the retention period cannot be restored.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  models.UserResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      410  {object}  map[string]string
+// @Router       /admin/users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.Restore(id)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrUserNotFound)
+		return
+	case errors.Is(err, services.ErrRestoreExpired):
+		utils.ErrorJSON(c, http.StatusGone, i18n.ErrRestoreExpired)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToRestore)
+		return
+	}
+
 	c.JSON(http.StatusOK, user.ToResponse())
 }
 
@@ -74,59 +217,104 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 // @Success      200  {object}  models.UserResponse
 // @Failure      400  {object}  map[string]string
 // @Failure      401  {object}  map[string]string
+// @Failure      413  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /users/avatar [put]
 func (h *UserHandler) UpdateAvatar(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	if userID == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		utils.ErrorJSON(c, http.StatusUnauthorized, i18n.ErrUnauthorized)
 		return
 	}
 
 	file, err := c.FormFile("avatar")
+	if limit, ok := utils.RequestTooLarge(err); ok {
+		utils.ErrorJSON(c, http.StatusRequestEntityTooLarge, i18n.ErrRequestTooLarge, limit)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrNoFileUploaded)
 		return
 	}
 
 	if !utils.IsValidImageFile(file) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type. Only images are allowed"})
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrInvalidFileType)
 		return
 	}
 
 	filename := utils.GenerateAvatarFilename(userID.(uint), file.Filename)
-	avatarPath := filepath.ToSlash(filepath.Join("uploads/avatars", filename))
-	fullPath := filepath.Join(".", "uploads", "avatars", filename)
+	avatarPath := filepath.ToSlash(filepath.Join(utils.AvatarUploadDir, filename))
+	fullPath := filepath.Join(".", filepath.FromSlash(utils.AvatarUploadDir), filename)
 
 	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create directory"})
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToCreateDirectory)
 		return
 	}
 
 	if err := c.SaveUploadedFile(file, fullPath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToSaveFile)
 		return
 	}
 
 	// Get current user to check old avatar
 	currentUser, err := h.userService.FindByID(userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrUserNotFound)
 		return
 	}
 
 	// Remove old avatar if it's not the default
-	if currentUser.AvatarPath != "/avatars/default.png" {
-		oldPath := filepath.Join(".", currentUser.AvatarPath)
-		os.Remove(oldPath)
-	}
+	utils.RemoveAvatarFile(currentUser.AvatarPath)
 
 	// Update user avatar
 	updatedUser, err := h.userService.UpdateAvatar(userID.(uint), "/"+avatarPath)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToUpdateUser)
 		return
 	}
 
 	c.JSON(http.StatusOK, updatedUser.ToResponse())
 }
+
+// UpdateLocale godoc
+// @Summary      Update preferred locale
+// @Description  Set the preferred language for API messages (empty string falls back to Accept-Language). Returns a refreshed token carrying the preference.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        locale body models.LocaleRequest true "Preferred locale"
+// @Success      200  {object}  models.LoginResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Router       /users/locale [put]
+func (h *UserHandler) UpdateLocale(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	if userID == nil {
+		utils.ErrorJSON(c, http.StatusUnauthorized, i18n.ErrUnauthorized)
+		return
+	}
+
+	var req models.LocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	updatedUser, err := h.userService.UpdateLocale(userID.(uint), req.Locale)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToUpdateUser)
+		return
+	}
+
+	token, err := utils.GenerateJWTToken(updatedUser)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToUpdateUser)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LoginResponse{
+		Token:        token,
+		UserResponse: updatedUser.ToResponse(),
+	})
+}
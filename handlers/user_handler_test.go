@@ -24,6 +24,9 @@ import (
 func init() {
 	os.Setenv("ENV", "test")
 	testutils.TestDB = infra.SetupDB()
+	if err := infra.Migrate(testutils.TestDB); err != nil {
+		panic(err)
+	}
 }
 
 func setupUserTest() (*gin.Engine, *handlers.UserHandler) {
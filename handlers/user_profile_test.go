@@ -0,0 +1,138 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateProfile(t *testing.T) {
+	r, userHandler := testutils.SetupUserHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.PATCH("/users/me", userHandler.UpdateProfile)
+	auth.GET("/users/by-handle/:handle", userHandler.GetUserByHandle)
+
+	_, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	taken := models.User{Email: "taken@example.com", Handle: "taken", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&taken).Error)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		checkResponse  func(*testing.T, models.ProfileResponse)
+	}{
+		{
+			name:           "Update Profile",
+			body:           `{"handle":"NewHandle","display_name":"  山田 太郎 ","bio":"Go が好き\r\nよろしく","website":"https://example.com"}`,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response models.ProfileResponse) {
+				assert.Equal(t, "NewHandle", response.Handle)
+				assert.Equal(t, "山田 太郎", response.DisplayName)
+				assert.Equal(t, "Go が好き\nよろしく", response.Bio)
+				assert.Equal(t, "https://example.com", response.Website)
+			},
+		},
+		{
+			name:           "Omitted Fields Are Unchanged",
+			body:           `{"location":"東京"}`,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response models.ProfileResponse) {
+				assert.Equal(t, "NewHandle", response.Handle)
+				assert.Equal(t, "東京", response.Location)
+			},
+		},
+		{name: "Handle Taken (Case Insensitive)", body: `{"handle":"TAKEN"}`, expectedStatus: http.StatusConflict},
+		{name: "Invalid Handle", body: `{"handle":"a b"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Invalid Website", body: `{"website":"javascript:alert(1)"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Bio Too Long", body: fmt.Sprintf(`{"bio":%q}`, string(bytes.Repeat([]byte("a"), 161))), expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/users/me", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.checkResponse != nil {
+				var response models.ProfileResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				tt.checkResponse(t, response)
+			}
+		})
+	}
+
+	// 新しいハンドルで引ける
+	req := httptest.NewRequest(http.MethodGet, "/users/by-handle/@newhandle", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestFollowUser(t *testing.T) {
+	r, followHandler := testutils.SetupFollowHandler()
+
+	// ルートの設定
+	auth := r.Group("/")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.POST("/users/:id/follow", followHandler.FollowUser)
+	auth.DELETE("/users/:id/follow", followHandler.UnfollowUser)
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	target := models.User{Email: "target@example.com", Handle: "target", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&target).Error)
+	assert.NoError(t, testutils.TestDB.Create(&models.Micropost{Title: "Hello", UserID: target.ID}).Error)
+
+	tests := []struct {
+		name              string
+		method            string
+		id                uint
+		expectedStatus    int
+		expectedFollowers int64
+		expectedFollowing bool
+	}{
+		{name: "Follow", method: http.MethodPost, id: target.ID, expectedStatus: http.StatusOK, expectedFollowers: 1, expectedFollowing: true},
+		{name: "Follow Twice Is Idempotent", method: http.MethodPost, id: target.ID, expectedStatus: http.StatusOK, expectedFollowers: 1, expectedFollowing: true},
+		{name: "Unfollow", method: http.MethodDelete, id: target.ID, expectedStatus: http.StatusOK, expectedFollowers: 0, expectedFollowing: false},
+		{name: "Follow Self", method: http.MethodPost, id: user.ID, expectedStatus: http.StatusBadRequest},
+		{name: "Unknown User", method: http.MethodPost, id: 999999, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, fmt.Sprintf("/users/%d/follow", tt.id), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.ProfileResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, int64(1), response.PostCount)
+				assert.Equal(t, tt.expectedFollowers, response.FollowerCount)
+				assert.Equal(t, tt.expectedFollowing, response.FollowedByMe)
+			}
+		})
+	}
+}
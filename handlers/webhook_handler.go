@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// CreateWebhook godoc
+// @Summary      Register webhook
+// @Description  Register a URL that receives the selected events as signed POST requests. Each request carries X-Webhook-Event, X-Webhook-Delivery, X-Webhook-Timestamp and X-Webhook-Signature, where the signature is "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)). A webhook receives events the current user takes part in (their own posts, likes on their posts, follows to or from them); admins can set global to receive every user's events. The secret is generated when omitted and is only returned in this response. Failed deliveries are retried with exponential backoff.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        webhook  body      models.WebhookRequest  true  "Webhook"
+// @Success      201  {object}  models.WebhookResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrInvalidWebhookURL)
+		return
+	}
+
+	webhook, err := h.webhookService.Create(userID, isAdmin(c), req)
+	switch {
+	case errors.Is(err, services.ErrForbidden):
+		utils.ErrorJSON(c, http.StatusForbidden, i18n.ErrForbidden)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToCreateWebhook)
+		return
+	}
+
+	response := webhook.ToResponse()
+	response.Secret = webhook.Secret
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetWebhooks godoc
+// @Summary      List webhooks
+// @Description  List the current user's webhooks, newest first
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.WebhookListResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Router       /webhooks [get]
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	webhooks, total, err := h.webhookService.List(userID, page)
+	if err != nil {
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchWebhooks)
+		return
+	}
+
+	response := models.WebhookListResponse{Webhooks: make([]models.WebhookResponse, 0, len(webhooks)), PageMeta: page.Meta(total)}
+	for _, webhook := range webhooks {
+		response.Webhooks = append(response.Webhooks, webhook.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetWebhook godoc
+// @Summary      Get webhook
+// @Description  Get one of the current user's webhooks
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Webhook ID"
+// @Success      200  {object}  models.WebhookResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookService.Get(id, userID)
+	if !h.respondWebhookError(c, err, i18n.ErrFailedToFetchWebhooks) {
+		return
+	}
+	c.JSON(http.StatusOK, webhook.ToResponse())
+}
+
+// UpdateWebhook godoc
+// @Summary      Update webhook
+// @Description  Partially update a webhook's URL, event types or active flag. Omitted fields are left unchanged; deliveries to an inactive webhook are dropped.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                          true  "Webhook ID"
+// @Param        webhook  body      models.WebhookUpdateRequest  true  "Fields to update"
+// @Success      200  {object}  models.WebhookResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /webhooks/{id} [patch]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.WebhookUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ErrorJSON(c, http.StatusBadRequest, i18n.ErrInvalidWebhookURL)
+		return
+	}
+
+	webhook, err := h.webhookService.Update(id, userID, req)
+	if !h.respondWebhookError(c, err, i18n.ErrFailedToUpdateWebhook) {
+		return
+	}
+	c.JSON(http.StatusOK, webhook.ToResponse())
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete webhook
+// @Description  Delete one of the current user's webhooks together with its delivery log
+// @Tags         webhooks
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Webhook ID"
+// @Success      204
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := h.webhookService.Delete(id, userID)
+	if !h.respondWebhookError(c, err, i18n.ErrFailedToDeleteWebhook) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries godoc
+// @Summary      Webhook delivery log
+// @Description  List a webhook's deliveries, newest first, with the payload sent, the number of attempts and the last response code, body (truncated) or error
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int  true   "Webhook ID"
+// @Param        page      query     int  false  "Page number"
+// @Param        per_page  query     int  false  "Results per page (max 100)"
+// @Success      200  {object}  models.WebhookDeliveryListResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetWebhookDeliveries(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.BindingErrorJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	deliveries, total, err := h.webhookService.Deliveries(id, userID, page)
+	if !h.respondWebhookError(c, err, i18n.ErrFailedToFetchWebhooks) {
+		return
+	}
+
+	response := models.WebhookDeliveryListResponse{Deliveries: make([]models.WebhookDeliveryResponse, 0, len(deliveries)), PageMeta: page.Meta(total)}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, delivery.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RedeliverWebhook godoc
+// @Summary      Redeliver webhook
+// @Description  Send a delivery again with the same payload and a fresh timestamp and signature. Receivers can use X-Webhook-Delivery or the event id in the payload to drop duplicates.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id           path      int  true  "Webhook ID"
+// @Param        delivery_id  path      int  true  "Delivery ID"
+// @Success      202  {object}  models.WebhookDeliveryResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := idParam(c, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(id, deliveryID, userID)
+	switch {
+	case errors.Is(err, services.ErrWebhookDeliveryPending):
+		utils.ErrorJSON(c, http.StatusConflict, i18n.ErrWebhookDeliveryPending)
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrWebhookDeliveryNotFound)
+		return
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToRedeliverWebhook)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery.ToResponse())
+}
+
+// respondWebhookError は Webhook が見つからなければ 404、その他のエラーは 500 を返す（エラーがなければ true）
+func (h *WebhookHandler) respondWebhookError(c *gin.Context, err error, failure string) bool {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorJSON(c, http.StatusNotFound, i18n.ErrWebhookNotFound)
+		return false
+	case err != nil:
+		utils.ErrorJSON(c, http.StatusInternalServerError, failure)
+		return false
+	}
+	return true
+}
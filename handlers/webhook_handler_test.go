@@ -0,0 +1,229 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/services"
+	"go-gin-gorm-minimum/testutils"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// webhookReceiver は受け取ったリクエストを記録し、status の応答を返すテスト用の受信側
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.requests = append(rcv.requests, r)
+	rcv.bodies = append(rcv.bodies, body)
+	w.WriteHeader(rcv.status)
+	fmt.Fprint(w, http.StatusText(rcv.status))
+}
+
+func (rcv *webhookReceiver) received() int {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return len(rcv.requests)
+}
+
+func TestWebhooks(t *testing.T) {
+	r, handler, dispatcher, queue := testutils.SetupWebhookHandler()
+	services.WebhookAllowPrivateNetworks = true
+	defer func() { services.WebhookAllowPrivateNetworks = false }()
+
+	// ルートの設定
+	auth := r.Group("/webhooks")
+	auth.Use(middlewares.AuthMiddleware())
+	auth.POST("", handler.CreateWebhook)
+	auth.GET("", handler.GetWebhooks)
+	auth.PATCH("/:id", handler.UpdateWebhook)
+	auth.GET("/:id/deliveries", handler.GetWebhookDeliveries)
+	auth.POST("/:id/deliveries/:delivery_id/redeliver", handler.RedeliverWebhook)
+
+	receiver := &webhookReceiver{status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	user, token, err := testutils.CreateTestUser()
+	assert.NoError(t, err)
+	other := models.User{Email: "other@example.com", Handle: "other", Password: "password123"}
+	assert.NoError(t, testutils.TestDB.Create(&other).Error)
+	micropostService := services.NewMicropostService(testutils.TestDB)
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reader io.Reader
+		if body != nil {
+			data, _ := json.Marshal(body)
+			reader = bytes.NewReader(data)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	// イベントを配信して Webhook への送信ジョブを実行する
+	deliver := func() {
+		_, err := dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		_, err = queue.Drain(context.Background())
+		assert.NoError(t, err)
+	}
+	deliveries := func(webhookID uint) []models.WebhookDeliveryResponse {
+		w := request(http.MethodGet, fmt.Sprintf("/webhooks/%d/deliveries", webhookID), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var list models.WebhookDeliveryListResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		return list.Deliveries
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			body           models.WebhookRequest
+			expectedStatus int
+		}{
+			{name: "Unknown Event", body: models.WebhookRequest{URL: server.URL, EventTypes: []string{"user.unknown"}}, expectedStatus: http.StatusBadRequest},
+			{name: "Not HTTP", body: models.WebhookRequest{URL: "ftp://example.com/hook", EventTypes: []string{models.EventMicropostCreated}}, expectedStatus: http.StatusBadRequest},
+			{name: "No Events", body: models.WebhookRequest{URL: server.URL}, expectedStatus: http.StatusBadRequest},
+			{name: "Global Requires Admin", body: models.WebhookRequest{URL: server.URL, EventTypes: []string{models.EventUserSignedUp}, Global: true}, expectedStatus: http.StatusForbidden},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expectedStatus, request(http.MethodPost, "/webhooks", tt.body).Code)
+			})
+		}
+	})
+
+	var webhook models.WebhookResponse
+	t.Run("signed delivery", func(t *testing.T) {
+		w := request(http.MethodPost, "/webhooks", models.WebhookRequest{URL: server.URL, EventTypes: []string{models.EventMicropostCreated}})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &webhook))
+		assert.Len(t, webhook.Secret, 64)
+
+		// 一覧では Secret を返さない
+		w = request(http.MethodGet, "/webhooks", nil)
+		assert.NotContains(t, w.Body.String(), webhook.Secret)
+
+		post := models.Micropost{Title: "Hello", Body: "webhook", UserID: user.ID}
+		assert.NoError(t, micropostService.Create(&post))
+		// 他のユーザーの投稿は届かない
+		assert.NoError(t, micropostService.Create(&models.Micropost{Title: "Hello", Body: "other", UserID: other.ID}))
+		deliver()
+
+		assert.Equal(t, 1, receiver.received())
+		req, body := receiver.requests[0], receiver.bodies[0]
+		assert.Equal(t, models.EventMicropostCreated, req.Header.Get(services.WebhookEventHeader))
+		timestamp := req.Header.Get(services.WebhookTimestampHeader)
+		assert.True(t, utils.VerifyWebhookSignature(webhook.Secret, timestamp, body, req.Header.Get(services.WebhookSignatureHeader)))
+		assert.False(t, utils.VerifyWebhookSignature("wrong-secret", timestamp, body, req.Header.Get(services.WebhookSignatureHeader)))
+
+		var payload struct {
+			Type string                  `json:"type"`
+			Data models.MicropostCreated `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, models.EventMicropostCreated, payload.Type)
+		assert.Equal(t, post.ID, payload.Data.MicropostID)
+
+		log := deliveries(webhook.ID)
+		assert.Len(t, log, 1)
+		assert.Equal(t, models.WebhookDeliverySucceeded, log[0].Status)
+		assert.Equal(t, http.StatusOK, log[0].ResponseStatus)
+		assert.Equal(t, 1, log[0].Attempts)
+	})
+
+	t.Run("retries until the receiver recovers", func(t *testing.T) {
+		receiver.status = http.StatusServiceUnavailable
+		assert.NoError(t, micropostService.Create(&models.Micropost{Title: "Hello", Body: "retry", UserID: user.ID}))
+		deliver()
+
+		log := deliveries(webhook.ID)
+		assert.Equal(t, models.WebhookDeliveryPending, log[0].Status)
+		assert.Equal(t, http.StatusServiceUnavailable, log[0].ResponseStatus)
+		assert.Contains(t, log[0].Error, "503")
+
+		// バックオフを待たずに再試行する
+		receiver.status = http.StatusNoContent
+		assert.NoError(t, testutils.TestDB.Model(&models.Job{}).Where("status = ?", models.JobStatusPending).Update("run_at", time.Now().Add(-time.Second)).Error)
+		deliver()
+
+		log = deliveries(webhook.ID)
+		assert.Equal(t, models.WebhookDeliverySucceeded, log[0].Status)
+		assert.Equal(t, http.StatusNoContent, log[0].ResponseStatus)
+		assert.Equal(t, 2, log[0].Attempts)
+		assert.Empty(t, log[0].Error)
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		before := receiver.received()
+		delivery := deliveries(webhook.ID)[1]
+		path := fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", webhook.ID, delivery.ID)
+		assert.Equal(t, http.StatusAccepted, request(http.MethodPost, path, nil).Code)
+		assert.Equal(t, http.StatusConflict, request(http.MethodPost, path, nil).Code)
+
+		deliver()
+		assert.Equal(t, before+1, receiver.received())
+		assert.Equal(t, delivery.Payload, string(receiver.bodies[before]))
+
+		assert.Equal(t, http.StatusNotFound, request(http.MethodPost, fmt.Sprintf("/webhooks/%d/deliveries/999999/redeliver", webhook.ID), nil).Code)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		maxAttempts := models.WebhookMaxAttempts
+		models.WebhookMaxAttempts = 2
+		defer func() { models.WebhookMaxAttempts = maxAttempts }()
+
+		receiver.status = http.StatusInternalServerError
+		assert.NoError(t, micropostService.Create(&models.Micropost{Title: "Hello", Body: "failed", UserID: user.ID}))
+		deliver()
+		assert.NoError(t, testutils.TestDB.Model(&models.Job{}).Where("status = ?", models.JobStatusPending).Update("run_at", time.Now().Add(-time.Second)).Error)
+		deliver()
+
+		log := deliveries(webhook.ID)
+		assert.Equal(t, models.WebhookDeliveryFailed, log[0].Status)
+		assert.Equal(t, 2, log[0].Attempts)
+		receiver.status = http.StatusOK
+	})
+
+	t.Run("inactive webhook and private address", func(t *testing.T) {
+		active := false
+		w := request(http.MethodPatch, fmt.Sprintf("/webhooks/%d", webhook.ID), models.WebhookUpdateRequest{Active: &active})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		before := receiver.received()
+		assert.NoError(t, micropostService.Create(&models.Micropost{Title: "Hello", Body: "inactive", UserID: user.ID}))
+		deliver()
+		assert.Equal(t, before, receiver.received())
+
+		// ループバックアドレスへの配信は既定では拒否する
+		services.WebhookAllowPrivateNetworks = false
+		active = true
+		request(http.MethodPatch, fmt.Sprintf("/webhooks/%d", webhook.ID), models.WebhookUpdateRequest{Active: &active})
+		assert.NoError(t, micropostService.Create(&models.Micropost{Title: "Hello", Body: "blocked", UserID: user.ID}))
+		deliver()
+		assert.Equal(t, before, receiver.received())
+		assert.Contains(t, deliveries(webhook.ID)[0].Error, "not allowed")
+	})
+}
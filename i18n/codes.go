@@ -0,0 +1,97 @@
+package i18n
+
+// エラーコード（レスポンスの "code" とメッセージカタログのキーを兼ねる）
+const (
+	ErrInvalidRequest              = "invalid_request"
+	ErrInvalidJSON                 = "invalid_json"
+	ErrAuthorizationHeaderRequired = "authorization_header_required"
+	ErrInvalidTokenFormat          = "invalid_token_format"
+	ErrInvalidToken                = "invalid_token"
+	ErrInvalidTokenClaims          = "invalid_token_claims"
+	ErrUnauthorized                = "unauthorized"
+	ErrInvalidCredentials          = "invalid_credentials"
+	ErrRecordNotFound              = "record_not_found"
+	ErrUserNotFound                = "user_not_found"
+	ErrEmailAlreadyExists          = "email_already_exists"
+	ErrFailedToCreateUser          = "failed_to_create_user"
+	ErrFailedToFetchUsers          = "failed_to_fetch_users"
+	ErrFailedToUpdateUser          = "failed_to_update_user"
+	ErrFailedToCreateMicropost     = "failed_to_create_micropost"
+	ErrFailedToFetchMicroposts     = "failed_to_fetch_microposts"
+	ErrNoFileUploaded              = "no_file_uploaded"
+	ErrInvalidFileType             = "invalid_file_type"
+	ErrFailedToCreateDirectory     = "failed_to_create_directory"
+	ErrFailedToSaveFile            = "failed_to_save_file"
+	ErrRateLimited                 = "rate_limited"
+	ErrRequestTooLarge             = "request_too_large"
+	ErrMicropostTitleRequired      = "micropost_title_required"
+	ErrMicropostBodyTooLong        = "micropost_body_too_long"
+	ErrInvalidPublishAt            = "invalid_publish_at"
+	ErrSearchQueryRequired         = "search_query_required"
+	ErrFailedToUpdateLike          = "failed_to_update_like"
+	ErrFailedToFetchLikes          = "failed_to_fetch_likes"
+	ErrAlreadyReposted             = "already_reposted"
+	ErrFailedToDeleteMicropost     = "failed_to_delete_micropost"
+	ErrForbidden                   = "forbidden"
+	ErrFailedToUpdateMicropost     = "failed_to_update_micropost"
+	ErrFailedToFetchTags           = "failed_to_fetch_tags"
+	ErrInvalidTrendingWindow       = "invalid_trending_window"
+	ErrHandleAlreadyExists         = "handle_already_exists"
+	ErrInvalidWebsite              = "invalid_website"
+	ErrCannotFollowSelf            = "cannot_follow_self"
+	ErrFailedToUpdateFollow        = "failed_to_update_follow"
+	ErrFailedToFetchNotifications  = "failed_to_fetch_notifications"
+	ErrFailedToUpdateNotifications = "failed_to_update_notifications"
+	ErrInvalidParticipants         = "invalid_participants"
+	ErrConversationNotFound        = "conversation_not_found"
+	ErrMessageBodyRequired         = "message_body_required"
+	ErrMessageBodyTooLong          = "message_body_too_long"
+	ErrFailedToStartConversation   = "failed_to_start_conversation"
+	ErrFailedToFetchConversations  = "failed_to_fetch_conversations"
+	ErrFailedToSendMessage         = "failed_to_send_message"
+	ErrFailedToUpdateConversation  = "failed_to_update_conversation"
+	ErrBlocked                     = "blocked"
+	ErrCannotBlockSelf             = "cannot_block_self"
+	ErrCannotMuteSelf              = "cannot_mute_self"
+	ErrFailedToUpdateBlock         = "failed_to_update_block"
+	ErrFailedToUpdateMute          = "failed_to_update_mute"
+	ErrAdminRequired               = "admin_required"
+	ErrAccountSuspended            = "account_suspended"
+	ErrCannotReportSelf            = "cannot_report_self"
+	ErrAlreadyReported             = "already_reported"
+	ErrFailedToCreateReport        = "failed_to_create_report"
+	ErrReportNotFound              = "report_not_found"
+	ErrReportResolved              = "report_resolved"
+	ErrInvalidModerationAction     = "invalid_moderation_action"
+	ErrFailedToFetchReports        = "failed_to_fetch_reports"
+	ErrFailedToResolveReport       = "failed_to_resolve_report"
+	ErrContentRejected             = "content_rejected"
+	ErrTooManyLinks                = "too_many_links"
+	ErrDuplicateContent            = "duplicate_content"
+	ErrAccountDeleted              = "account_deleted"
+	ErrRestoreExpired              = "restore_expired"
+	ErrFailedToRestore             = "failed_to_restore"
+	ErrFailedToDeleteUser          = "failed_to_delete_user"
+	ErrIncorrectPassword           = "incorrect_password"
+	ErrExportInProgress            = "export_in_progress"
+	ErrExportNotReady              = "export_not_ready"
+	ErrExportExpired               = "export_expired"
+	ErrExportNotFound              = "export_not_found"
+	ErrFailedToCreateExport        = "failed_to_create_export"
+	ErrFailedToFetchExports        = "failed_to_fetch_exports"
+	ErrJobNotFound                 = "job_not_found"
+	ErrJobNotRetryable             = "job_not_retryable"
+	ErrFailedToFetchJobs           = "failed_to_fetch_jobs"
+	ErrFailedToRetryJob            = "failed_to_retry_job"
+	ErrWebhookNotFound             = "webhook_not_found"
+	ErrWebhookDeliveryNotFound     = "webhook_delivery_not_found"
+	ErrInvalidWebhookURL           = "invalid_webhook_url"
+	ErrWebhookDeliveryPending      = "webhook_delivery_pending"
+	ErrFailedToCreateWebhook       = "failed_to_create_webhook"
+	ErrFailedToFetchWebhooks       = "failed_to_fetch_webhooks"
+	ErrFailedToUpdateWebhook       = "failed_to_update_webhook"
+	ErrFailedToDeleteWebhook       = "failed_to_delete_webhook"
+	ErrFailedToRedeliverWebhook    = "failed_to_redeliver_webhook"
+	ErrFailedToPublishMicropost    = "failed_to_publish_micropost"
+	ErrFailedToFetchRevisions      = "failed_to_fetch_revisions"
+)
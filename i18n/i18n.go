@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// サポートするロケール
+const (
+	LocaleJA = "ja"
+	LocaleEN = "en"
+
+	// DefaultLocale は判定できなかった場合に使うロケール
+	DefaultLocale = LocaleJA
+
+	// ContextKey は gin.Context にロケールを保存するキー
+	ContextKey = "locale"
+)
+
+var catalogs = map[string]map[string]string{
+	LocaleJA: messagesJA,
+	LocaleEN: messagesEN,
+}
+
+// IsSupported reports whether the locale has a message catalog
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Normalize reduces a language tag such as "en-US" to a supported locale, or "" if unsupported
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if IsSupported(tag) {
+		return tag
+	}
+	return ""
+}
+
+// ParseAcceptLanguage picks the best supported locale from an Accept-Language header
+func ParseAcceptLanguage(header string) string {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale := Normalize(fields[0])
+		if locale == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{locale: locale, q: q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].locale
+}
+
+// FromContext returns the locale resolved for the request.
+// The value set by the middlewares wins; otherwise Accept-Language is parsed directly.
+func FromContext(c *gin.Context) string {
+	if locale := c.GetString(ContextKey); IsSupported(locale) {
+		return locale
+	}
+	if locale := ParseAcceptLanguage(c.GetHeader("Accept-Language")); locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// T translates a message key into the given locale, formatting args with fmt.Sprintf.
+// Missing keys fall back to the default locale and finally to the key itself.
+func T(locale, key string, args ...interface{}) string {
+	msg, ok := catalogs[locale][key]
+	if !ok {
+		msg, ok = catalogs[DefaultLocale][key]
+	}
+	if !ok {
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
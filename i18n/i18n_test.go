@@ -0,0 +1,36 @@
+package i18n_test
+
+import (
+	"testing"
+
+	"go-gin-gorm-minimum/i18n"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "Empty", header: "", expected: ""},
+		{name: "Region Subtag", header: "en-US", expected: "en"},
+		{name: "Quality Order", header: "fr;q=1.0, en;q=0.5, ja;q=0.8", expected: "ja"},
+		{name: "Unsupported Only", header: "fr, de", expected: ""},
+		{name: "Zero Quality", header: "en;q=0, ja;q=0.1", expected: "ja"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, i18n.ParseAcceptLanguage(tt.header))
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Invalid email or password", i18n.T(i18n.LocaleEN, i18n.ErrInvalidCredentials))
+	assert.Equal(t, "メールアドレスまたはパスワードが正しくありません", i18n.T(i18n.LocaleJA, i18n.ErrInvalidCredentials))
+	assert.Equal(t, "password must be at least 6 characters", i18n.T(i18n.LocaleEN, "validation.min", "password", "6"))
+	assert.Equal(t, "unknown_key", i18n.T(i18n.LocaleEN, "unknown_key"))
+}
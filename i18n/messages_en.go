@@ -0,0 +1,122 @@
+package i18n
+
+// messagesEN is the English message catalog
+var messagesEN = map[string]string{
+	ErrInvalidRequest:              "Invalid request",
+	ErrInvalidJSON:                 "Malformed JSON",
+	ErrAuthorizationHeaderRequired: "Authorization header required",
+	ErrInvalidTokenFormat:          "Invalid token format",
+	ErrInvalidToken:                "Invalid token",
+	ErrInvalidTokenClaims:          "Invalid token claims",
+	ErrUnauthorized:                "Unauthorized",
+	ErrInvalidCredentials:          "Invalid email or password",
+	ErrRecordNotFound:              "Record not found!",
+	ErrUserNotFound:                "User not found",
+	ErrEmailAlreadyExists:          "Email already exists",
+	ErrFailedToCreateUser:          "Failed to create user",
+	ErrFailedToFetchUsers:          "Failed to fetch users",
+	ErrFailedToUpdateUser:          "Failed to update user",
+	ErrFailedToCreateMicropost:     "Failed to create micropost",
+	ErrFailedToFetchMicroposts:     "Failed to fetch microposts",
+	ErrNoFileUploaded:              "No file uploaded",
+	ErrInvalidFileType:             "Invalid file type. Only images are allowed",
+	ErrFailedToCreateDirectory:     "Failed to create directory",
+	ErrFailedToSaveFile:            "Failed to save file",
+	ErrRateLimited:                 "Too many requests. Please try again later",
+	ErrRequestTooLarge:             "Request body too large (limit %d bytes)",
+	ErrMicropostTitleRequired:      "title is required",
+	ErrMicropostBodyTooLong:        "body must be at most %d characters",
+	ErrInvalidPublishAt:            "publish_at must be in the future",
+	ErrSearchQueryRequired:         "search query is required",
+	ErrFailedToUpdateLike:          "Failed to update like",
+	ErrFailedToFetchLikes:          "Failed to fetch likes",
+	ErrAlreadyReposted:             "You have already reposted this micropost",
+	ErrFailedToDeleteMicropost:     "Failed to delete micropost",
+	ErrForbidden:                   "You are not allowed to perform this action",
+	ErrFailedToUpdateMicropost:     "Failed to update micropost",
+	ErrFailedToFetchTags:           "Failed to fetch tags",
+	ErrInvalidTrendingWindow:       "window must be a positive duration up to %s (e.g. 1h, 24h)",
+	ErrHandleAlreadyExists:         "Handle already taken",
+	ErrInvalidWebsite:              "website must be an http or https URL",
+	ErrCannotFollowSelf:            "You cannot follow yourself",
+	ErrFailedToUpdateFollow:        "Failed to update follow",
+	ErrFailedToFetchNotifications:  "Failed to fetch notifications",
+	ErrFailedToUpdateNotifications: "Failed to update notifications",
+	ErrInvalidParticipants:         "Choose between 1 and %d other existing users",
+	ErrConversationNotFound:        "Conversation not found",
+	ErrMessageBodyRequired:         "message body is required",
+	ErrMessageBodyTooLong:          "message must be at most %d characters",
+	ErrFailedToStartConversation:   "Failed to start conversation",
+	ErrFailedToFetchConversations:  "Failed to fetch conversations",
+	ErrFailedToSendMessage:         "Failed to send message",
+	ErrFailedToUpdateConversation:  "Failed to update conversation",
+	ErrBlocked:                     "You cannot interact with this user",
+	ErrCannotBlockSelf:             "You cannot block yourself",
+	ErrCannotMuteSelf:              "You cannot mute yourself",
+	ErrFailedToUpdateBlock:         "Failed to update block",
+	ErrFailedToUpdateMute:          "Failed to update mute",
+	ErrAdminRequired:               "Administrator privileges are required",
+	ErrAccountSuspended:            "This account is suspended",
+	ErrCannotReportSelf:            "You cannot report yourself or your own posts",
+	ErrAlreadyReported:             "You have already reported this",
+	ErrFailedToCreateReport:        "Failed to create report",
+	ErrReportNotFound:              "Report not found",
+	ErrReportResolved:              "This report has already been resolved",
+	ErrInvalidModerationAction:     "This action cannot be applied to the report",
+	ErrFailedToFetchReports:        "Failed to fetch reports",
+	ErrFailedToResolveReport:       "Failed to resolve report",
+	ErrContentRejected:             "This post violates the community guidelines",
+	ErrTooManyLinks:                "This post contains too many links",
+	ErrDuplicateContent:            "You have already posted the same content recently",
+	ErrAccountDeleted:              "This account has been deleted",
+	ErrRestoreExpired:              "The restore period has expired",
+	ErrFailedToRestore:             "Failed to restore",
+	ErrFailedToDeleteUser:          "Failed to delete user",
+	ErrIncorrectPassword:           "The password is incorrect",
+	ErrExportInProgress:            "A data export is already in progress",
+	ErrExportNotReady:              "The data export is not ready yet",
+	ErrExportExpired:               "The download link has expired. Please request a new export",
+	ErrExportNotFound:              "Data export not found",
+	ErrFailedToCreateExport:        "Failed to create data export",
+	ErrFailedToFetchExports:        "Failed to fetch data exports",
+	ErrJobNotFound:                 "Job not found",
+	ErrJobNotRetryable:             "Only dead jobs can be retried",
+	ErrFailedToFetchJobs:           "Failed to fetch jobs",
+	ErrFailedToRetryJob:            "Failed to retry job",
+	ErrWebhookNotFound:             "Webhook not found",
+	ErrWebhookDeliveryNotFound:     "Webhook delivery not found",
+	ErrInvalidWebhookURL:           "url must be an http or https URL",
+	ErrWebhookDeliveryPending:      "This delivery is still pending",
+	ErrFailedToCreateWebhook:       "Failed to create webhook",
+	ErrFailedToFetchWebhooks:       "Failed to fetch webhooks",
+	ErrFailedToUpdateWebhook:       "Failed to update webhook",
+	ErrFailedToDeleteWebhook:       "Failed to delete webhook",
+	ErrFailedToRedeliverWebhook:    "Failed to redeliver webhook",
+	ErrFailedToPublishMicropost:    "Failed to publish micropost",
+	ErrFailedToFetchRevisions:      "Failed to fetch revisions",
+
+	// Validation
+	"validation.separator": "; ",
+	"validation.default":   "%s is invalid",
+	"validation.required":  "%s is required",
+	"validation.email":     "%s must be a valid email address",
+	"validation.min":       "%s must be at least %s characters",
+	"validation.max":       "%s must be at most %s characters",
+	"validation.oneof":     "%s must be one of: %s",
+	"validation.url":       "%s must be a valid URL",
+	"validation.handle":    "%s must be 3-30 letters, digits or underscores and not only digits",
+
+	// Notifications (%s is the latest actor's name, %d the number of others)
+	"notification.like":           "%s liked your post",
+	"notification.like.others":    "%s and %d others liked your post",
+	"notification.follow":         "%s followed you",
+	"notification.follow.others":  "%s and %d others followed you",
+	"notification.reply":          "%s replied to your post",
+	"notification.reply.others":   "%s and %d others replied to your post",
+	"notification.repost":         "%s reposted your post",
+	"notification.repost.others":  "%s and %d others reposted your post",
+	"notification.quote":          "%s quoted your post",
+	"notification.quote.others":   "%s and %d others quoted your post",
+	"notification.mention":        "%s mentioned you",
+	"notification.mention.others": "%s and %d others mentioned you",
+}
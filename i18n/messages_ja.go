@@ -0,0 +1,122 @@
+package i18n
+
+// messagesJA は日本語のメッセージカタログ
+var messagesJA = map[string]string{
+	ErrInvalidRequest:              "リクエストが不正です",
+	ErrInvalidJSON:                 "JSON の形式が不正です",
+	ErrAuthorizationHeaderRequired: "Authorization ヘッダーが必要です",
+	ErrInvalidTokenFormat:          "トークンの形式が不正です",
+	ErrInvalidToken:                "トークンが無効です",
+	ErrInvalidTokenClaims:          "トークンのクレームが無効です",
+	ErrUnauthorized:                "認証されていません",
+	ErrInvalidCredentials:          "メールアドレスまたはパスワードが正しくありません",
+	ErrRecordNotFound:              "レコードが見つかりません",
+	ErrUserNotFound:                "ユーザーが見つかりません",
+	ErrEmailAlreadyExists:          "このメールアドレスは既に登録されています",
+	ErrFailedToCreateUser:          "ユーザーの作成に失敗しました",
+	ErrFailedToFetchUsers:          "ユーザーの取得に失敗しました",
+	ErrFailedToUpdateUser:          "ユーザーの更新に失敗しました",
+	ErrFailedToCreateMicropost:     "マイクロポストの作成に失敗しました",
+	ErrFailedToFetchMicroposts:     "マイクロポストの取得に失敗しました",
+	ErrNoFileUploaded:              "ファイルがアップロードされていません",
+	ErrInvalidFileType:             "ファイル形式が不正です。画像のみアップロードできます",
+	ErrFailedToCreateDirectory:     "ディレクトリの作成に失敗しました",
+	ErrFailedToSaveFile:            "ファイルの保存に失敗しました",
+	ErrRateLimited:                 "リクエストが多すぎます。しばらくしてから再度お試しください",
+	ErrRequestTooLarge:             "リクエストボディが大きすぎます（上限 %d バイト）",
+	ErrMicropostTitleRequired:      "タイトルは必須です",
+	ErrMicropostBodyTooLong:        "本文は%d文字以下で入力してください",
+	ErrInvalidPublishAt:            "公開予定の日時には未来の日時を指定してください",
+	ErrSearchQueryRequired:         "検索キーワードを入力してください",
+	ErrFailedToUpdateLike:          "いいねの更新に失敗しました",
+	ErrFailedToFetchLikes:          "いいねしたユーザーの取得に失敗しました",
+	ErrAlreadyReposted:             "この投稿は既に再投稿しています",
+	ErrFailedToDeleteMicropost:     "マイクロポストの削除に失敗しました",
+	ErrForbidden:                   "この操作を行う権限がありません",
+	ErrFailedToUpdateMicropost:     "マイクロポストの更新に失敗しました",
+	ErrFailedToFetchTags:           "タグの取得に失敗しました",
+	ErrInvalidTrendingWindow:       "集計期間は %s 以下の期間（例: 1h, 24h）で指定してください",
+	ErrHandleAlreadyExists:         "このハンドルは既に使われています",
+	ErrInvalidWebsite:              "ウェブサイトは http または https の URL で入力してください",
+	ErrCannotFollowSelf:            "自分自身はフォローできません",
+	ErrFailedToUpdateFollow:        "フォローの更新に失敗しました",
+	ErrFailedToFetchNotifications:  "通知の取得に失敗しました",
+	ErrFailedToUpdateNotifications: "通知の更新に失敗しました",
+	ErrInvalidParticipants:         "1人以上%d人以下の存在するユーザーを選んでください",
+	ErrConversationNotFound:        "会話が見つかりません",
+	ErrMessageBodyRequired:         "メッセージを入力してください",
+	ErrMessageBodyTooLong:          "メッセージは%d文字以下で入力してください",
+	ErrFailedToStartConversation:   "会話の開始に失敗しました",
+	ErrFailedToFetchConversations:  "会話の取得に失敗しました",
+	ErrFailedToSendMessage:         "メッセージの送信に失敗しました",
+	ErrFailedToUpdateConversation:  "会話の更新に失敗しました",
+	ErrBlocked:                     "このユーザーとはやり取りできません",
+	ErrCannotBlockSelf:             "自分自身はブロックできません",
+	ErrCannotMuteSelf:              "自分自身はミュートできません",
+	ErrFailedToUpdateBlock:         "ブロックの更新に失敗しました",
+	ErrFailedToUpdateMute:          "ミュートの更新に失敗しました",
+	ErrAdminRequired:               "管理者権限が必要です",
+	ErrAccountSuspended:            "このアカウントは利用停止中です",
+	ErrCannotReportSelf:            "自分自身や自分の投稿は通報できません",
+	ErrAlreadyReported:             "既に通報済みです",
+	ErrFailedToCreateReport:        "通報に失敗しました",
+	ErrReportNotFound:              "通報が見つかりません",
+	ErrReportResolved:              "この通報は対応済みです",
+	ErrInvalidModerationAction:     "この通報にはその操作を行えません",
+	ErrFailedToFetchReports:        "通報の取得に失敗しました",
+	ErrFailedToResolveReport:       "通報の対応に失敗しました",
+	ErrContentRejected:             "コミュニティガイドラインに違反する投稿はできません",
+	ErrTooManyLinks:                "投稿に含まれるリンクが多すぎます",
+	ErrDuplicateContent:            "同じ内容の投稿が最近行われています",
+	ErrAccountDeleted:              "このアカウントは削除されています",
+	ErrRestoreExpired:              "復元できる期間を過ぎています",
+	ErrFailedToRestore:             "復元に失敗しました",
+	ErrFailedToDeleteUser:          "ユーザーの削除に失敗しました",
+	ErrIncorrectPassword:           "パスワードが正しくありません",
+	ErrExportInProgress:            "データのエクスポートはすでに作成中です",
+	ErrExportNotReady:              "データのエクスポートはまだ作成中です",
+	ErrExportExpired:               "ダウンロードの期限が切れています。もう一度エクスポートしてください",
+	ErrExportNotFound:              "データのエクスポートが見つかりません",
+	ErrFailedToCreateExport:        "データのエクスポートに失敗しました",
+	ErrFailedToFetchExports:        "データのエクスポートの取得に失敗しました",
+	ErrJobNotFound:                 "ジョブが見つかりません",
+	ErrJobNotRetryable:             "再実行できるのは dead のジョブだけです",
+	ErrFailedToFetchJobs:           "ジョブの取得に失敗しました",
+	ErrFailedToRetryJob:            "ジョブの再実行に失敗しました",
+	ErrWebhookNotFound:             "Webhook が見つかりません",
+	ErrWebhookDeliveryNotFound:     "Webhook の配信が見つかりません",
+	ErrInvalidWebhookURL:           "URL は http または https の URL で入力してください",
+	ErrWebhookDeliveryPending:      "この配信はまだ送信待ちです",
+	ErrFailedToCreateWebhook:       "Webhook の登録に失敗しました",
+	ErrFailedToFetchWebhooks:       "Webhook の取得に失敗しました",
+	ErrFailedToUpdateWebhook:       "Webhook の更新に失敗しました",
+	ErrFailedToDeleteWebhook:       "Webhook の削除に失敗しました",
+	ErrFailedToRedeliverWebhook:    "Webhook の再送に失敗しました",
+	ErrFailedToPublishMicropost:    "マイクロポストの公開に失敗しました",
+	ErrFailedToFetchRevisions:      "編集履歴の取得に失敗しました",
+
+	// バリデーション
+	"validation.separator": "、",
+	"validation.default":   "%sの値が不正です",
+	"validation.required":  "%sは必須です",
+	"validation.email":     "%sはメールアドレスの形式で入力してください",
+	"validation.min":       "%sは%s文字以上で入力してください",
+	"validation.max":       "%sは%s文字以下で入力してください",
+	"validation.oneof":     "%sは次のいずれかを指定してください: %s",
+	"validation.url":       "%sは URL の形式で入力してください",
+	"validation.handle":    "%sは半角英数字とアンダースコアの3〜30文字で入力してください（数字のみは不可）",
+
+	// 通知（%s は最新のユーザーの表示名、%d は他の人数）
+	"notification.like":           "%sさんがあなたの投稿にいいねしました",
+	"notification.like.others":    "%sさんと他%d人があなたの投稿にいいねしました",
+	"notification.follow":         "%sさんがあなたをフォローしました",
+	"notification.follow.others":  "%sさんと他%d人があなたをフォローしました",
+	"notification.reply":          "%sさんがあなたの投稿に返信しました",
+	"notification.reply.others":   "%sさんと他%d人があなたの投稿に返信しました",
+	"notification.repost":         "%sさんがあなたの投稿を再投稿しました",
+	"notification.repost.others":  "%sさんと他%d人があなたの投稿を再投稿しました",
+	"notification.quote":          "%sさんがあなたの投稿を引用しました",
+	"notification.quote.others":   "%sさんと他%d人があなたの投稿を引用しました",
+	"notification.mention":        "%sさんがあなたをメンションしました",
+	"notification.mention.others": "%sさんと他%d人があなたをメンションしました",
+}
@@ -0,0 +1,57 @@
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// バリデーションエラーのフィールド名を JSON のキー名で表示する
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+// TranslateBindingError converts an error returned by ShouldBind* into a localized message
+func TranslateBindingError(locale string, err error) string {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		messages := make([]string, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			messages = append(messages, translateFieldError(locale, fe))
+		}
+		return strings.Join(messages, T(locale, "validation.separator"))
+	}
+
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &syntaxError) || errors.As(err, &typeError) {
+		return T(locale, ErrInvalidJSON)
+	}
+
+	return T(locale, ErrInvalidRequest)
+}
+
+func translateFieldError(locale string, fe validator.FieldError) string {
+	key := "validation." + fe.Tag()
+	if _, ok := catalogs[DefaultLocale][key]; !ok {
+		return T(locale, "validation.default", fe.Field())
+	}
+	if fe.Param() != "" {
+		return T(locale, key, fe.Field(), fe.Param())
+	}
+	return T(locale, key, fe.Field())
+}
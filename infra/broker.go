@@ -0,0 +1,104 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-gin-gorm-minimum/realtime"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// NOTIFY のペイロード上限（8000 バイト未満）。超える場合は data を省き、クライアントが ID で取得し直す
+const maxNotifyPayload = 7900
+
+// PostgresBroker は Postgres の LISTEN/NOTIFY で複数のサーバー間にイベントを配信する
+type PostgresBroker struct {
+	db      *gorm.DB
+	channel string
+}
+
+func NewPostgresBroker(db *gorm.DB, channel string) *PostgresBroker {
+	return &PostgresBroker{db: db, channel: channel}
+}
+
+func (b *PostgresBroker) Publish(ctx context.Context, event realtime.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		event.Data = nil
+		if payload, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", b.channel, string(payload)).Error
+}
+
+// Subscribe はコネクションを1本専有して LISTEN し、切断されたら再接続する
+func (b *PostgresBroker) Subscribe(ctx context.Context, handler func(realtime.Event)) error {
+	backoff := time.Second
+	for {
+		err := b.listen(ctx, handler)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("realtime: listen on %s failed, retrying in %s: %v", b.channel, backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (b *PostgresBroker) listen(ctx context.Context, handler func(realtime.Event)) error {
+	sqlDB, err := b.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		pgConn := stdConn.Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+			return err
+		}
+
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					// 専有したコネクションをプールに戻す前に購読を解除する
+					pgConn.Exec(context.Background(), "UNLISTEN *")
+				}
+				return err
+			}
+
+			var event realtime.Event
+			if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+				log.Printf("realtime: invalid payload on %s: %v", b.channel, err)
+				continue
+			}
+			handler(event)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package infra
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 環境変数の読み込みヘルパー（.env.<ENV> は SetupDB で読み込まれる）
+
+// EnvString returns the value of key, or defaultValue when unset
+func EnvString(key, defaultValue string) string {
+	return getEnvOrDefault(key, defaultValue)
+}
+
+// EnvInt returns the integer value of key, or defaultValue when unset or malformed
+func EnvInt(key string, defaultValue int) int {
+	value := getEnvOrDefault(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid integer for %s=%q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// EnvBool returns the boolean value of key, or defaultValue when unset or malformed
+func EnvBool(key string, defaultValue bool) bool {
+	value := getEnvOrDefault(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid boolean for %s=%q, using %t", key, value, defaultValue)
+		return defaultValue
+	}
+	return b
+}
+
+// EnvDuration returns the time.Duration value of key (e.g. "15m"), or defaultValue when unset or malformed
+func EnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := getEnvOrDefault(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid duration for %s=%q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// EnvList returns the comma separated values of key, or defaultValue when unset
+func EnvList(key string, defaultValue []string) []string {
+	value := getEnvOrDefault(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
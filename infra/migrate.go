@@ -0,0 +1,117 @@
+package infra
+
+import (
+	"fmt"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+// MigrationModels は AutoMigrate の対象モデル（依存される側を先に並べる）
+func MigrationModels() []interface{} {
+	return []interface{}{
+		&models.User{},
+		&models.Micropost{},
+		&models.MicropostRevision{},
+		&models.Like{},
+		&models.Tag{},
+		&models.Mention{},
+		&models.Notification{},
+		&models.Follow{},
+		&models.Block{},
+		&models.Mute{},
+		&models.Conversation{},
+		&models.ConversationParticipant{},
+		&models.Message{},
+		&models.Report{},
+		&models.ModerationAction{},
+		&models.DataExport{},
+		&models.Job{},
+		&models.OutboxEvent{},
+		&models.OutboxOffset{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+	}
+}
+
+// postMigrations は GORM のタグで表現できないスキーマ定義（冪等な SQL のみ）
+var postMigrations = []string{
+	// 全文検索: タイトル(A)と本文(B)の重み付き tsvector を生成列として保持する
+	`ALTER TABLE microposts ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(body, '')), 'B')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_microposts_search_vector ON microposts USING GIN (search_vector)`,
+	// 日本語など空白で区切られないテキストは pg_trgm の部分一致で検索する
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`CREATE INDEX IF NOT EXISTS idx_microposts_title_trgm ON microposts USING GIN (title gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_microposts_body_trgm ON microposts USING GIN (body gin_trgm_ops)`,
+	// タグページの新着順取得用
+	`CREATE INDEX IF NOT EXISTS idx_micropost_tags_tag_id ON micropost_tags (tag_id, micropost_id)`,
+	// ハンドル導入前のユーザーに user<ID> を割り当て、大文字小文字を区別せず一意にする
+	`UPDATE users SET handle = 'user' || id WHERE handle = ''`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_handle_lower ON users (lower(handle)) WHERE handle <> ''`,
+	// コメントなしの再投稿はユーザーごとに1回まで
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_microposts_unique_repost ON microposts (user_id, repost_of_id) WHERE kind = 'repost'`,
+	// 公開予定の日時を過ぎた予約投稿の取り出し用
+	`CREATE INDEX IF NOT EXISTS idx_microposts_scheduled ON microposts (publish_at, id) WHERE status = 'scheduled'`,
+	// 作成中のデータエクスポートはユーザーごとに1件まで
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_active ON data_exports (user_id) WHERE status IN ('pending', 'running')`,
+	// ワーカーが実行待ちのジョブを実行時刻の順に取り出す
+	`CREATE INDEX IF NOT EXISTS idx_jobs_ready ON jobs (queue, run_at, id) WHERE status = 'pending'`,
+	// 同じ UniqueKey の未完了のジョブは1件だけ（定期実行のジョブが重複しないように）
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_unique_key ON jobs (unique_key) WHERE unique_key IS NOT NULL AND status IN ('pending', 'running')`,
+	// ユーザーの完全削除で投稿も削除する（ON DELETE の指定がなかった既存の外部キーを張り直す）
+	`DO $$
+	DECLARE fk record;
+	BEGIN
+		FOR fk IN SELECT conname FROM pg_constraint
+			WHERE conrelid = 'microposts'::regclass AND confrelid = 'users'::regclass
+				AND contype = 'f' AND confdeltype <> 'c'
+		LOOP
+			EXECUTE format('ALTER TABLE microposts DROP CONSTRAINT %I', fk.conname);
+			EXECUTE format('ALTER TABLE microposts ADD CONSTRAINT %I FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE', fk.conname);
+		END LOOP;
+	END $$`,
+	// 会話の作成者の完全削除で会話ごと消えないように、作成者への外部キーを SET NULL に張り直す
+	`ALTER TABLE conversations ALTER COLUMN created_by_id DROP NOT NULL`,
+	`DO $$
+	DECLARE fk record;
+	BEGIN
+		FOR fk IN SELECT conname FROM pg_constraint
+			WHERE conrelid = 'conversations'::regclass AND confrelid = 'users'::regclass
+				AND contype = 'f' AND confdeltype <> 'n'
+		LOOP
+			EXECUTE format('ALTER TABLE conversations DROP CONSTRAINT %I', fk.conname);
+			EXECUTE format('ALTER TABLE conversations ADD CONSTRAINT %I FOREIGN KEY (created_by_id) REFERENCES users (id) ON DELETE SET NULL', fk.conname);
+		END LOOP;
+	END $$`,
+	// モデレーターを完全に削除できるように、監査ログのモデレーターへの外部キーを SET NULL に張り直す
+	`ALTER TABLE moderation_actions ALTER COLUMN moderator_id DROP NOT NULL`,
+	`DO $$
+	DECLARE fk record;
+	BEGIN
+		FOR fk IN SELECT conname FROM pg_constraint
+			WHERE conrelid = 'moderation_actions'::regclass AND confrelid = 'users'::regclass
+				AND contype = 'f' AND confdeltype <> 'n'
+		LOOP
+			EXECUTE format('ALTER TABLE moderation_actions DROP CONSTRAINT %I', fk.conname);
+			EXECUTE format('ALTER TABLE moderation_actions ADD CONSTRAINT %I FOREIGN KEY (moderator_id) REFERENCES users (id) ON DELETE SET NULL', fk.conname);
+		END LOOP;
+	END $$`,
+}
+
+// Migrate はテーブルを作成/更新し、追加のスキーマ定義を適用する
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(MigrationModels()...); err != nil {
+		return err
+	}
+	for _, stmt := range postMigrations {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("post migration failed: %w", err)
+		}
+	}
+	return nil
+}
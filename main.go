@@ -11,11 +11,19 @@
 package main
 
 import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	_ "go-gin-gorm-minimum/docs"
 	"go-gin-gorm-minimum/handlers"
 	"go-gin-gorm-minimum/infra"
 	"go-gin-gorm-minimum/middlewares"
 	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/realtime"
 	"go-gin-gorm-minimum/services"
 
 	"github.com/gin-gonic/gin"
@@ -34,64 +42,392 @@ func init() {
 	if err != nil {
 		panic("failed to connect database")
 	}
-	db.AutoMigrate(&models.Micropost{}, &models.User{})
+	if err := infra.Migrate(db); err != nil {
+		panic(err)
+	}
 }
 
 // Router setup
 type Router struct {
-	auth      *handlers.AuthHandler
-	user      *handlers.UserHandler
-	micropost *handlers.MicropostHandler
+	auth         *handlers.AuthHandler
+	user         *handlers.UserHandler
+	micropost    *handlers.MicropostHandler
+	like         *handlers.LikeHandler
+	tag          *handlers.TagHandler
+	follow       *handlers.FollowHandler
+	block        *handlers.BlockHandler
+	notification *handlers.NotificationHandler
+	conversation *handlers.ConversationHandler
+	moderation   *handlers.ModerationHandler
+	export       *handlers.ExportHandler
+	job          *handlers.JobHandler
+	webhook      *handlers.WebhookHandler
+	stream       *handlers.StreamHandler
+	limits       rateLimits
+	bodies       bodyLimits
+}
+
+// rateLimits はルートグループごとのレート制限ミドルウェア
+type rateLimits struct {
+	auth  gin.HandlerFunc
+	read  gin.HandlerFunc
+	write gin.HandlerFunc
+}
+
+// bodyLimits はルートごとのリクエストボディ上限ミドルウェア
+type bodyLimits struct {
+	json   gin.HandlerFunc
+	avatar gin.HandlerFunc
+}
+
+func newBodyLimits() bodyLimits {
+	return bodyLimits{
+		json:   middlewares.BodySizeLimit(int64(infra.EnvInt("BODY_LIMIT_JSON", 1<<20))),
+		avatar: middlewares.BodySizeLimit(int64(infra.EnvInt("BODY_LIMIT_AVATAR", 5<<20))),
+	}
 }
 
-func NewRouter(db *gorm.DB) *Router {
+func corsConfig() middlewares.CORSConfig {
+	cfg := middlewares.DefaultCORSConfig()
+	cfg.AllowOrigins = infra.EnvList("CORS_ALLOW_ORIGINS", []string{"http://localhost:3000"})
+	cfg.AllowCredentials = infra.EnvBool("CORS_ALLOW_CREDENTIALS", true)
+	cfg.MaxAge = infra.EnvDuration("CORS_MAX_AGE", cfg.MaxAge)
+	return cfg
+}
+
+func securityHeadersConfig() middlewares.SecurityHeadersConfig {
+	cfg := middlewares.DefaultSecurityHeadersConfig()
+	cfg.HSTSMaxAge = infra.EnvDuration("SECURITY_HSTS_MAX_AGE", cfg.HSTSMaxAge)
+	return cfg
+}
+
+func newRateLimits(store middlewares.RateLimitStore) rateLimits {
+	policy := func(name, envKey, defaultSpec string) gin.HandlerFunc {
+		p, err := middlewares.ParseRateLimitPolicy(name, infra.EnvString(envKey, defaultSpec))
+		if err != nil {
+			log.Printf("Warning: %v, using %s", err, defaultSpec)
+			p, _ = middlewares.ParseRateLimitPolicy(name, defaultSpec)
+		}
+		return middlewares.RateLimitMiddleware(store, p)
+	}
+
+	return rateLimits{
+		auth:  policy("auth", "RATE_LIMIT_AUTH", "10/1m"),
+		read:  policy("read", "RATE_LIMIT_READ", "300/1m"),
+		write: policy("write", "RATE_LIMIT_WRITE", "30/1m"),
+	}
+}
+
+// newRealtimeBroker は REALTIME_BROKER に応じてイベントの配信経路を選ぶ（複数台構成では postgres）
+func newRealtimeBroker(db *gorm.DB) realtime.Broker {
+	switch broker := infra.EnvString("REALTIME_BROKER", "memory"); broker {
+	case "postgres":
+		return infra.NewPostgresBroker(db, infra.EnvString("REALTIME_CHANNEL", "realtime_events"))
+	case "memory":
+		return realtime.NewMemoryBroker()
+	default:
+		log.Printf("Warning: unknown REALTIME_BROKER %q, using memory", broker)
+		return realtime.NewMemoryBroker()
+	}
+}
+
+// newContentFilter は投稿の自動モデレーションのルールを環境変数から組み立てる（上限が 0 以下のルールは無効）
+func newContentFilter() *services.ContentFilter {
+	action := func(envKey, defaultAction string) string {
+		a := infra.EnvString(envKey, defaultAction)
+		if !models.ValidContentAction(a) {
+			log.Printf("Warning: invalid %s %q, using %s", envKey, a, defaultAction)
+			return defaultAction
+		}
+		return a
+	}
+
+	var rules []services.ContentRule
+	if path := infra.EnvString("CONTENT_WORD_FILTER_FILE", ""); path != "" {
+		if words, err := loadWordFilter(path); err != nil {
+			log.Printf("Warning: word filter not loaded: %v", err)
+		} else {
+			rules = append(rules, services.WordFilterRule{Words: words})
+		}
+	}
+	if max := infra.EnvInt("CONTENT_MAX_LINKS", 5); max > 0 {
+		rules = append(rules, services.LinkLimitRule{Max: max, Action: action("CONTENT_LINK_ACTION", models.ContentActionReject)})
+	}
+	if limit := infra.EnvInt("CONTENT_DUPLICATE_LIMIT", 3); limit > 0 {
+		rules = append(rules, services.DuplicateRule{
+			Window: infra.EnvDuration("CONTENT_DUPLICATE_WINDOW", 10*time.Minute),
+			Limit:  limit,
+			Action: action("CONTENT_DUPLICATE_ACTION", models.ContentActionShadowHide),
+		})
+	}
+	return services.NewContentFilter(rules...)
+}
+
+func loadWordFilter(path string) (*models.WordFilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return models.ParseWordFilter(f)
+}
+
+// exportDir はデータエクスポートのアーカイブを保存するディレクトリ
+func exportDir() string {
+	return infra.EnvString("EXPORT_DIR", "exports")
+}
+
+// newEventDispatcher はドメインイベントの配信先を環境変数から組み立てる
+func newEventDispatcher(db *gorm.DB) *services.EventDispatcher {
+	dispatcher := services.NewEventDispatcher(db)
+	dispatcher.Subscribe("webhooks", services.NewWebhookService(db).FanOut)
+	if path := infra.EnvString("EVENT_LOG_FILE", ""); path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Printf("Warning: event log not opened: %v", err)
+		} else {
+			dispatcher.AddSink("event-log", services.NewJSONLinesSink(f))
+		}
+	}
+	return dispatcher
+}
+
+// newJobQueue はアプリケーションのジョブの処理を登録した JobQueue を返す
+func newJobQueue(db *gorm.DB, dispatcher *services.EventDispatcher) *services.JobQueue {
+	queue := services.NewJobQueue(db)
+	services.RegisterJobs(queue, db, services.NewExportService(db, exportDir()), dispatcher)
+	return queue
+}
+
+// workerOptions はジョブのワーカーの設定を環境変数から読み込む
+func workerOptions() services.WorkerOptions {
+	return services.WorkerOptions{
+		Queues:       infra.EnvList("JOB_QUEUES", []string{models.DefaultJobQueue}),
+		Concurrency:  infra.EnvInt("JOB_CONCURRENCY", 2),
+		PollInterval: infra.EnvDuration("JOB_POLL_INTERVAL", time.Second),
+		LockTimeout:  infra.EnvDuration("JOB_LOCK_TIMEOUT", 15*time.Minute),
+	}
+}
+
+// runJobs は ctx が終わるまでドメインイベントを配信し、定期実行のジョブを登録してワーカーでジョブを処理する
+func runJobs(ctx context.Context, queue *services.JobQueue, dispatcher *services.EventDispatcher) {
+	go dispatcher.Run(ctx, infra.EnvDuration("OUTBOX_POLL_INTERVAL", time.Second))
+	go queue.Schedule(ctx, infra.EnvDuration("SCHEDULED_PUBLISH_INTERVAL", time.Minute), services.JobTypePublishPosts)
+	go queue.Schedule(ctx, infra.EnvDuration("TRASH_PURGE_INTERVAL", time.Hour), services.JobTypeTrashPurge)
+	go queue.Schedule(ctx, infra.EnvDuration("EXPORT_CLEANUP_INTERVAL", time.Hour), services.JobTypeExportCleanup)
+	go queue.Schedule(ctx, infra.EnvDuration("JOB_PRUNE_INTERVAL", time.Hour), services.JobTypeJobPrune)
+	go queue.Schedule(ctx, infra.EnvDuration("JOB_PRUNE_INTERVAL", time.Hour), services.JobTypeOutboxPrune)
+	queue.RunWorker(ctx, workerOptions())
+}
+
+func NewRouter(db *gorm.DB, hub *realtime.Hub, queue *services.JobQueue) *Router {
 	userService := services.NewUserService(db)
 	authService := services.NewAuthService(db)
 	micropostService := services.NewMicropostService(db)
+	likeService := services.NewLikeService(db)
+	tagService := services.NewTagService(db)
+	followService := services.NewFollowService(db)
+	blockService := services.NewBlockService(db)
+	notificationService := services.NewNotificationService(db)
+	conversationService := services.NewConversationService(db)
+	moderationService := services.NewModerationService(db)
+	exportService := services.NewExportService(db, exportDir())
 
 	return &Router{
-		auth:      handlers.NewAuthHandler(authService, userService),
-		user:      handlers.NewUserHandler(userService),
-		micropost: handlers.NewMicropostHandler(micropostService),
+		auth:         handlers.NewAuthHandler(authService, userService),
+		user:         handlers.NewUserHandler(userService),
+		micropost:    handlers.NewMicropostHandler(micropostService),
+		like:         handlers.NewLikeHandler(likeService),
+		tag:          handlers.NewTagHandler(tagService, micropostService),
+		follow:       handlers.NewFollowHandler(followService),
+		block:        handlers.NewBlockHandler(blockService),
+		notification: handlers.NewNotificationHandler(notificationService),
+		conversation: handlers.NewConversationHandler(conversationService),
+		moderation:   handlers.NewModerationHandler(moderationService),
+		export:       handlers.NewExportHandler(exportService),
+		job:          handlers.NewJobHandler(queue),
+		webhook:      handlers.NewWebhookHandler(services.NewWebhookService(db)),
+		stream:       handlers.NewStreamHandler(hub),
+		limits:       newRateLimits(middlewares.NewMemoryRateLimitStore()),
+		bodies:       newBodyLimits(),
 	}
 }
 
 func (router *Router) Setup(r *gin.Engine) {
+	r.Use(
+		middlewares.CORSMiddleware(corsConfig()),
+		middlewares.SecurityHeadersMiddleware(securityHeadersConfig()),
+		middlewares.LocaleMiddleware(),
+	)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	v1 := r.Group("/api/v1")
 	{
 		router.setupMicropostRoutes(v1.Group("/microposts"))
 		router.setupUserRoutes(v1.Group("/users"))
+		router.setupTagRoutes(v1.Group("/tags"))
+		router.setupNotificationRoutes(v1.Group("/notifications"))
+		router.setupConversationRoutes(v1.Group("/conversations"))
+		router.setupExportRoutes(v1.Group("/exports"))
+		router.setupWebhookRoutes(v1.Group("/webhooks"))
+		router.setupAdminRoutes(v1.Group("/admin"))
+		v1.GET("/stream", middlewares.AuthMiddleware(), router.limits.read, router.stream.Stream)
 		router.setupAuthRoutes(v1.Group("/auth"))
 	}
 }
 
 func (router *Router) setupMicropostRoutes(group *gin.RouterGroup) {
 	group.Use(middlewares.AuthMiddleware())
-	group.POST("", router.micropost.CreateMicropost)
-	group.GET("", router.micropost.GetMicroposts)
-	group.GET("/:id", router.micropost.GetMicropost)
+	group.POST("", router.limits.write, router.bodies.json, router.micropost.CreateMicropost)
+	group.GET("", router.limits.read, router.micropost.GetMicroposts)
+	group.GET("/search", router.limits.read, router.micropost.SearchMicroposts)
+	group.GET("/trash", router.limits.read, router.micropost.GetTrash)
+	group.POST("/drafts", router.limits.write, router.bodies.json, router.micropost.CreateDraft)
+	group.GET("/drafts", router.limits.read, router.micropost.GetDrafts)
+	group.GET("/drafts/:id", router.limits.read, router.micropost.GetDraft)
+	group.PUT("/drafts/:id", router.limits.write, router.bodies.json, router.micropost.UpdateDraft)
+	group.DELETE("/drafts/:id", router.limits.write, router.micropost.DeleteDraft)
+	group.POST("/drafts/:id/publish", router.limits.write, router.micropost.PublishDraft)
+	group.GET("/:id", router.limits.read, router.micropost.GetMicropost)
+	group.PUT("/:id", router.limits.write, router.bodies.json, router.micropost.UpdateMicropost)
+	group.DELETE("/:id", router.limits.write, router.micropost.DeleteMicropost)
+	group.POST("/:id/restore", router.limits.write, router.micropost.RestoreMicropost)
+	group.POST("/:id/replies", router.limits.write, router.bodies.json, router.micropost.CreateReply)
+	group.GET("/:id/thread", router.limits.read, router.micropost.GetThread)
+	group.GET("/:id/revisions", router.limits.read, router.micropost.GetRevisions)
+	group.POST("/:id/repost", router.limits.write, router.bodies.json, router.micropost.Repost)
+	group.DELETE("/:id/repost", router.limits.write, router.micropost.Unrepost)
+	group.POST("/:id/like", router.limits.write, router.like.LikeMicropost)
+	group.DELETE("/:id/like", router.limits.write, router.like.UnlikeMicropost)
+	group.GET("/:id/likes", router.limits.read, router.like.GetLikers)
+	group.POST("/:id/report", router.limits.write, router.bodies.json, router.moderation.ReportMicropost)
 }
 
 func (router *Router) setupUserRoutes(group *gin.RouterGroup) {
 	group.Use(middlewares.AuthMiddleware())
-	group.GET("", router.user.GetUsers)
-	group.GET("/:id", router.user.GetUser)
-	group.PUT("/avatar", router.user.UpdateAvatar)
+	group.GET("", router.limits.read, router.user.GetUsers)
+	group.GET("/:id", router.limits.read, router.user.GetUser)
+	group.GET("/by-handle/:handle", router.limits.read, router.user.GetUserByHandle)
+	group.PATCH("/me", router.limits.write, router.bodies.json, router.user.UpdateProfile)
+	group.DELETE("/me", router.limits.write, router.bodies.json, router.auth.DeleteAccount)
+	group.POST("/:id/follow", router.limits.write, router.follow.FollowUser)
+	group.DELETE("/:id/follow", router.limits.write, router.follow.UnfollowUser)
+	group.GET("/me/blocks", router.limits.read, router.block.GetBlockedUsers)
+	group.GET("/me/mutes", router.limits.read, router.block.GetMutedUsers)
+	group.POST("/:id/block", router.limits.write, router.block.BlockUser)
+	group.DELETE("/:id/block", router.limits.write, router.block.UnblockUser)
+	group.POST("/:id/mute", router.limits.write, router.block.MuteUser)
+	group.DELETE("/:id/mute", router.limits.write, router.block.UnmuteUser)
+	group.POST("/:id/report", router.limits.write, router.bodies.json, router.moderation.ReportUser)
+	group.PUT("/avatar", router.limits.write, router.bodies.avatar, router.user.UpdateAvatar)
+	group.PUT("/locale", router.limits.write, router.bodies.json, router.user.UpdateLocale)
+}
+
+func (router *Router) setupTagRoutes(group *gin.RouterGroup) {
+	group.Use(middlewares.AuthMiddleware(), router.limits.read)
+	group.GET("/trending", router.tag.GetTrendingTags)
+	group.GET("/:name/microposts", router.tag.GetTagMicroposts)
+}
+
+func (router *Router) setupNotificationRoutes(group *gin.RouterGroup) {
+	group.Use(middlewares.AuthMiddleware())
+	group.GET("", router.limits.read, router.notification.GetNotifications)
+	group.GET("/unread-count", router.limits.read, router.notification.GetUnreadCount)
+	group.POST("/read-all", router.limits.write, router.notification.MarkAllRead)
+	group.POST("/:id/read", router.limits.write, router.notification.MarkRead)
+}
+
+func (router *Router) setupConversationRoutes(group *gin.RouterGroup) {
+	group.Use(middlewares.AuthMiddleware())
+	group.POST("", router.limits.write, router.bodies.json, router.conversation.StartConversation)
+	group.GET("", router.limits.read, router.conversation.GetConversations)
+	group.GET("/unread-count", router.limits.read, router.conversation.GetUnreadMessageCount)
+	group.GET("/:id", router.limits.read, router.conversation.GetConversation)
+	group.GET("/:id/messages", router.limits.read, router.conversation.GetMessages)
+	group.POST("/:id/messages", router.limits.write, router.bodies.json, router.conversation.SendMessage)
+	group.POST("/:id/read", router.limits.write, router.bodies.json, router.conversation.MarkConversationRead)
+}
+
+func (router *Router) setupExportRoutes(group *gin.RouterGroup) {
+	group.Use(middlewares.AuthMiddleware())
+	group.POST("", router.limits.write, router.export.CreateExport)
+	group.GET("", router.limits.read, router.export.GetExports)
+	group.GET("/:id", router.limits.read, router.export.GetExport)
+	group.GET("/:id/download", router.limits.read, router.export.DownloadExport)
+}
+
+func (router *Router) setupWebhookRoutes(group *gin.RouterGroup) {
+	group.Use(middlewares.AuthMiddleware())
+	group.POST("", router.limits.write, router.bodies.json, router.webhook.CreateWebhook)
+	group.GET("", router.limits.read, router.webhook.GetWebhooks)
+	group.GET("/:id", router.limits.read, router.webhook.GetWebhook)
+	group.PATCH("/:id", router.limits.write, router.bodies.json, router.webhook.UpdateWebhook)
+	group.DELETE("/:id", router.limits.write, router.webhook.DeleteWebhook)
+	group.GET("/:id/deliveries", router.limits.read, router.webhook.GetWebhookDeliveries)
+	group.POST("/:id/deliveries/:delivery_id/redeliver", router.limits.write, router.webhook.RedeliverWebhook)
+}
+
+func (router *Router) setupAdminRoutes(group *gin.RouterGroup) {
+	group.Use(middlewares.AuthMiddleware(), middlewares.AdminMiddleware())
+	group.GET("/reports", router.limits.read, router.moderation.GetReports)
+	group.POST("/reports/:id/actions", router.limits.write, router.bodies.json, router.moderation.ResolveReport)
+	group.GET("/moderation-actions", router.limits.read, router.moderation.GetModerationActions)
+	group.DELETE("/users/:id", router.limits.write, router.user.DeleteUser)
+	group.POST("/users/:id/restore", router.limits.write, router.user.RestoreUser)
+	group.GET("/jobs", router.limits.read, router.job.GetJobs)
+	group.POST("/jobs/:id/retry", router.limits.write, router.job.RetryJob)
 }
 
 func (router *Router) setupAuthRoutes(group *gin.RouterGroup) {
+	group.Use(router.limits.auth, router.bodies.json)
 	group.POST("/signup", router.auth.SignupUser)
 	group.POST("/login", router.auth.LoginUser)
+	group.POST("/cancel-deletion", router.auth.CancelDeletion)
 	group.GET("/me", middlewares.AuthMiddleware(), router.auth.GetMe)
 }
 
 func main() {
 	db := infra.SetupDB()
-	router := NewRouter(db)
+	models.MicropostBodyMaxLength = infra.EnvInt("MICROPOST_BODY_MAX_LENGTH", models.MicropostBodyMaxLength)
+	models.DefaultTrendingWindow = infra.EnvDuration("TRENDING_WINDOW", models.DefaultTrendingWindow)
+	models.TrashRetention = infra.EnvDuration("TRASH_RETENTION", models.TrashRetention)
+	models.ExportTTL = infra.EnvDuration("EXPORT_TTL", models.ExportTTL)
+	services.JobRetention = infra.EnvDuration("JOB_RETENTION", services.JobRetention)
+	services.OutboxRetention = infra.EnvDuration("OUTBOX_RETENTION", services.OutboxRetention)
+	models.WebhookMaxAttempts = infra.EnvInt("WEBHOOK_MAX_ATTEMPTS", models.WebhookMaxAttempts)
+	services.WebhookAllowPrivateNetworks = infra.EnvBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+	hub := realtime.NewHub(newRealtimeBroker(db), services.NewFollowerAudience(db))
+	services.SetEventPublisher(hub)
+	services.SetContentFilter(newContentFilter())
+	middlewares.SetAccountCheck(services.NewUserService(db).Active)
+	go func() {
+		if err := hub.Run(context.Background()); err != nil {
+			log.Printf("realtime hub stopped: %v", err)
+		}
+	}()
+	dispatcher := newEventDispatcher(db)
+	queue := newJobQueue(db, dispatcher)
+
+	// go run . worker でジョブのワーカーだけを起動する（API サーバーとは別のプロセスでジョブを処理する構成）
+	if len(os.Args) > 1 && os.Args[1] == "worker" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		log.Printf("jobs: worker started")
+		runJobs(ctx, queue, dispatcher)
+		return
+	}
+	if infra.EnvBool("JOB_EMBEDDED_WORKER", true) {
+		go runJobs(context.Background(), queue, dispatcher)
+	}
+
+	router := NewRouter(db, hub, queue)
 
 	r := gin.Default()
+	// X-Forwarded-For は TRUSTED_PROXIES（カンマ区切りの IP・CIDR）から来たときだけ使う（既定は接続元の IP）
+	if err := r.SetTrustedProxies(infra.EnvList("TRUSTED_PROXIES", nil)); err != nil {
+		log.Printf("Warning: invalid TRUSTED_PROXIES: %v, trusting no proxies", err)
+		r.SetTrustedProxies(nil)
+	}
 	router.Setup(r)
 	r.Run(":8080")
 }
@@ -4,40 +4,95 @@ import (
 	"net/http"
 	"strings"
 
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/models"
 	"go-gin-gorm-minimum/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 )
 
+// accountCheck reports the current role of the user a token was issued to, whether the account is still active
+// and whether it is suspended. When nil, a valid token and its role claim are trusted until the token expires.
+var accountCheck func(userID uint) (role string, active, suspended bool, err error)
+
+// SetAccountCheck makes AuthMiddleware reject tokens of accounts that have been deleted since the token was issued,
+// reject everything but reads from suspended accounts, and take the role from the returned value instead of the token,
+// so demoting an admin takes effect immediately.
+// Call it once at startup. The check runs on every authenticated request, so it should be a cheap lookup.
+func SetAccountCheck(check func(userID uint) (role string, active, suspended bool, err error)) {
+	accountCheck = check
+}
+
+// isReadOnly reports whether the request method does not change anything
+func isReadOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		header := c.GetHeader("Authorization")
 		if header == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			utils.AbortWithErrorJSON(c, http.StatusUnauthorized, i18n.ErrAuthorizationHeaderRequired)
 			return
 		}
 
 		if !strings.HasPrefix(header, "Bearer ") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
+			utils.AbortWithErrorJSON(c, http.StatusUnauthorized, i18n.ErrInvalidTokenFormat)
 			return
 		}
 
 		tokenString := strings.TrimPrefix(header, "Bearer ")
 		token, err := utils.ParseJWTToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			utils.AbortWithErrorJSON(c, http.StatusUnauthorized, i18n.ErrInvalidToken)
 			return
 		}
 
 		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			c.Set("user_id", uint(claims["sub"].(float64)))
+			userID := uint(claims["sub"].(float64))
+			role, _ := claims["role"].(string)
+			if accountCheck != nil {
+				current, active, suspended, err := accountCheck(userID)
+				if err != nil {
+					utils.AbortWithErrorJSON(c, http.StatusInternalServerError, i18n.ErrFailedToFetchUsers)
+					return
+				}
+				if !active {
+					utils.AbortWithErrorJSON(c, http.StatusUnauthorized, i18n.ErrAccountDeleted)
+					return
+				}
+				if suspended && !isReadOnly(c.Request.Method) {
+					utils.AbortWithErrorJSON(c, http.StatusForbidden, i18n.ErrAccountSuspended)
+					return
+				}
+				role = current
+			}
+			c.Set("user_id", userID)
 			c.Set("email", claims["email"].(string))
+			if role != "" {
+				c.Set("role", role)
+			}
+			if locale, ok := claims["locale"].(string); ok && i18n.IsSupported(locale) {
+				c.Set(i18n.ContextKey, locale)
+			}
 			c.Next()
 		} else {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			utils.AbortWithErrorJSON(c, http.StatusUnauthorized, i18n.ErrInvalidTokenClaims)
+			return
+		}
+	}
+}
+
+// AdminMiddleware allows only users with the admin role.
+// It must run after AuthMiddleware. Without an account check the role comes from the token,
+// so a role change takes effect only when the user's next token is issued.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != models.RoleAdmin {
+			utils.AbortWithErrorJSON(c, http.StatusForbidden, i18n.ErrAdminRequired)
 			return
 		}
+		c.Next()
 	}
 }
@@ -0,0 +1,109 @@
+package middlewares_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin", middlewares.AuthMiddleware(), middlewares.AdminMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	token := func(role string) string {
+		signed, err := utils.GenerateJWTToken(models.User{ID: 1, Email: "user1@example.com", Role: role})
+		assert.NoError(t, err)
+		return "Bearer " + signed
+	}
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "Admin", authorization: token(models.RoleAdmin), expectedStatus: http.StatusOK},
+		{name: "User", authorization: token(models.RoleUser), expectedStatus: http.StatusForbidden},
+		{name: "No Role Claim", authorization: token(""), expectedStatus: http.StatusForbidden},
+		{name: "No Token", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestAccountCheck(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/me", middlewares.AuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/me", middlewares.AuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/admin", middlewares.AuthMiddleware(), middlewares.AdminMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	middlewares.SetAccountCheck(func(userID uint) (string, bool, bool, error) {
+		switch userID {
+		case 1:
+			return models.RoleUser, true, false, nil
+		case 2:
+			return "", false, false, nil
+		case 4:
+			return models.RoleAdmin, true, false, nil
+		case 5:
+			return models.RoleUser, true, true, nil
+		}
+		return "", false, false, errors.New("database unavailable")
+	})
+	defer middlewares.SetAccountCheck(nil)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		userID         uint
+		tokenRole      string
+		expectedStatus int
+	}{
+		{name: "Active", path: "/me", userID: 1, expectedStatus: http.StatusOK},
+		{name: "Deleted", path: "/me", userID: 2, expectedStatus: http.StatusUnauthorized},
+		{name: "Check Failed", path: "/me", userID: 3, expectedStatus: http.StatusInternalServerError},
+		// トークンのロールではなく現在のロールで判定する
+		{name: "Demoted Admin", path: "/admin", userID: 1, tokenRole: models.RoleAdmin, expectedStatus: http.StatusForbidden},
+		{name: "Promoted User", path: "/admin", userID: 4, tokenRole: models.RoleUser, expectedStatus: http.StatusOK},
+		// 利用停止中は読み取りだけできる
+		{name: "Suspended Read", path: "/me", userID: 5, expectedStatus: http.StatusOK},
+		{name: "Suspended Write", method: http.MethodPost, path: "/me", userID: 5, expectedStatus: http.StatusForbidden},
+		{name: "Active Write", method: http.MethodPost, path: "/me", userID: 1, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := utils.GenerateJWTToken(models.User{ID: tt.userID, Email: "user@example.com", Role: tt.tokenRole})
+			assert.NoError(t, err)
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+signed)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
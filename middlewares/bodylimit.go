@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodySizeLimit caps the request body at maxBytes. Requests that declare a larger
+// Content-Length are rejected with 413 up front; otherwise reads past the cap fail
+// and handlers report 413 via utils.RequestTooLarge.
+func BodySizeLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			utils.AbortWithErrorJSON(c, http.StatusRequestEntityTooLarge, i18n.ErrRequestTooLarge, maxBytes)
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,104 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gin-gorm-minimum/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	cfg := middlewares.DefaultCORSConfig()
+	cfg.AllowOrigins = []string{"https://app.example.com"}
+	cfg.AllowCredentials = true
+	r.Use(middlewares.CORSMiddleware(cfg))
+	r.GET("/resource", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name           string
+		method         string
+		origin         string
+		preflight      bool
+		expectedStatus int
+		expectedOrigin string
+	}{
+		{name: "Allowed Simple Request", method: http.MethodGet, origin: "https://app.example.com", expectedStatus: http.StatusOK, expectedOrigin: "https://app.example.com"},
+		{name: "Disallowed Simple Request", method: http.MethodGet, origin: "https://evil.example.com", expectedStatus: http.StatusOK},
+		{name: "Allowed Preflight", method: http.MethodOptions, origin: "https://app.example.com", preflight: true, expectedStatus: http.StatusNoContent, expectedOrigin: "https://app.example.com"},
+		{name: "Disallowed Preflight", method: http.MethodOptions, origin: "https://evil.example.com", preflight: true, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/resource", nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+			if tt.expectedOrigin != "" {
+				assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+			}
+			if tt.preflight && tt.expectedOrigin != "" {
+				assert.Equal(t, "43200", w.Header().Get("Access-Control-Max-Age"))
+			}
+		})
+	}
+}
+
+func TestBodySizeLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/echo", middlewares.BodySizeLimit(8), func(c *gin.Context) {
+		var body map[string]string
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewBufferString(`{"title":"too long for the limit"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/echo", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	cfg := middlewares.DefaultSecurityHeadersConfig()
+	r.Use(middlewares.SecurityHeadersMiddleware(cfg))
+	r.GET("/api", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/swagger/index.html", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api", nil))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Contains(t, w.Header().Get("Strict-Transport-Security"), "max-age=31536000")
+	assert.Equal(t, cfg.ContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+	assert.Equal(t, cfg.SwaggerContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+}
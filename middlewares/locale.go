@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"go-gin-gorm-minimum/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware resolves the response locale from Accept-Language.
+// AuthMiddleware overrides it with the user's stored preference when one is set.
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+		if locale == "" {
+			locale = i18n.DefaultLocale
+		}
+		c.Set(i18n.ContextKey, locale)
+		c.Next()
+	}
+}
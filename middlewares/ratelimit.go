@@ -0,0 +1,161 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-gin-gorm-minimum/i18n"
+	"go-gin-gorm-minimum/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitPolicy is a token bucket: Limit requests of burst, refilled evenly over Window
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// ParseRateLimitPolicy parses a "<limit>/<window>" spec such as "30/1m"
+func ParseRateLimitPolicy(name, spec string) (RateLimitPolicy, error) {
+	parts := strings.SplitN(spec, "/", 2)
+	if len(parts) != 2 {
+		return RateLimitPolicy{}, fmt.Errorf("invalid rate limit %q: expected <limit>/<window>", spec)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || limit <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("invalid rate limit %q: limit must be a positive integer", spec)
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+	if err != nil || window <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("invalid rate limit %q: window must be a positive duration", spec)
+	}
+	return RateLimitPolicy{Name: name, Limit: limit, Window: window}, nil
+}
+
+func (p RateLimitPolicy) ratePerSecond() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// RateLimitResult is the outcome of taking one token from a bucket
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token is available (zero when allowed)
+}
+
+// RateLimitStore holds token buckets. The in-memory store is per process;
+// implement this interface over a shared backend (e.g. Redis) to limit across instances.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	window  time.Duration
+}
+
+// 満タンになったバケットを掃除する間隔
+const rateLimitSweepInterval = time.Minute
+
+// MemoryRateLimitStore is an in-process RateLimitStore
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*tokenBucket), lastSweep: time.Now()}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	now := time.Now()
+	rate := policy.ratePerSecond()
+	capacity := float64(policy.Limit)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updated: now, window: policy.Window}
+		s.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+	bucket.updated = now
+
+	result := RateLimitResult{Limit: policy.Limit}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - bucket.tokens) / rate)
+	}
+	result.Remaining = int(math.Floor(bucket.tokens))
+	result.ResetAfter = secondsToDuration((capacity - bucket.tokens) / rate)
+	return result, nil
+}
+
+// sweep drops buckets that have refilled completely, at most once per rateLimitSweepInterval
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.updated) >= bucket.window {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// RateLimitMiddleware limits requests per user_id (when AuthMiddleware ran first) or per client IP.
+// The client IP honors X-Forwarded-For only from the engine's trusted proxies, so configure them with SetTrustedProxies.
+// It emits RateLimit-* headers and answers 429 with Retry-After once the bucket is empty.
+func RateLimitMiddleware(store RateLimitStore, policy RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := policy.Name + ":ip:" + c.ClientIP()
+		if userID, ok := c.Get("user_id"); ok {
+			key = fmt.Sprintf("%s:user:%v", policy.Name, userID)
+		}
+
+		result, err := store.Take(c.Request.Context(), key, policy)
+		if err != nil {
+			// ストア障害時はリクエストを通す（fail open）
+			log.Printf("rate limit store error: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds()))))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds())))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			utils.AbortWithErrorJSON(c, http.StatusTooManyRequests, i18n.ErrRateLimited)
+			return
+		}
+		c.Next()
+	}
+}
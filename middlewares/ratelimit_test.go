@@ -0,0 +1,75 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	assert.NoError(t, r.SetTrustedProxies(nil))
+
+	policy := middlewares.RateLimitPolicy{Name: "test", Limit: 2, Window: time.Hour}
+	store := middlewares.NewMemoryRateLimitStore()
+	r.GET("/limited", func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set("user_id", userID)
+		}
+	}, middlewares.RateLimitMiddleware(store, policy), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+
+	w = request("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = request("")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// 信頼するプロキシがなければ X-Forwarded-For を変えても別のバケットにならない
+	req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// ユーザー単位のバケットは IP 単位のものと独立している
+	w = request("1")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestParseRateLimitPolicy(t *testing.T) {
+	policy, err := middlewares.ParseRateLimitPolicy("write", "30/1m")
+	assert.NoError(t, err)
+	assert.Equal(t, 30, policy.Limit)
+	assert.Equal(t, time.Minute, policy.Window)
+
+	for _, spec := range []string{"", "30", "0/1m", "30/abc", "-1/1m"} {
+		_, err := middlewares.ParseRateLimitPolicy("write", spec)
+		assert.Error(t, err, spec)
+	}
+}
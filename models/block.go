@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Block モデル定義（BlockerID が BlockedID をブロックする、組み合わせは一意）
+//
+// ブロックは双方向に働き、どちらからもフォロー・返信・メンション・ダイレクトメッセージができなくなる。
+type Block struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BlockerID uint      `json:"blocker_id" gorm:"not null;uniqueIndex:idx_blocks_blocker_blocked"`
+	Blocker   User      `json:"-" gorm:"foreignKey:BlockerID;references:ID;constraint:OnDelete:CASCADE"`
+	BlockedID uint      `json:"blocked_id" gorm:"not null;uniqueIndex:idx_blocks_blocker_blocked;index"`
+	Blocked   User      `json:"-" gorm:"foreignKey:BlockedID;references:ID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// Mute モデル定義（MuterID が MutedID をミュートする、組み合わせは一意）
+//
+// ミュートは MuterID だけに働き、タイムライン・検索・通知から MutedID の投稿や操作を除く。相手には伝わらない。
+type Mute struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	MuterID   uint      `json:"muter_id" gorm:"not null;uniqueIndex:idx_mutes_muter_muted"`
+	Muter     User      `json:"-" gorm:"foreignKey:MuterID;references:ID;constraint:OnDelete:CASCADE"`
+	MutedID   uint      `json:"muted_id" gorm:"not null;uniqueIndex:idx_mutes_muter_muted;index"`
+	Muted     User      `json:"-" gorm:"foreignKey:MutedID;references:ID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
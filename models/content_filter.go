@@ -0,0 +1,149 @@
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 自動モデレーションの判定（後ろほど厳しい）
+const (
+	ContentActionAllow      = "allow"
+	ContentActionFlag       = "flag"
+	ContentActionShadowHide = "shadow_hide"
+	ContentActionReject     = "reject"
+)
+
+// 自動モデレーションのルール名（自動通報の reason にも使う）
+const (
+	ContentRuleBannedWord = "banned_word"
+	ContentRuleLinkLimit  = "link_limit"
+	ContentRuleDuplicate  = "duplicate"
+)
+
+var contentActionSeverity = map[string]int{
+	ContentActionAllow:      0,
+	ContentActionFlag:       1,
+	ContentActionShadowHide: 2,
+	ContentActionReject:     3,
+}
+
+// ValidContentAction は action が判定として使える値かどうかを返す
+func ValidContentAction(action string) bool {
+	_, ok := contentActionSeverity[action]
+	return ok
+}
+
+// ContentVerdict は自動モデレーションの判定結果（Detail はモデレーター向けの説明）
+type ContentVerdict struct {
+	Action string
+	Rule   string
+	Detail string
+}
+
+// Allowed は投稿をそのまま公開してよいかどうかを返す
+func (v ContentVerdict) Allowed() bool {
+	return v.Action == "" || v.Action == ContentActionAllow
+}
+
+// StricterThan は v が other より厳しい判定かどうかを返す
+func (v ContentVerdict) StricterThan(other ContentVerdict) bool {
+	return contentActionSeverity[v.Action] > contentActionSeverity[other.Action]
+}
+
+// katakanaToHiragana はカタカナとひらがなの差（ァ..ヶ → ぁ..ゖ）
+const katakanaToHiragana = 'ァ' - 'ぁ'
+
+// FoldText は文字種の違いを吸収した照合用の文字列を返す
+// (NFKC 正規化で全角英数・半角カナを統一し、英字は小文字、カタカナはひらがなに寄せる)
+func FoldText(s string) string {
+	s = norm.NFKC.String(s)
+	return strings.Map(func(r rune) rune {
+		if r >= 'ァ' && r <= 'ヶ' {
+			return r - katakanaToHiragana
+		}
+		return r
+	}, strings.ToLower(s))
+}
+
+type filterWord struct {
+	word   string
+	folded string
+	action string
+}
+
+// WordFilter は禁止語のリスト（語ごとに判定を持つ）
+type WordFilter struct {
+	words []filterWord
+}
+
+// NewWordFilter は words をすべて action で判定する WordFilter を返す
+func NewWordFilter(action string, words ...string) *WordFilter {
+	f := &WordFilter{}
+	for _, word := range words {
+		f.add(action, word)
+	}
+	return f
+}
+
+func (f *WordFilter) add(action, word string) {
+	word = strings.TrimSpace(word)
+	if folded := FoldText(word); folded != "" {
+		f.words = append(f.words, filterWord{word: word, folded: folded, action: action})
+	}
+}
+
+// ParseWordFilter は1行1語の禁止語リストを読み込む。
+// "flag:語" のように判定を前に付けられる（省略時は reject）。空行と # で始まる行は無視する。
+func ParseWordFilter(r io.Reader) (*WordFilter, error) {
+	f := &WordFilter{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		action, word := ContentActionReject, text
+		if prefix, rest, ok := strings.Cut(text, ":"); ok && ValidContentAction(prefix) {
+			action, word = prefix, rest
+		}
+		if action == ContentActionAllow {
+			return nil, fmt.Errorf("word filter line %d: %q is not a filtering action", line, action)
+		}
+		f.add(action, word)
+	}
+	return f, scanner.Err()
+}
+
+// Len は登録されている語の数を返す
+func (f *WordFilter) Len() int {
+	return len(f.words)
+}
+
+// Match は texts に含まれる禁止語のうち最も厳しい判定を返す（該当なしなら allow）
+func (f *WordFilter) Match(texts ...string) ContentVerdict {
+	verdict := ContentVerdict{Action: ContentActionAllow}
+	folded := FoldText(strings.Join(texts, "\n"))
+	for _, w := range f.words {
+		candidate := ContentVerdict{Action: w.action, Rule: ContentRuleBannedWord, Detail: w.word}
+		if candidate.StricterThan(verdict) && strings.Contains(folded, w.folded) {
+			verdict = candidate
+		}
+	}
+	return verdict
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// CountLinks は texts に含まれる URL の数を数える（全角で書かれた URL も数える）
+func CountLinks(texts ...string) int {
+	count := 0
+	for _, text := range texts {
+		count += len(linkPattern.FindAllStringIndex(norm.NFKC.String(text), -1))
+	}
+	return count
+}
@@ -0,0 +1,94 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"go-gin-gorm-minimum/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoldText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Full Width ASCII", input: "ＳＰＡＭ１２３", expected: "spam123"},
+		{name: "Half Width Katakana", input: "ｽﾊﾟﾑ", expected: "すぱむ"},
+		{name: "Katakana To Hiragana", input: "スパム", expected: "すぱむ"},
+		{name: "Small Kana", input: "ァヵヶ", expected: "ぁゕゖ"},
+		{name: "Hiragana Unchanged", input: "すぱむ", expected: "すぱむ"},
+		{name: "Kanji Unchanged", input: "迷惑", expected: "迷惑"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, models.FoldText(tt.input))
+		})
+	}
+}
+
+func TestWordFilter(t *testing.T) {
+	filter, err := models.ParseWordFilter(strings.NewReader(`
+# コメント
+スパム
+flag:ｶｼﾞﾉ
+shadow_hide:Free Money
+reject:ngword
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, filter.Len())
+
+	tests := []struct {
+		name           string
+		texts          []string
+		expectedAction string
+		expectedDetail string
+	}{
+		{name: "Clean", texts: []string{"こんにちは", "いい天気"}, expectedAction: models.ContentActionAllow},
+		{name: "Hiragana Matches Katakana", texts: []string{"すぱむです"}, expectedAction: models.ContentActionReject, expectedDetail: "スパム"},
+		{name: "Full Width Matches Half Width", texts: []string{"オンラインカジノ"}, expectedAction: models.ContentActionFlag, expectedDetail: "ｶｼﾞﾉ"},
+		{name: "Case Insensitive In Body", texts: []string{"title", "get FREE MONEY now"}, expectedAction: models.ContentActionShadowHide, expectedDetail: "Free Money"},
+		{name: "Strictest Wins", texts: []string{"かじの", "ＮＧＷＯＲＤ"}, expectedAction: models.ContentActionReject, expectedDetail: "ngword"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := filter.Match(tt.texts...)
+			assert.Equal(t, tt.expectedAction, verdict.Action)
+			assert.Equal(t, tt.expectedDetail, verdict.Detail)
+			if tt.expectedAction != models.ContentActionAllow {
+				assert.Equal(t, models.ContentRuleBannedWord, verdict.Rule)
+			}
+		})
+	}
+
+	_, err = models.ParseWordFilter(strings.NewReader("allow:word"))
+	assert.Error(t, err)
+
+	// 判定名でない接頭辞は語の一部として扱う
+	filter, err = models.ParseWordFilter(strings.NewReader("note:spam"))
+	assert.NoError(t, err)
+	assert.Equal(t, models.ContentActionReject, filter.Match("NOTE:SPAM").Action)
+}
+
+func TestCountLinks(t *testing.T) {
+	assert.Equal(t, 0, models.CountLinks("no links here", "example.com"))
+	assert.Equal(t, 3, models.CountLinks("see https://a.example/x and http://b.example", "www.c.example"))
+	assert.Equal(t, 1, models.CountLinks("ｈｔｔｐｓ：／／ｅｘａｍｐｌｅ．ｃｏｍ"))
+}
+
+func TestContentVerdict(t *testing.T) {
+	allow := models.ContentVerdict{Action: models.ContentActionAllow}
+	flag := models.ContentVerdict{Action: models.ContentActionFlag}
+	reject := models.ContentVerdict{Action: models.ContentActionReject}
+
+	assert.True(t, allow.Allowed())
+	assert.True(t, models.ContentVerdict{}.Allowed())
+	assert.False(t, flag.Allowed())
+	assert.True(t, reject.StricterThan(flag))
+	assert.True(t, flag.StricterThan(allow))
+	assert.False(t, allow.StricterThan(flag))
+}
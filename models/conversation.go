@@ -0,0 +1,204 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 会話の上限
+const (
+	MaxConversationParticipants = 10
+	MessageBodyMaxLength        = 2000
+)
+
+// 会話・メッセージの検証エラー
+var (
+	ErrInvalidParticipants = errors.New("invalid participants")
+	ErrMessageBodyRequired = errors.New("message body is required")
+	ErrMessageBodyTooLong  = errors.New("message body is too long")
+)
+
+// Conversation モデル定義（1対1 または少人数のグループ）
+//
+// DirectKey は1対1の会話で "小さい方のユーザーID:大きい方のユーザーID" を持ち、同じ2人の会話を1つにする。
+// グループでは NULL。CreatedByID は作成者で、作成者が完全に削除されると NULL になる（会話と他の参加者のメッセージは残す）。
+type Conversation struct {
+	ID            uint                      `json:"id" gorm:"primaryKey"`
+	IsGroup       bool                      `json:"is_group" gorm:"not null;default:false"`
+	Title         string                    `json:"title" gorm:"size:100;not null;default:''"`
+	DirectKey     *string                   `json:"-" gorm:"size:50;uniqueIndex"`
+	CreatedByID   *uint                     `json:"created_by_id" gorm:"index"`
+	CreatedBy     *User                     `json:"-" gorm:"foreignKey:CreatedByID;references:ID;constraint:OnDelete:SET NULL"`
+	LastMessageAt *time.Time                `json:"last_message_at" gorm:"index"`
+	Participants  []ConversationParticipant `json:"-" gorm:"foreignKey:ConversationID;constraint:OnDelete:CASCADE"`
+	CreatedAt     time.Time                 `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt     time.Time                 `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP;autoUpdateTime"`
+	Stats         ConversationStats         `json:"-" gorm:"-"`
+}
+
+// ConversationStats は閲覧者ごとの付加情報（DB には保存しない）
+type ConversationStats struct {
+	UnreadCount int64
+	LastMessage *Message
+}
+
+// ConversationParticipant モデル定義（LastReadMessageID までのメッセージを既読とする）
+type ConversationParticipant struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	ConversationID    uint      `json:"conversation_id" gorm:"not null;uniqueIndex:idx_participants_conversation_user"`
+	UserID            uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_participants_conversation_user;index"`
+	User              User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	LastReadMessageID uint      `json:"last_read_message_id" gorm:"not null;default:0"`
+	CreatedAt         time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// Message モデル定義
+type Message struct {
+	ID             uint         `json:"id" gorm:"primaryKey"`
+	ConversationID uint         `json:"conversation_id" gorm:"not null;index"`
+	Conversation   Conversation `json:"-" gorm:"foreignKey:ConversationID;references:ID;constraint:OnDelete:CASCADE"`
+	SenderID       uint         `json:"sender_id" gorm:"not null"`
+	Sender         User         `json:"-" gorm:"foreignKey:SenderID;references:ID;constraint:OnDelete:CASCADE"`
+	Body           string       `json:"body" gorm:"type:text;not null"`
+	CreatedAt      time.Time    `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// DirectConversationKey は2人のユーザーの1対1の会話を表すキーを返す
+func DirectConversationKey(a, b uint) string {
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%d:%d", a, b)
+}
+
+// ConversationRequest は会話の開始リクエスト用の構造体（相手が1人なら1対1、2人以上ならグループ）
+type ConversationRequest struct {
+	ParticipantIDs []uint `json:"participant_ids" binding:"required,min=1" example:"2"`
+	Title          string `json:"title" binding:"omitempty,max=100" example:"週末の予定"`
+}
+
+// Normalize は自分自身と重複を除いた相手の ID を昇順に並べ、タイトルを正規化する
+func (r *ConversationRequest) Normalize(userID uint) {
+	seen := map[uint]bool{userID: true}
+	ids := make([]uint, 0, len(r.ParticipantIDs))
+	for _, id := range r.ParticipantIDs {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	r.ParticipantIDs = ids
+	r.Title = NormalizeText(r.Title, false)
+}
+
+// Validate は正規化後の相手の人数を検証する（自分を含めて MaxConversationParticipants 人まで）
+func (r *ConversationRequest) Validate() error {
+	if len(r.ParticipantIDs) == 0 || len(r.ParticipantIDs)+1 > MaxConversationParticipants {
+		return ErrInvalidParticipants
+	}
+	return nil
+}
+
+// MessageRequest はメッセージ送信リクエスト用の構造体
+type MessageRequest struct {
+	Body string `json:"body" binding:"required" example:"こんにちは"`
+}
+
+// Normalize は本文を保存用に正規化する
+func (r *MessageRequest) Normalize() {
+	r.Body = NormalizeText(r.Body, true)
+}
+
+// Validate は正規化後の本文を検証する
+func (r *MessageRequest) Validate() error {
+	if r.Body == "" {
+		return ErrMessageBodyRequired
+	}
+	if CharCount(r.Body) > MessageBodyMaxLength {
+		return ErrMessageBodyTooLong
+	}
+	return nil
+}
+
+// ReadRequest は既読位置の更新リクエスト用の構造体（MessageID を省略すると最新のメッセージまで既読）
+type ReadRequest struct {
+	MessageID uint `json:"message_id" example:"120"`
+}
+
+// MessageResponse はメッセージのレスポンス用の構造体
+type MessageResponse struct {
+	ID             uint         `json:"id" example:"120"`
+	ConversationID uint         `json:"conversation_id" example:"1"`
+	Sender         UserResponse `json:"sender"`
+	Body           string       `json:"body" example:"こんにちは"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// ToResponse は Message モデルを MessageResponse に変換する
+func (m *Message) ToResponse() MessageResponse {
+	return MessageResponse{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		Sender:         m.Sender.ToResponse(),
+		Body:           m.Body,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+// ParticipantResponse は参加者と既読位置（既読表示に使う）のレスポンス構造体
+type ParticipantResponse struct {
+	UserResponse
+	LastReadMessageID uint `json:"last_read_message_id" example:"118"`
+}
+
+// ConversationResponse は会話のレスポンス用の構造体（unread_count は閲覧者の未読数）
+type ConversationResponse struct {
+	ID            uint                  `json:"id" example:"1"`
+	IsGroup       bool                  `json:"is_group" example:"false"`
+	Title         string                `json:"title" example:""`
+	Participants  []ParticipantResponse `json:"participants"`
+	LastMessage   *MessageResponse      `json:"last_message"`
+	UnreadCount   int64                 `json:"unread_count" example:"3"`
+	LastMessageAt *time.Time            `json:"last_message_at"`
+	CreatedAt     time.Time             `json:"created_at"`
+}
+
+// ToResponse は Conversation モデルを ConversationResponse に変換する
+func (c *Conversation) ToResponse() ConversationResponse {
+	response := ConversationResponse{
+		ID:            c.ID,
+		IsGroup:       c.IsGroup,
+		Title:         c.Title,
+		Participants:  make([]ParticipantResponse, 0, len(c.Participants)),
+		UnreadCount:   c.Stats.UnreadCount,
+		LastMessageAt: c.LastMessageAt,
+		CreatedAt:     c.CreatedAt,
+	}
+	for _, p := range c.Participants {
+		response.Participants = append(response.Participants, ParticipantResponse{
+			UserResponse:      p.User.ToResponse(),
+			LastReadMessageID: p.LastReadMessageID,
+		})
+	}
+	if c.Stats.LastMessage != nil {
+		last := c.Stats.LastMessage.ToResponse()
+		response.LastMessage = &last
+	}
+	return response
+}
+
+// ConversationListResponse はページネーション付きの会話一覧レスポンス構造体（unread_count は全会話の未読数の合計）
+type ConversationListResponse struct {
+	Conversations []ConversationResponse `json:"conversations"`
+	UnreadCount   int64                  `json:"unread_count" example:"5"`
+	PageMeta
+}
+
+// MessageListResponse はカーソル方式のメッセージ一覧レスポンス構造体（新しい順）
+type MessageListResponse struct {
+	Messages []MessageResponse `json:"messages"`
+	CursorMeta
+}
@@ -0,0 +1,58 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"go-gin-gorm-minimum/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversationRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		ids         []uint
+		expectedIDs []uint
+		expectedErr error
+	}{
+		{name: "Direct", ids: []uint{7}, expectedIDs: []uint{7}},
+		{name: "Drops Self And Duplicates", ids: []uint{9, 1, 7, 9, 0}, expectedIDs: []uint{7, 9}},
+		{name: "Only Self", ids: []uint{1}, expectedIDs: []uint{}, expectedErr: models.ErrInvalidParticipants},
+		{name: "Max Group", ids: []uint{2, 3, 4, 5, 6, 7, 8, 9, 10}, expectedIDs: []uint{2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{name: "Too Many", ids: []uint{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, expectedIDs: []uint{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, expectedErr: models.ErrInvalidParticipants},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := models.ConversationRequest{ParticipantIDs: tt.ids}
+			req.Normalize(1)
+			assert.Equal(t, tt.expectedIDs, req.ParticipantIDs)
+			assert.Equal(t, tt.expectedErr, req.Validate())
+		})
+	}
+
+	assert.Equal(t, "3:7", models.DirectConversationKey(7, 3))
+	assert.Equal(t, models.DirectConversationKey(3, 7), models.DirectConversationKey(7, 3))
+}
+
+func TestMessageRequestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		expectedErr error
+	}{
+		{name: "Valid", body: "こんにちは"},
+		{name: "Blank", body: " \n ", expectedErr: models.ErrMessageBodyRequired},
+		{name: "Max Length", body: strings.Repeat("あ", models.MessageBodyMaxLength)},
+		{name: "Too Long", body: strings.Repeat("あ", models.MessageBodyMaxLength+1), expectedErr: models.ErrMessageBodyTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := models.MessageRequest{Body: tt.body}
+			req.Normalize()
+			assert.Equal(t, tt.expectedErr, req.Validate())
+		})
+	}
+}
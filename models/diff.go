@@ -0,0 +1,111 @@
+package models
+
+// 差分の区間の種類
+const (
+	DiffEqual  = "equal"  // 変更なし
+	DiffInsert = "insert" // 追加
+	DiffDelete = "delete" // 削除
+)
+
+// diffMaxCells は LCS の表の最大サイズ（超えると変更箇所全体を削除と追加として扱う）
+const diffMaxCells = 1 << 20
+
+// DiffSegment は差分の1区間
+type DiffSegment struct {
+	Op   string `json:"op" example:"insert"`
+	Text string `json:"text" example:"追記"`
+}
+
+// Diff は oldText から newText への文字（コードポイント）単位の差分を返す。
+// 同じ種類の連続した区間はまとめ、変更箇所では削除を追加より先に並べる。
+// equal と delete をつなぐと oldText に、equal と insert をつなぐと newText になる。
+func Diff(oldText, newText string) []DiffSegment {
+	a, b := []rune(oldText), []rune(newText)
+
+	// 共通の先頭と末尾は LCS の計算から除く（編集は一部分だけのことが多い）
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var d differ
+	d.add(DiffEqual, a[:prefix])
+	d.diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	d.add(DiffEqual, a[len(a)-suffix:])
+	if d.segments == nil {
+		return []DiffSegment{}
+	}
+	return d.segments
+}
+
+type differ struct {
+	segments []DiffSegment
+}
+
+// add は区間を追加する（直前と同じ種類ならつなげる）
+func (d *differ) add(op string, text []rune) {
+	if len(text) == 0 {
+		return
+	}
+	if n := len(d.segments); n > 0 && d.segments[n-1].Op == op {
+		d.segments[n-1].Text += string(text)
+		return
+	}
+	d.segments = append(d.segments, DiffSegment{Op: op, Text: string(text)})
+}
+
+// diffMiddle は最長共通部分列（LCS）をもとに a から b への差分を追加する
+func (d *differ) diffMiddle(a, b []rune) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 || (n+1)*(m+1) > diffMaxCells {
+		d.add(DiffDelete, a)
+		d.add(DiffInsert, b)
+		return
+	}
+
+	// lcs[i*(m+1)+j] は a[i:] と b[j:] の LCS の長さ
+	lcs := make([]int32, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			case lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]:
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j]
+			default:
+				lcs[i*(m+1)+j] = lcs[i*(m+1)+j+1]
+			}
+		}
+	}
+
+	// 変更箇所の削除と追加をまとめてから出力する
+	var deleted, inserted []rune
+	flush := func() {
+		d.add(DiffDelete, deleted)
+		d.add(DiffInsert, inserted)
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			flush()
+			d.add(DiffEqual, a[i:i+1])
+			i++
+			j++
+		case lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]:
+			deleted = append(deleted, a[i])
+			i++
+		default:
+			inserted = append(inserted, b[j])
+			j++
+		}
+	}
+	deleted = append(deleted, a[i:]...)
+	inserted = append(inserted, b[j:]...)
+	flush()
+}
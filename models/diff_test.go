@@ -0,0 +1,106 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"go-gin-gorm-minimum/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected []models.DiffSegment
+	}{
+		{name: "Identical", old: "hello", new: "hello", expected: []models.DiffSegment{{Op: models.DiffEqual, Text: "hello"}}},
+		{name: "Both Empty", old: "", new: "", expected: []models.DiffSegment{}},
+		{name: "From Empty", old: "", new: "hello", expected: []models.DiffSegment{{Op: models.DiffInsert, Text: "hello"}}},
+		{name: "To Empty", old: "hello", new: "", expected: []models.DiffSegment{{Op: models.DiffDelete, Text: "hello"}}},
+		{
+			name: "Append",
+			old:  "hello",
+			new:  "hello world",
+			expected: []models.DiffSegment{
+				{Op: models.DiffEqual, Text: "hello"},
+				{Op: models.DiffInsert, Text: " world"},
+			},
+		},
+		{
+			name: "Replace Middle",
+			old:  "今日は晴れです",
+			new:  "今日は雨です",
+			expected: []models.DiffSegment{
+				{Op: models.DiffEqual, Text: "今日は"},
+				{Op: models.DiffDelete, Text: "晴れ"},
+				{Op: models.DiffInsert, Text: "雨"},
+				{Op: models.DiffEqual, Text: "です"},
+			},
+		},
+		{
+			name: "Multiple Changes",
+			old:  "abcdef",
+			new:  "axcdyf",
+			expected: []models.DiffSegment{
+				{Op: models.DiffEqual, Text: "a"},
+				{Op: models.DiffDelete, Text: "b"},
+				{Op: models.DiffInsert, Text: "x"},
+				{Op: models.DiffEqual, Text: "cd"},
+				{Op: models.DiffDelete, Text: "e"},
+				{Op: models.DiffInsert, Text: "y"},
+				{Op: models.DiffEqual, Text: "f"},
+			},
+		},
+		{
+			name: "Emoji",
+			old:  "いいね👍",
+			new:  "いいね🎉",
+			expected: []models.DiffSegment{
+				{Op: models.DiffEqual, Text: "いいね"},
+				{Op: models.DiffDelete, Text: "👍"},
+				{Op: models.DiffInsert, Text: "🎉"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, models.Diff(tt.old, tt.new))
+		})
+	}
+}
+
+// TestDiffReconstructs は差分から変更前と変更後のテキストを組み立て直せることを確認する
+func TestDiffReconstructs(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+	}{
+		{name: "Rewrite", old: "the quick brown fox", new: "a slow brown dog jumps"},
+		{name: "Japanese", old: "マイクロポストの本文を書く", new: "本文をマイクロポストに書き直す"},
+		{name: "Multiline", old: "1行目\n2行目\n3行目", new: "1行目\n3行目\n4行目"},
+		// LCS の表が大きすぎる場合は変更箇所全体を削除と追加にする
+		{name: "Too Large", old: "x" + strings.Repeat("ab", 1200) + "y", new: "x" + strings.Repeat("ba", 1200) + "y"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before, after strings.Builder
+			for _, segment := range models.Diff(tt.old, tt.new) {
+				assert.NotEmpty(t, segment.Text)
+				if segment.Op != models.DiffInsert {
+					before.WriteString(segment.Text)
+				}
+				if segment.Op != models.DiffDelete {
+					after.WriteString(segment.Text)
+				}
+			}
+			assert.Equal(t, tt.old, before.String())
+			assert.Equal(t, tt.new, after.String())
+		})
+	}
+}
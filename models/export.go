@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// データエクスポートの状態（pending は受付済み、running は作成中、completed はダウンロード可能、
+// failed は作成に失敗、expired はダウンロード期限を過ぎてアーカイブを削除済み）
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+	ExportStatusExpired   = "expired"
+)
+
+// ExportTTL は作成したアーカイブをダウンロードできる期間（起動時に設定で上書きされる）
+var ExportTTL = 7 * 24 * time.Hour
+
+// DataExport モデル定義（ユーザーが保有データ一式を ZIP でダウンロードするための非同期ジョブ）
+type DataExport struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	User        User       `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	Status      string     `json:"status" gorm:"size:16;not null;default:'pending';index"`
+	FilePath    string     `json:"-" gorm:"not null;default:''"`
+	Size        int64      `json:"size" gorm:"not null;default:0"`
+	Error       string     `json:"-" gorm:"type:text;not null;default:''"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP;autoUpdateTime"`
+}
+
+// Downloadable はアーカイブがまだダウンロードできるかどうかを返す
+func (e *DataExport) Downloadable(now time.Time) bool {
+	return e.Status == ExportStatusCompleted && e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+}
+
+// DataExportResponse はデータエクスポートのレスポンス構造体（DownloadURL はダウンロードできる間だけ返す）
+type DataExportResponse struct {
+	ID          uint       `json:"id" example:"1"`
+	Status      string     `json:"status" example:"completed"`
+	Size        int64      `json:"size" example:"20480"`
+	DownloadURL string     `json:"download_url,omitempty" example:"/api/v1/exports/1/download"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" example:"2024-11-09T18:00:00+09:00"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" example:"2024-11-16T18:00:00+09:00"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-11-09T18:00:00+09:00"`
+}
+
+// ToResponse は DataExport モデルを DataExportResponse に変換する
+func (e *DataExport) ToResponse() DataExportResponse {
+	response := DataExportResponse{
+		ID:          e.ID,
+		Status:      e.Status,
+		Size:        e.Size,
+		CompletedAt: e.CompletedAt,
+		ExpiresAt:   e.ExpiresAt,
+		CreatedAt:   e.CreatedAt,
+	}
+	if e.Downloadable(time.Now()) {
+		response.DownloadURL = fmt.Sprintf("/api/v1/exports/%d/download", e.ID)
+	}
+	return response
+}
+
+// DataExportListResponse はページネーション付きのデータエクスポート一覧レスポンス構造体
+type DataExportListResponse struct {
+	Exports []DataExportResponse `json:"exports"`
+	PageMeta
+}
@@ -0,0 +1,39 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataExportDownloadable(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		export   models.DataExport
+		expected bool
+	}{
+		{name: "Pending", export: models.DataExport{ID: 1, Status: models.ExportStatusPending}, expected: false},
+		{name: "Completed", export: models.DataExport{ID: 1, Status: models.ExportStatusCompleted, ExpiresAt: &future}, expected: true},
+		{name: "Past Expiry", export: models.DataExport{ID: 1, Status: models.ExportStatusCompleted, ExpiresAt: &past}, expected: false},
+		{name: "Expired", export: models.DataExport{ID: 1, Status: models.ExportStatusExpired, ExpiresAt: &future}, expected: false},
+		{name: "Failed", export: models.DataExport{ID: 1, Status: models.ExportStatusFailed}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.export.Downloadable(now))
+			if tt.expected {
+				assert.Equal(t, "/api/v1/exports/1/download", tt.export.ToResponse().DownloadURL)
+			} else {
+				assert.Empty(t, tt.export.ToResponse().DownloadURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Follow モデル定義（FollowerID が FollowedID をフォローする、組み合わせは一意）
+type Follow struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	FollowerID uint      `json:"follower_id" gorm:"not null;uniqueIndex:idx_follows_follower_followed"`
+	Follower   User      `json:"-" gorm:"foreignKey:FollowerID;references:ID;constraint:OnDelete:CASCADE"`
+	FollowedID uint      `json:"followed_id" gorm:"not null;uniqueIndex:idx_follows_follower_followed;index"`
+	Followed   User      `json:"-" gorm:"foreignKey:FollowedID;references:ID;constraint:OnDelete:CASCADE"`
+	CreatedAt  time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
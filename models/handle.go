@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ハンドル（@handle）の長さ
+const (
+	HandleMinLength = 3
+	HandleMaxLength = 30
+)
+
+// ErrInvalidHandle はハンドルの形式が不正なときのエラー
+var ErrInvalidHandle = errors.New("invalid handle")
+
+// binding タグの "handle" でハンドルの形式を検証する
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterValidation("handle", func(fl validator.FieldLevel) bool {
+			return IsValidHandle(fl.Field().String())
+		})
+	}
+}
+
+// IsValidHandle はハンドルが半角英数字とアンダースコアの3〜30文字で、数字だけでないかを返す
+func IsValidHandle(handle string) bool {
+	if len(handle) < HandleMinLength || len(handle) > HandleMaxLength {
+		return false
+	}
+	runes := []rune(handle)
+	for _, r := range runes {
+		if !isHandleRune(r) {
+			return false
+		}
+	}
+	return !isAllDigits(runes)
+}
+
+// HandleKey はハンドルの比較用のキー（大文字小文字を区別しない）
+func HandleKey(handle string) string {
+	return strings.ToLower(strings.TrimLeft(handle, "@＠"))
+}
+
+// HandleFromEmail はメールアドレスのローカル部からハンドルの候補を作る
+func HandleFromEmail(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+	var b strings.Builder
+	for _, r := range strings.ToLower(local) {
+		if isHandleRune(r) {
+			b.WriteRune(r)
+		}
+	}
+	handle := b.String()
+	if len(handle) > HandleMaxLength-4 {
+		handle = handle[:HandleMaxLength-4]
+	}
+	if !IsValidHandle(handle) {
+		handle = "user" + handle
+	}
+	return handle
+}
+
+func isHandleRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ハッシュタグの上限
+const (
+	MaxHashtagsPerMicropost = 30
+	MaxHashtagLength        = 100
+)
+
+// TextEntity はテキスト中のハッシュタグ・メンションの位置（Unicode 文字単位、End は含まない）
+type TextEntity struct {
+	Field string `json:"field" example:"body"`
+	Start int    `json:"start" example:"5"`
+	End   int    `json:"end" example:"10"`
+	Text  string `json:"text" example:"日本語"`
+}
+
+// NormalizeTagName はタグ名を比較・保存用に正規化する（先頭の #、全角/半角の揺れ、大文字小文字）
+func NormalizeTagName(name string) string {
+	name = strings.TrimLeft(strings.TrimSpace(name), "#＃")
+	return strings.ToLower(norm.NFKC.String(name))
+}
+
+// ExtractHashtags はテキストから正規化済みのハッシュタグを出現順・重複なしで取り出す
+func ExtractHashtags(texts ...string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, text := range texts {
+		for _, entity := range FindHashtags("", text) {
+			name := NormalizeTagName(entity.Text)
+			if seen[name] || utf8.RuneCountInString(name) > MaxHashtagLength {
+				continue
+			}
+			seen[name] = true
+			tags = append(tags, name)
+			if len(tags) == MaxHashtagsPerMicropost {
+				return tags
+			}
+		}
+	}
+	return tags
+}
+
+// FindHashtags は #タグ の位置を返す（Text は # を除いたタグ名）。
+// 英数字の直後の # （例: "C#"、URL のフラグメント）はタグとみなさない。
+func FindHashtags(field, text string) []TextEntity {
+	return findPrefixed(field, text, func(r rune) bool { return r == '#' || r == '＃' }, isHashtagRune)
+}
+
+func isHashtagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r) || r == '_'
+}
+
+// findPrefixed は接頭辞の記号に続く語を探す
+func findPrefixed(field, text string, isPrefix, isBodyRune func(rune) bool) []TextEntity {
+	runes := []rune(text)
+	var entities []TextEntity
+	for i := 0; i < len(runes); i++ {
+		if !isPrefix(runes[i]) {
+			continue
+		}
+		if i > 0 && (isBodyRune(runes[i-1]) || isPrefix(runes[i-1]) || runes[i-1] == '&' || runes[i-1] == '/') {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && isBodyRune(runes[j]) {
+			j++
+		}
+		// 数字だけのタグ（"#1" など）は除外する
+		if j > i+1 && !isAllDigits(runes[i+1:j]) {
+			entities = append(entities, TextEntity{Field: field, Start: i, End: j, Text: string(runes[i+1 : j])})
+		}
+		i = j - 1
+	}
+	return entities
+}
+
+func isAllDigits(runes []rune) bool {
+	for _, r := range runes {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
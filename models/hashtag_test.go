@@ -0,0 +1,45 @@
+package models_test
+
+import (
+	"testing"
+
+	"go-gin-gorm-minimum/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	tests := []struct {
+		name     string
+		texts    []string
+		expected []string
+	}{
+		{name: "Japanese", texts: []string{"今日は #日本語 の勉強"}, expected: []string{"日本語"}},
+		{name: "Full Width Hash And Case", texts: []string{"＃Go と #go と #ＧＯ"}, expected: []string{"go"}},
+		{name: "Title And Body", texts: []string{"#gin", "本文 #gorm #gin"}, expected: []string{"gin", "gorm"}},
+		{name: "Not A Tag", texts: []string{"C# と https://example.com/#top と &#39; と #123"}, expected: nil},
+		{name: "Underscore And Digits", texts: []string{"#go_1 #2024年"}, expected: []string{"go_1", "2024年"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, models.ExtractHashtags(tt.texts...))
+		})
+	}
+}
+
+func TestFindHashtags(t *testing.T) {
+	entities := models.FindHashtags("body", "こんにちは #世界 です")
+	assert.Equal(t, []models.TextEntity{{Field: "body", Start: 6, End: 9, Text: "世界"}}, entities)
+}
+
+func TestTrendingTagQueryDuration(t *testing.T) {
+	window, err := models.TrendingTagQuery{}.Duration()
+	assert.NoError(t, err)
+	assert.Equal(t, models.DefaultTrendingWindow, window)
+
+	for _, w := range []string{"abc", "-1h", "0s", "200h"} {
+		_, err := models.TrendingTagQuery{Window: w}.Duration()
+		assert.ErrorIs(t, err, models.ErrInvalidTrendingWindow, w)
+	}
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"time"
+)
+
+// ジョブの状態（pending は実行待ち、running は実行中、succeeded は完了、
+// dead は再試行の上限に達したか処理できない種類のジョブで、管理者が再実行するまで残す）
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusDead      = "dead"
+)
+
+// ジョブの既定値
+const (
+	DefaultJobQueue       = "default"
+	DefaultJobMaxAttempts = 5
+)
+
+// 再試行までの待ち時間（1回目の失敗で JobBackoffBase、以降は倍ずつ増やし JobBackoffMax で頭打ち）
+var (
+	JobBackoffBase = 10 * time.Second
+	JobBackoffMax  = time.Hour
+)
+
+// Job モデル定義（Postgres の jobs テーブルを使ったバックグラウンドジョブ）
+// Payload はジョブの種類ごとの JSON。UniqueKey が同じ未完了のジョブは1件しか登録されない。
+type Job struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Queue       string     `json:"queue" gorm:"size:64;not null;default:'default'"`
+	Type        string     `json:"type" gorm:"size:100;not null;index"`
+	Payload     string     `json:"payload" gorm:"type:jsonb;not null;default:'{}'"`
+	UniqueKey   *string    `json:"unique_key" gorm:"size:200"`
+	Status      string     `json:"status" gorm:"size:16;not null;default:'pending'"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"not null;default:5"`
+	RunAt       time.Time  `json:"run_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	LockedAt    *time.Time `json:"locked_at"`
+	LockedBy    string     `json:"locked_by" gorm:"size:200;not null;default:''"`
+	LastError   string     `json:"last_error" gorm:"type:text;not null;default:''"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP;autoUpdateTime"`
+}
+
+// LastAttempt は今回の実行が最後の試行かどうかを返す（失敗すると dead になる）
+func (j *Job) LastAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+// JobBackoff は attempt 回目の失敗の後、再試行するまでの待ち時間を返す
+func JobBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	backoff := JobBackoffBase
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= JobBackoffMax {
+			return JobBackoffMax
+		}
+	}
+	return min(backoff, JobBackoffMax)
+}
+
+// JobQuery は管理者向けジョブ一覧の絞り込み用クエリパラメータ（既定は dead）
+type JobQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending running succeeded dead" example:"dead"`
+	Type   string `form:"type" binding:"omitempty,max=100" example:"export.create"`
+	PageQuery
+}
+
+// JobResponse はジョブのレスポンス構造体
+type JobResponse struct {
+	ID          uint       `json:"id" example:"1"`
+	Queue       string     `json:"queue" example:"default"`
+	Type        string     `json:"type" example:"export.create"`
+	Payload     string     `json:"payload" example:"{\"export_id\":1}"`
+	Status      string     `json:"status" example:"dead"`
+	Attempts    int        `json:"attempts" example:"5"`
+	MaxAttempts int        `json:"max_attempts" example:"5"`
+	RunAt       time.Time  `json:"run_at" example:"2024-11-09T18:00:00+09:00"`
+	LastError   string     `json:"last_error" example:"connection refused"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty" example:"2024-11-09T18:00:00+09:00"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-11-09T18:00:00+09:00"`
+}
+
+// ToResponse は Job モデルを JobResponse に変換する
+func (j *Job) ToResponse() JobResponse {
+	return JobResponse{
+		ID:          j.ID,
+		Queue:       j.Queue,
+		Type:        j.Type,
+		Payload:     j.Payload,
+		Status:      j.Status,
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		RunAt:       j.RunAt,
+		LastError:   j.LastError,
+		FinishedAt:  j.FinishedAt,
+		CreatedAt:   j.CreatedAt,
+	}
+}
+
+// JobListResponse はページネーション付きのジョブ一覧レスポンス構造体
+type JobListResponse struct {
+	Jobs []JobResponse `json:"jobs"`
+	PageMeta
+}
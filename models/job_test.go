@@ -0,0 +1,49 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempt  int
+		expected time.Duration
+	}{
+		{name: "First Failure", attempt: 1, expected: models.JobBackoffBase},
+		{name: "Zero Attempts", attempt: 0, expected: models.JobBackoffBase},
+		{name: "Doubles", attempt: 2, expected: 2 * models.JobBackoffBase},
+		{name: "Doubles Again", attempt: 4, expected: 8 * models.JobBackoffBase},
+		{name: "Capped", attempt: 20, expected: models.JobBackoffMax},
+		{name: "Large Attempt", attempt: 1000, expected: models.JobBackoffMax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, models.JobBackoff(tt.attempt))
+		})
+	}
+}
+
+func TestJobLastAttempt(t *testing.T) {
+	tests := []struct {
+		name     string
+		job      models.Job
+		expected bool
+	}{
+		{name: "First Of Five", job: models.Job{Attempts: 1, MaxAttempts: 5}, expected: false},
+		{name: "Fifth Of Five", job: models.Job{Attempts: 5, MaxAttempts: 5}, expected: true},
+		{name: "Single Attempt", job: models.Job{Attempts: 1, MaxAttempts: 1}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.job.LastAttempt())
+		})
+	}
+}
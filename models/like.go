@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Like モデル定義（ユーザーとマイクロポストの組み合わせは一意）
+type Like struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_likes_user_micropost"`
+	User        User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	MicropostID uint      `json:"micropost_id" gorm:"not null;uniqueIndex:idx_likes_user_micropost;index"`
+	Micropost   Micropost `json:"-" gorm:"foreignKey:MicropostID;references:ID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// LikeStatusResponse はいいね/取り消し後の状態を返すレスポンス構造体
+type LikeStatusResponse struct {
+	MicropostID uint  `json:"micropost_id" example:"1"`
+	LikeCount   int64 `json:"like_count" example:"3"`
+	LikedByMe   bool  `json:"liked_by_me" example:"true"`
+}
+
+// UserListResponse はページネーション付きのユーザー一覧レスポンス構造体
+type UserListResponse struct {
+	Users []UserResponse `json:"users"`
+	PageMeta
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// MaxMentionsPerMicropost は1件の投稿で解決するメンション先ユーザーの上限
+const MaxMentionsPerMicropost = 10
+
+// Mention モデル定義（投稿作成・更新時に解決した @handle と、その位置）
+type Mention struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	MicropostID uint      `json:"micropost_id" gorm:"not null;index"`
+	Micropost   Micropost `json:"-" gorm:"foreignKey:MicropostID;references:ID;constraint:OnDelete:CASCADE"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	User        User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	Field       string    `json:"field" gorm:"size:16;not null"`
+	Start       int       `json:"start" gorm:"not null"`
+	End         int       `json:"end" gorm:"not null"`
+	Text        string    `json:"text" gorm:"size:30;not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// MentionEntity はレスポンスに含めるメンションの位置と解決先のユーザー
+type MentionEntity struct {
+	TextEntity
+	UserID uint `json:"user_id" example:"2"`
+}
+
+// FindMentions は @handle の位置を返す（Text は @ を除いたハンドル）。
+// メールアドレスのように英数字の直後にある @ はメンションとみなさない。
+func FindMentions(field, text string) []TextEntity {
+	var entities []TextEntity
+	for _, entity := range findPrefixed(field, text, func(r rune) bool { return r == '@' || r == '＠' }, isHandleRune) {
+		if IsValidHandle(entity.Text) {
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
+
+// ToEntity は Mention を MentionEntity に変換する
+func (m *Mention) ToEntity() MentionEntity {
+	return MentionEntity{
+		TextEntity: TextEntity{Field: m.Field, Start: m.Start, End: m.End, Text: m.Text},
+		UserID:     m.UserID,
+	}
+}
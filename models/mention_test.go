@@ -0,0 +1,34 @@
+package models_test
+
+import (
+	"testing"
+
+	"go-gin-gorm-minimum/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMentions(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []models.TextEntity
+	}{
+		{name: "Mention", text: "こんにちは @alice さん", expected: []models.TextEntity{{Field: "body", Start: 6, End: 12, Text: "alice"}}},
+		{name: "Full Width At", text: "＠Bob_1 へ", expected: []models.TextEntity{{Field: "body", Start: 0, End: 6, Text: "Bob_1"}}},
+		{name: "Email Is Not A Mention", text: "mail: foo@example.com", expected: nil},
+		{name: "Too Short Or Digits", text: "@ab @12345", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, models.FindMentions("body", tt.text))
+		})
+	}
+}
+
+func TestHandleFromEmail(t *testing.T) {
+	assert.Equal(t, "johndoe_", models.HandleFromEmail("John.Doe_@example.com"))
+	assert.Equal(t, "user42", models.HandleFromEmail("42@example.com"))
+	assert.True(t, models.IsValidHandle(models.HandleFromEmail("山田@example.com")))
+}
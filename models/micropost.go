@@ -1,38 +1,264 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MicropostBodyMaxLength は本文の最大文字数（Unicode 文字単位、起動時に設定で上書きされる）
+var MicropostBodyMaxLength = 1000
+
+// マイクロポストのバリデーションエラー
+var (
+	ErrMicropostTitleRequired = errors.New("micropost title is required")
+	ErrMicropostBodyTooLong   = errors.New("micropost body is too long")
+	ErrInvalidPublishAt       = errors.New("publish_at must be in the future")
+)
+
+// マイクロポストの種類
+const (
+	MicropostKindPost   = "post"   // 通常の投稿
+	MicropostKindRepost = "repost" // コメントなしの再投稿（ユーザーごとに1回まで）
+	MicropostKindQuote  = "quote"  // コメント付きの引用投稿
+)
+
+// マイクロポストの公開状態
+const (
+	MicropostStatusPublished = "published" // 公開済み
+	MicropostStatusDraft     = "draft"     // 下書き（投稿者本人の下書き一覧にだけ出る）
+	MicropostStatusScheduled = "scheduled" // 予約投稿（PublishAt を過ぎたら公開する）
+)
 
 // MicropostRequest はマイクロポスト作成リクエスト用の構造体
 type MicropostRequest struct {
 	Title string `json:"title" binding:"required" example:"マイクロポストのタイトル"`
+	Body  string `json:"body" example:"マイクロポストの本文"`
+}
+
+// Normalize はタイトルと本文を保存用に正規化する
+func (r *MicropostRequest) Normalize() {
+	r.Title = NormalizeText(r.Title, false)
+	r.Body = NormalizeText(r.Body, true)
+}
+
+// Validate は正規化後の内容を検証する（Normalize の後に呼ぶ）
+func (r *MicropostRequest) Validate() error {
+	if r.Title == "" {
+		return ErrMicropostTitleRequired
+	}
+	if CharCount(r.Body) > MicropostBodyMaxLength {
+		return ErrMicropostBodyTooLong
+	}
+	return nil
+}
+
+// DraftRequest は下書き・予約投稿の作成・更新リクエスト用の構造体
+// publish_at を指定すると予約投稿、省略すると下書きになる
+type DraftRequest struct {
+	Title     string     `json:"title" binding:"required" example:"マイクロポストのタイトル"`
+	Body      string     `json:"body" example:"マイクロポストの本文"`
+	PublishAt *time.Time `json:"publish_at" example:"2024-11-10T09:00:00+09:00"`
+}
+
+// Normalize はタイトルと本文を保存用に正規化する
+func (r *DraftRequest) Normalize() {
+	r.Title = NormalizeText(r.Title, false)
+	r.Body = NormalizeText(r.Body, true)
+}
+
+// Validate は正規化後の内容と公開予定の日時を検証する（Normalize の後に呼ぶ）
+func (r *DraftRequest) Validate(now time.Time) error {
+	if err := (&MicropostRequest{Title: r.Title, Body: r.Body}).Validate(); err != nil {
+		return err
+	}
+	if r.PublishAt != nil && !r.PublishAt.After(now) {
+		return ErrInvalidPublishAt
+	}
+	return nil
+}
+
+// Apply はタイトル・本文と公開状態をマイクロポストに反映する
+func (r *DraftRequest) Apply(micropost *Micropost) {
+	micropost.Title = r.Title
+	micropost.Body = r.Body
+	micropost.PublishAt = r.PublishAt
+	micropost.Status = MicropostStatusDraft
+	if r.PublishAt != nil {
+		micropost.Status = MicropostStatusScheduled
+	}
 }
 
 // Micropost モデル定義
+//
+// ParentID は返信先で、親が削除されても返信は残し NULL にする。
+// RepostOfID は再投稿・引用元で、元投稿が削除されると NULL になり、
+// コメントなしの再投稿は一覧から除外される。
+// HiddenAt はモデレーターが非表示にした日時、ShadowHidden は自動モデレーションで
+// 投稿者本人以外から隠した投稿（本人には通常どおり見える）。
+// Status が published 以外の下書き・予約投稿は投稿者本人の下書き一覧にだけ出る。
+// 予約投稿は PublishAt を過ぎると公開され、CreatedAt が公開した日時になる。
+// EditedAt は公開後にタイトルか本文を最後に編集した日時で、編集前の内容は MicropostRevision に残る。
 type Micropost struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Title     string    `json:"title" binding:"required" example:"マイクロポストのタイトル"`
-	UserID    uint      `json:"user_id" gorm:"not null"`
-	User      User      `json:"-" gorm:"foreignKey:UserID;references:ID"`
-	CreatedAt time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP;autoUpdateTime"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	Title        string         `json:"title" binding:"required" example:"マイクロポストのタイトル"`
+	Body         string         `json:"body" gorm:"type:text;not null;default:''" example:"マイクロポストの本文"`
+	UserID       uint           `json:"user_id" gorm:"not null"`
+	User         User           `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	ParentID     *uint          `json:"parent_id" gorm:"index"`
+	Parent       *Micropost     `json:"-" gorm:"foreignKey:ParentID;references:ID;constraint:OnDelete:SET NULL"`
+	Kind         string         `json:"kind" gorm:"size:16;not null;default:'post'" example:"post"`
+	RepostOfID   *uint          `json:"repost_of_id" gorm:"index"`
+	RepostOf     *Micropost     `json:"-" gorm:"foreignKey:RepostOfID;references:ID;constraint:OnDelete:SET NULL"`
+	Tags         []Tag          `json:"-" gorm:"many2many:micropost_tags;constraint:OnDelete:CASCADE"`
+	Mentions     []Mention      `json:"-" gorm:"foreignKey:MicropostID;constraint:OnDelete:CASCADE"`
+	HiddenAt     *time.Time     `json:"-" gorm:"index"`
+	ShadowHidden bool           `json:"-" gorm:"not null;default:false"`
+	Status       string         `json:"status" gorm:"size:16;not null;default:'published'" example:"published"`
+	PublishAt    *time.Time     `json:"publish_at"`
+	EditedAt     *time.Time     `json:"edited_at"`
+	CreatedAt    time.Time      `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt    time.Time      `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP;autoUpdateTime"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	Stats        MicropostStats `json:"-" gorm:"-"`
+}
+
+// MicropostStats は閲覧者ごとに集計する付加情報（DB には保存しない）
+// RepostCount はコメントなしの再投稿数、QuoteCount は引用数
+type MicropostStats struct {
+	LikeCount    int64
+	LikedByMe    bool
+	ReplyCount   int64
+	RepostCount  int64
+	QuoteCount   int64
+	RepostedByMe bool
 }
 
 // MicropostResponse はマイクロポストのレスポンス用の構造体
+// 再投稿（kind=repost）では user と reposted_by が再投稿したユーザー、repost_of が元投稿になる
+// ゴミ箱の投稿では deleted_at と完全に削除される purge_at が入る
+// 予約投稿では publish_at に公開予定の日時が入り、公開後に編集した投稿では edited_at に最後に編集した日時が入る
 type MicropostResponse struct {
-	ID        uint         `json:"id"`
-	Title     string       `json:"title"`
-	User      UserResponse `json:"user"`
-	CreatedAt time.Time    `json:"created_at"`
-	UpdatedAt time.Time    `json:"updated_at"`
+	ID           uint               `json:"id"`
+	Title        string             `json:"title"`
+	Body         string             `json:"body"`
+	User         UserResponse       `json:"user"`
+	ParentID     *uint              `json:"parent_id" example:"1"`
+	Kind         string             `json:"kind" example:"post"`
+	RepostOf     *MicropostResponse `json:"repost_of,omitempty"`
+	RepostedBy   *UserResponse      `json:"reposted_by,omitempty"`
+	Hashtags     []string           `json:"hashtags" example:"日本語"`
+	Mentions     []MentionEntity    `json:"mentions"`
+	ReplyCount   int64              `json:"reply_count" example:"2"`
+	LikeCount    int64              `json:"like_count" example:"3"`
+	LikedByMe    bool               `json:"liked_by_me" example:"false"`
+	RepostCount  int64              `json:"repost_count" example:"1"`
+	QuoteCount   int64              `json:"quote_count" example:"0"`
+	RepostedByMe bool               `json:"reposted_by_me" example:"false"`
+	HiddenAt     *time.Time         `json:"hidden_at,omitempty"`
+	Status       string             `json:"status" example:"published"`
+	PublishAt    *time.Time         `json:"publish_at,omitempty"`
+	EditedAt     *time.Time         `json:"edited_at,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	DeletedAt    *time.Time         `json:"deleted_at,omitempty"`
+	PurgeAt      *time.Time         `json:"purge_at,omitempty"`
 }
 
 // ToResponse は Micropost モデルを MicropostResponse に変換するヘルパー関数
 func (m *Micropost) ToResponse() MicropostResponse {
-	return MicropostResponse{
-		ID:        m.ID,
-		Title:     m.Title,
-		User:      m.User.ToResponse(),
-		CreatedAt: m.CreatedAt,
-		UpdatedAt: m.UpdatedAt,
+	response := MicropostResponse{
+		ID:           m.ID,
+		Title:        m.Title,
+		Body:         m.Body,
+		User:         m.User.ToResponse(),
+		ParentID:     m.ParentID,
+		Kind:         m.Kind,
+		ReplyCount:   m.Stats.ReplyCount,
+		LikeCount:    m.Stats.LikeCount,
+		LikedByMe:    m.Stats.LikedByMe,
+		RepostCount:  m.Stats.RepostCount,
+		QuoteCount:   m.Stats.QuoteCount,
+		RepostedByMe: m.Stats.RepostedByMe,
+		HiddenAt:     m.HiddenAt,
+		Status:       m.Status,
+		PublishAt:    m.PublishAt,
+		EditedAt:     m.EditedAt,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+	response.Hashtags = make([]string, 0, len(m.Tags))
+	for _, tag := range m.Tags {
+		response.Hashtags = append(response.Hashtags, tag.Name)
+	}
+	response.Mentions = make([]MentionEntity, 0, len(m.Mentions))
+	for _, mention := range m.Mentions {
+		response.Mentions = append(response.Mentions, mention.ToEntity())
+	}
+	if m.RepostOf != nil {
+		original := m.RepostOf.ToResponse()
+		response.RepostOf = &original
+	}
+	if m.Kind == MicropostKindRepost {
+		response.RepostedBy = &response.User
+	}
+	if m.DeletedAt.Valid {
+		deletedAt, purgeAt := m.DeletedAt.Time, PurgeAt(m.DeletedAt)
+		response.DeletedAt, response.PurgeAt = &deletedAt, &purgeAt
 	}
+	return response
+}
+
+// MicropostThread はスレッドの取得結果（service から handler へ渡す）
+type MicropostThread struct {
+	Micropost   Micropost
+	Ancestors   []Micropost
+	Descendants []ThreadNode
+	Total       int64
+}
+
+// ThreadNode はスレッドの子孫1件と起点からの深さ
+type ThreadNode struct {
+	Micropost Micropost
+	Depth     int
+}
+
+// RepostRequest は再投稿リクエスト用の構造体（タイトルも本文も空ならコメントなしの再投稿、あれば引用）
+type RepostRequest struct {
+	Title string `json:"title" example:"これは必読"`
+	Body  string `json:"body" example:"引用コメント"`
+}
+
+// Normalize はコメントを保存用に正規化する
+func (r *RepostRequest) Normalize() {
+	r.Title = NormalizeText(r.Title, false)
+	r.Body = NormalizeText(r.Body, true)
+}
+
+// Validate は正規化後のコメントを検証する
+func (r *RepostRequest) Validate() error {
+	if CharCount(r.Body) > MicropostBodyMaxLength {
+		return ErrMicropostBodyTooLong
+	}
+	return nil
+}
+
+// IsQuote はコメント付きの引用かどうかを返す
+func (r *RepostRequest) IsQuote() bool {
+	return r.Title != "" || r.Body != ""
+}
+
+// ThreadReply はスレッド内の返信（depth は起点の投稿からの深さ、直接の返信が 1）
+type ThreadReply struct {
+	MicropostResponse
+	Depth int `json:"depth" example:"1"`
+}
+
+// ThreadResponse はスレッド表示用のレスポンス構造体
+type ThreadResponse struct {
+	Ancestors   []MicropostResponse `json:"ancestors"`
+	Micropost   MicropostResponse   `json:"micropost"`
+	Descendants []ThreadReply       `json:"descendants"`
+	PageMeta
 }
@@ -0,0 +1,48 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDraftRequest(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Minute)
+
+	tests := []struct {
+		name           string
+		request        models.DraftRequest
+		expectedErr    error
+		expectedStatus string
+	}{
+		{name: "Draft", request: models.DraftRequest{Title: "Hello"}, expectedStatus: models.MicropostStatusDraft},
+		{name: "Scheduled", request: models.DraftRequest{Title: "Hello", PublishAt: &future}, expectedStatus: models.MicropostStatusScheduled},
+		{name: "Past", request: models.DraftRequest{Title: "Hello", PublishAt: &past}, expectedErr: models.ErrInvalidPublishAt},
+		{name: "Now", request: models.DraftRequest{Title: "Hello", PublishAt: &now}, expectedErr: models.ErrInvalidPublishAt},
+		{name: "Blank Title", request: models.DraftRequest{Title: " 　"}, expectedErr: models.ErrMicropostTitleRequired},
+		{name: "Body Too Long", request: models.DraftRequest{Title: "Hello", Body: strings.Repeat("あ", models.MicropostBodyMaxLength+1)}, expectedErr: models.ErrMicropostBodyTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.Normalize()
+			err := tt.request.Validate(now)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			if err != nil {
+				return
+			}
+
+			micropost := models.Micropost{Status: models.MicropostStatusScheduled, PublishAt: &future}
+			tt.request.Apply(&micropost)
+			assert.Equal(t, tt.expectedStatus, micropost.Status)
+			assert.Equal(t, tt.request.PublishAt, micropost.PublishAt)
+			assert.Equal(t, tt.request.Title, micropost.Title)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// 通知の種類
+const (
+	NotificationTypeMention = "mention"
+	NotificationTypeLike    = "like"
+	NotificationTypeFollow  = "follow"
+	NotificationTypeReply   = "reply"
+	NotificationTypeRepost  = "repost"
+	NotificationTypeQuote   = "quote"
+)
+
+// NotificationActorsShown は通知1件に表示するユーザーの上限（残りは人数だけ返す）
+const NotificationActorsShown = 3
+
+// Notification モデル定義（UserID が受信者、ActorID が通知のきっかけになったユーザー）
+//
+// MicropostID はいいね・返信・再投稿・引用では受信者の投稿、メンションではメンションした投稿。
+// 同じ GroupKey の通知は一覧で「○○さんと他2人が…」のようにまとめて表示する。
+type Notification struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index:idx_notifications_user_group"`
+	User        User       `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	ActorID     uint       `json:"actor_id" gorm:"not null"`
+	Actor       User       `json:"-" gorm:"foreignKey:ActorID;references:ID;constraint:OnDelete:CASCADE"`
+	Type        string     `json:"type" gorm:"size:32;not null"`
+	GroupKey    string     `json:"group_key" gorm:"size:100;not null;default:'';index:idx_notifications_user_group"`
+	MicropostID *uint      `json:"micropost_id" gorm:"index"`
+	Micropost   *Micropost `json:"-" gorm:"foreignKey:MicropostID;references:ID;constraint:OnDelete:CASCADE"`
+	ReadAt      *time.Time `json:"read_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"default:CURRENT_TIMESTAMP;index"`
+}
+
+// NewNotification は種類と対象の投稿から GroupKey を決めて通知を作る
+func NewNotification(notificationType string, userID, actorID uint, micropostID *uint) Notification {
+	groupKey := notificationType
+	if micropostID != nil {
+		groupKey = fmt.Sprintf("%s:%d", notificationType, *micropostID)
+	}
+	return Notification{
+		UserID:      userID,
+		ActorID:     actorID,
+		Type:        notificationType,
+		GroupKey:    groupKey,
+		MicropostID: micropostID,
+	}
+}
+
+// NotificationGroup は GroupKey と既読状態でまとめた通知（service から handler へ渡す）
+// ID はグループ内で最新の通知の ID
+type NotificationGroup struct {
+	ID          uint
+	GroupKey    string
+	Type        string
+	MicropostID *uint
+	Unread      bool
+	ActorCount  int64
+	CreatedAt   time.Time
+	Actors      []User `gorm:"-"`
+}
+
+// NotificationResponse は通知一覧の1件（まとめた通知）のレスポンス構造体
+type NotificationResponse struct {
+	ID          uint           `json:"id" example:"10"`
+	Type        string         `json:"type" example:"like"`
+	Message     string         `json:"message" example:"ユーザー1さんと他2人があなたの投稿にいいねしました"`
+	Actors      []UserResponse `json:"actors"`
+	ActorCount  int64          `json:"actor_count" example:"3"`
+	MicropostID *uint          `json:"micropost_id" example:"1"`
+	Unread      bool           `json:"unread" example:"true"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// ToResponse は NotificationGroup を表示用メッセージ付きのレスポンスに変換する
+func (g *NotificationGroup) ToResponse(message string) NotificationResponse {
+	response := NotificationResponse{
+		ID:          g.ID,
+		Type:        g.Type,
+		Message:     message,
+		Actors:      make([]UserResponse, 0, len(g.Actors)),
+		ActorCount:  g.ActorCount,
+		MicropostID: g.MicropostID,
+		Unread:      g.Unread,
+		CreatedAt:   g.CreatedAt,
+	}
+	for _, actor := range g.Actors {
+		response.Actors = append(response.Actors, actor.ToResponse())
+	}
+	return response
+}
+
+// NotificationListResponse はページネーション付きの通知一覧レスポンス構造体
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	UnreadCount   int64                  `json:"unread_count" example:"2"`
+	PageMeta
+}
+
+// UnreadCountResponse は未読の通知（まとめた単位）の件数のレスポンス構造体
+type UnreadCountResponse struct {
+	UnreadCount int64 `json:"unread_count" example:"2"`
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"time"
+)
+
+// ドメインイベントの種類（<集約>.<出来事> の形式）
+const (
+	EventUserSignedUp     = "user.signed_up"
+	EventUserDeleted      = "user.deleted"
+	EventMicropostCreated = "micropost.created"
+	EventMicropostDeleted = "micropost.deleted"
+	EventMicropostLiked   = "micropost.liked"
+	EventUserFollowed     = "user.followed"
+)
+
+// EventTypes はドメインイベントの種類の一覧
+var EventTypes = []string{
+	EventUserSignedUp,
+	EventUserDeleted,
+	EventMicropostCreated,
+	EventMicropostDeleted,
+	EventMicropostLiked,
+	EventUserFollowed,
+}
+
+// ValidEventType は種類がドメインイベントの種類かどうかを返す
+func ValidEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// DomainEvent は変更と同じトランザクションでアウトボックスに書き込むイベント。
+// JSON にしたものが OutboxEvent.Payload になる。
+type DomainEvent interface {
+	EventType() string
+	// AggregateID はイベントが起きた集約（ユーザー・投稿）の ID
+	AggregateID() uint
+}
+
+// UserSignedUp はユーザーが登録したときのイベント（メールアドレスは外部に送らない）
+type UserSignedUp struct {
+	UserID uint   `json:"user_id"`
+	Handle string `json:"handle"`
+}
+
+func (e UserSignedUp) EventType() string { return EventUserSignedUp }
+func (e UserSignedUp) AggregateID() uint { return e.UserID }
+
+// UserDeleted はユーザーが退会したか管理者に削除されたときのイベント
+type UserDeleted struct {
+	UserID uint `json:"user_id"`
+}
+
+func (e UserDeleted) EventType() string { return EventUserDeleted }
+func (e UserDeleted) AggregateID() uint { return e.UserID }
+
+// MicropostCreated は投稿（返信・再投稿・引用を含む）を作成したときのイベント。
+// 自動モデレーションで隠した投稿では発行しない。
+type MicropostCreated struct {
+	MicropostID uint   `json:"micropost_id"`
+	UserID      uint   `json:"user_id"`
+	Kind        string `json:"kind"`
+	ParentID    *uint  `json:"parent_id,omitempty"`
+	RepostOfID  *uint  `json:"repost_of_id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Body        string `json:"body,omitempty"`
+}
+
+func (e MicropostCreated) EventType() string { return EventMicropostCreated }
+func (e MicropostCreated) AggregateID() uint { return e.MicropostID }
+
+// MicropostDeleted は投稿を削除したときのイベント
+type MicropostDeleted struct {
+	MicropostID uint `json:"micropost_id"`
+	UserID      uint `json:"user_id"`
+}
+
+func (e MicropostDeleted) EventType() string { return EventMicropostDeleted }
+func (e MicropostDeleted) AggregateID() uint { return e.MicropostID }
+
+// MicropostLiked は投稿にいいねしたときのイベント（UserID がいいねしたユーザー、AuthorID が投稿者）
+type MicropostLiked struct {
+	MicropostID uint `json:"micropost_id"`
+	UserID      uint `json:"user_id"`
+	AuthorID    uint `json:"author_id"`
+}
+
+func (e MicropostLiked) EventType() string { return EventMicropostLiked }
+func (e MicropostLiked) AggregateID() uint { return e.MicropostID }
+
+// UserFollowed はユーザーをフォローしたときのイベント
+type UserFollowed struct {
+	FollowerID uint `json:"follower_id"`
+	FollowedID uint `json:"followed_id"`
+}
+
+func (e UserFollowed) EventType() string { return EventUserFollowed }
+func (e UserFollowed) AggregateID() uint { return e.FollowedID }
+
+// OutboxEvent モデル定義（トランザクショナルアウトボックス）
+// ID の順にコミットされるとは限らないので、書き込んだトランザクションの ID（TxID）と ID の順に読む。
+// 購読者は処理済みの最後の位置（OutboxOffset）から、実行中のトランザクションより古いイベントだけを読む。
+type OutboxEvent struct {
+	ID          uint      `json:"id" gorm:"primaryKey;index:idx_outbox_events_position,priority:2"`
+	TxID        uint64    `json:"-" gorm:"not null;default:0;index:idx_outbox_events_position,priority:1"`
+	Type        string    `json:"type" gorm:"size:100;not null;index"`
+	AggregateID uint      `json:"aggregate_id" gorm:"not null"`
+	Payload     string    `json:"payload" gorm:"type:jsonb;not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP;index"`
+}
+
+// OutboxOffset モデル定義（購読者ごとに処理済みの最後のイベントの TxID と ID）
+type OutboxOffset struct {
+	Consumer  string    `json:"consumer" gorm:"primaryKey;size:100"`
+	TxID      uint64    `json:"tx_id" gorm:"not null;default:0"`
+	EventID   uint      `json:"event_id" gorm:"not null;default:0"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP;autoUpdateTime"`
+}
@@ -0,0 +1,39 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go-gin-gorm-minimum/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainEvents(t *testing.T) {
+	parentID := uint(3)
+	tests := []struct {
+		name        string
+		event       models.DomainEvent
+		eventType   string
+		aggregateID uint
+		payload     string
+	}{
+		{name: "Signed Up", event: models.UserSignedUp{UserID: 1, Handle: "alice"}, eventType: models.EventUserSignedUp, aggregateID: 1, payload: `{"user_id":1,"handle":"alice"}`},
+		{name: "Reply", event: models.MicropostCreated{MicropostID: 5, UserID: 1, Kind: models.MicropostKindPost, ParentID: &parentID, Body: "hi"}, eventType: models.EventMicropostCreated, aggregateID: 5, payload: `{"micropost_id":5,"user_id":1,"kind":"post","parent_id":3,"body":"hi"}`},
+		{name: "Liked", event: models.MicropostLiked{MicropostID: 5, UserID: 2, AuthorID: 1}, eventType: models.EventMicropostLiked, aggregateID: 5, payload: `{"micropost_id":5,"user_id":2,"author_id":1}`},
+		{name: "Followed", event: models.UserFollowed{FollowerID: 2, FollowedID: 1}, eventType: models.EventUserFollowed, aggregateID: 1, payload: `{"follower_id":2,"followed_id":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.eventType, tt.event.EventType())
+			assert.True(t, models.ValidEventType(tt.event.EventType()))
+			assert.Equal(t, tt.aggregateID, tt.event.AggregateID())
+			payload, err := json.Marshal(tt.event)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.payload, string(payload))
+		})
+	}
+
+	assert.False(t, models.ValidEventType("user.unknown"))
+}
@@ -0,0 +1,68 @@
+package models
+
+// ページネーションの既定値
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+// PageQuery はページ番号方式のページネーション用クエリパラメータ
+type PageQuery struct {
+	Page    int `form:"page" binding:"omitempty,min=1" example:"1"`
+	PerPage int `form:"per_page" binding:"omitempty,min=1,max=100" example:"20"`
+}
+
+// Limit は1ページあたりの件数を返す
+func (q PageQuery) Limit() int {
+	switch {
+	case q.PerPage <= 0:
+		return DefaultPerPage
+	case q.PerPage > MaxPerPage:
+		return MaxPerPage
+	}
+	return q.PerPage
+}
+
+// Offset は読み飛ばす件数を返す
+func (q PageQuery) Offset() int {
+	if q.Page <= 1 {
+		return 0
+	}
+	return (q.Page - 1) * q.Limit()
+}
+
+// Meta はレスポンス用のページ情報を返す
+func (q PageQuery) Meta(total int64) PageMeta {
+	page := q.Page
+	if page <= 0 {
+		page = 1
+	}
+	return PageMeta{Page: page, PerPage: q.Limit(), Total: total}
+}
+
+// PageMeta はページネーションのレスポンス情報
+type PageMeta struct {
+	Page    int   `json:"page" example:"1"`
+	PerPage int   `json:"per_page" example:"20"`
+	Total   int64 `json:"total" example:"42"`
+}
+
+// CursorQuery は ID をカーソルにしたページネーション用クエリパラメータ（Before より古いものを新しい順に返す）
+type CursorQuery struct {
+	Before uint `form:"before" binding:"omitempty,min=1" example:"120"`
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=100" example:"50"`
+}
+
+// Size は1回に返す件数を返す
+func (q CursorQuery) Size() int {
+	if q.Limit <= 0 {
+		return DefaultPerPage
+	}
+	return q.Limit
+}
+
+// CursorMeta はカーソル方式のレスポンス情報（NextCursor を次の before に指定する）
+type CursorMeta struct {
+	NextCursor *uint `json:"next_cursor" example:"71"`
+	HasMore    bool  `json:"has_more" example:"true"`
+}
@@ -0,0 +1,170 @@
+package models
+
+import "time"
+
+// 通報の対象
+const (
+	ReportTargetMicropost = "micropost"
+	ReportTargetUser      = "user"
+)
+
+// 通報の状態（open は未対応、dismissed は却下、actioned は措置済み）
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusDismissed = "dismissed"
+	ReportStatusActioned  = "actioned"
+)
+
+// モデレーターの操作
+const (
+	ModerationActionDismiss     = "dismiss"
+	ModerationActionHidePost    = "hide_post"
+	ModerationActionSuspendUser = "suspend_user"
+)
+
+// Report モデル定義（TargetUserID は通報されたユーザー、投稿の通報では投稿者）
+// 自動モデレーションによる通報は ReporterID が NULL で、Reason にルール名が入る。
+type Report struct {
+	ID           uint        `json:"id" gorm:"primaryKey"`
+	ReporterID   *uint       `json:"reporter_id" gorm:"index"`
+	Reporter     *User       `json:"-" gorm:"foreignKey:ReporterID;references:ID;constraint:OnDelete:CASCADE"`
+	TargetType   string      `json:"target_type" gorm:"size:16;not null"`
+	MicropostID  *uint       `json:"micropost_id" gorm:"index"`
+	Micropost    *Micropost  `json:"-" gorm:"foreignKey:MicropostID;references:ID;constraint:OnDelete:CASCADE"`
+	TargetUserID uint        `json:"target_user_id" gorm:"not null;index"`
+	TargetUser   User        `json:"-" gorm:"foreignKey:TargetUserID;references:ID;constraint:OnDelete:CASCADE"`
+	Reason       string      `json:"reason" gorm:"size:20;not null"`
+	Comment      string      `json:"comment" gorm:"type:text;not null;default:''"`
+	Status       string      `json:"status" gorm:"size:16;not null;default:'open';index"`
+	ResolvedByID *uint       `json:"resolved_by_id"`
+	ResolvedBy   *User       `json:"-" gorm:"foreignKey:ResolvedByID;references:ID;constraint:OnDelete:SET NULL"`
+	ResolvedAt   *time.Time  `json:"resolved_at"`
+	CreatedAt    time.Time   `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	Stats        ReportStats `json:"-" gorm:"-"`
+}
+
+// ModerationAction モデル定義（モデレーターの判断の監査ログ、更新・削除しない）
+// 対象のユーザー・投稿が削除されても残るよう、MicropostID と TargetUserID には外部キー制約を付けない。
+// モデレーターが完全に削除されると ModeratorID は NULL になる。
+type ModerationAction struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ModeratorID  *uint     `json:"moderator_id" gorm:"index"`
+	Moderator    *User     `json:"-" gorm:"foreignKey:ModeratorID;references:ID;constraint:OnDelete:SET NULL"`
+	Action       string    `json:"action" gorm:"size:20;not null"`
+	ReportID     *uint     `json:"report_id" gorm:"index"`
+	Report       *Report   `json:"-" gorm:"foreignKey:ReportID;references:ID;constraint:OnDelete:SET NULL"`
+	MicropostID  *uint     `json:"micropost_id"`
+	TargetUserID *uint     `json:"target_user_id" gorm:"index"`
+	Note         string    `json:"note" gorm:"type:text;not null;default:''"`
+	CreatedAt    time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// ReportRequest は通報リクエスト用の構造体
+type ReportRequest struct {
+	Reason  string `json:"reason" binding:"required,oneof=spam harassment hate violence sexual other" example:"spam"`
+	Comment string `json:"comment" binding:"omitempty,max=500" example:"同じ内容の宣伝を繰り返しています"`
+}
+
+// Normalize はコメントを保存用に正規化する
+func (r *ReportRequest) Normalize() {
+	r.Comment = NormalizeText(r.Comment, true)
+}
+
+// ReportQuery はモデレーションキューの絞り込み用クエリパラメータ（既定は未対応の通報）
+type ReportQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=open dismissed actioned" example:"open"`
+	PageQuery
+}
+
+// ModerationRequest はモデレーターの操作リクエスト用の構造体
+type ModerationRequest struct {
+	Action string `json:"action" binding:"required,oneof=dismiss hide_post suspend_user" example:"hide_post"`
+	Note   string `json:"note" binding:"omitempty,max=1000" example:"宣伝目的の投稿のため非表示にしました"`
+}
+
+// ReportResponse は通報のレスポンス用の構造体（モデレーションキューでは対象の投稿とユーザーを含む、自動モデレーションの通報は reporter が null）
+type ReportResponse struct {
+	ID          uint               `json:"id" example:"1"`
+	Reporter    *UserResponse      `json:"reporter"`
+	TargetType  string             `json:"target_type" example:"micropost"`
+	Micropost   *MicropostResponse `json:"micropost,omitempty"`
+	TargetUser  UserResponse       `json:"target_user"`
+	Reason      string             `json:"reason" example:"spam"`
+	Comment     string             `json:"comment" example:"同じ内容の宣伝を繰り返しています"`
+	Status      string             `json:"status" example:"open"`
+	ReportCount int64              `json:"report_count" example:"3"`
+	ResolvedAt  *time.Time         `json:"resolved_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// ReportStats はモデレーションキュー用の集計（DB には保存しない）
+type ReportStats struct {
+	// ReportCount は同じ対象への未対応の通報数
+	ReportCount int64
+}
+
+// ToResponse は Report モデルを ReportResponse に変換する
+func (r *Report) ToResponse() ReportResponse {
+	response := ReportResponse{
+		ID:          r.ID,
+		TargetType:  r.TargetType,
+		TargetUser:  r.TargetUser.ToResponse(),
+		Reason:      r.Reason,
+		Comment:     r.Comment,
+		Status:      r.Status,
+		ReportCount: r.Stats.ReportCount,
+		ResolvedAt:  r.ResolvedAt,
+		CreatedAt:   r.CreatedAt,
+	}
+	if r.Reporter != nil {
+		reporter := r.Reporter.ToResponse()
+		response.Reporter = &reporter
+	}
+	if r.Micropost != nil {
+		micropost := r.Micropost.ToResponse()
+		response.Micropost = &micropost
+	}
+	return response
+}
+
+// ReportListResponse はページネーション付きのモデレーションキューのレスポンス構造体
+type ReportListResponse struct {
+	Reports []ReportResponse `json:"reports"`
+	PageMeta
+}
+
+// ModerationActionResponse は監査ログ1件のレスポンス構造体
+type ModerationActionResponse struct {
+	ID           uint          `json:"id" example:"1"`
+	Moderator    *UserResponse `json:"moderator"` // 完全に削除されたモデレーターなら null
+	Action       string        `json:"action" example:"hide_post"`
+	ReportID     *uint         `json:"report_id" example:"1"`
+	MicropostID  *uint         `json:"micropost_id" example:"10"`
+	TargetUserID *uint         `json:"target_user_id" example:"2"`
+	Note         string        `json:"note" example:"宣伝目的の投稿のため非表示にしました"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// ToResponse は ModerationAction モデルを ModerationActionResponse に変換する
+func (a *ModerationAction) ToResponse() ModerationActionResponse {
+	response := ModerationActionResponse{
+		ID:           a.ID,
+		Action:       a.Action,
+		ReportID:     a.ReportID,
+		MicropostID:  a.MicropostID,
+		TargetUserID: a.TargetUserID,
+		Note:         a.Note,
+		CreatedAt:    a.CreatedAt,
+	}
+	if a.Moderator != nil {
+		moderator := a.Moderator.ToResponse()
+		response.Moderator = &moderator
+	}
+	return response
+}
+
+// ModerationActionListResponse はページネーション付きの監査ログのレスポンス構造体
+type ModerationActionListResponse struct {
+	Actions []ModerationActionResponse `json:"actions"`
+	PageMeta
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// MicropostRevision モデル定義（投稿の版の履歴）
+// 公開した時点の内容が版 1 で、タイトルか本文を編集するたびに次の版を追加する。最新の版が現在の内容になる。
+// 投稿と一緒に完全に削除され、ゴミ箱の中の投稿の履歴は管理者だけが見られる。
+type MicropostRevision struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	MicropostID uint      `json:"micropost_id" gorm:"not null;uniqueIndex:idx_micropost_revisions_version"`
+	Micropost   Micropost `json:"-" gorm:"foreignKey:MicropostID;references:ID;constraint:OnDelete:CASCADE"`
+	Version     int       `json:"version" gorm:"not null;uniqueIndex:idx_micropost_revisions_version"`
+	Title       string    `json:"title" gorm:"not null"`
+	Body        string    `json:"body" gorm:"type:text;not null;default:''"`
+	CreatedAt   time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// MicropostRevisionResponse は版のレスポンス構造体（差分は1つ前の版から、版 1 は空からの差分）
+type MicropostRevisionResponse struct {
+	Version   int           `json:"version" example:"2"`
+	Title     string        `json:"title" example:"マイクロポストのタイトル"`
+	Body      string        `json:"body" example:"マイクロポストの本文"`
+	TitleDiff []DiffSegment `json:"title_diff"`
+	BodyDiff  []DiffSegment `json:"body_diff"`
+	CreatedAt time.Time     `json:"created_at" example:"2024-11-09T18:00:00+09:00"`
+}
+
+// ToResponse は版を1つ前の版（previous、なければ nil）からの差分付きのレスポンスに変換する
+func (r *MicropostRevision) ToResponse(previous *MicropostRevision) MicropostRevisionResponse {
+	var before MicropostRevision
+	if previous != nil {
+		before = *previous
+	}
+	return MicropostRevisionResponse{
+		Version:   r.Version,
+		Title:     r.Title,
+		Body:      r.Body,
+		TitleDiff: Diff(before.Title, r.Title),
+		BodyDiff:  Diff(before.Body, r.Body),
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// MicropostRevisionListResponse はページネーション付きの版の一覧レスポンス構造体（新しい版から順に並ぶ）
+type MicropostRevisionListResponse struct {
+	Revisions []MicropostRevisionResponse `json:"revisions"`
+	PageMeta
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// MicropostSearchQuery はマイクロポスト検索のクエリパラメータ
+type MicropostSearchQuery struct {
+	Q      string     `form:"q" binding:"required" example:"東京"`
+	UserID uint       `form:"user_id" example:"1"`
+	From   *time.Time `form:"from" example:"2024-11-01T00:00:00+09:00"`
+	To     *time.Time `form:"to" example:"2024-11-30T23:59:59+09:00"`
+	PageQuery
+}
+
+// MicropostSearchHit は検索結果1件（service から handler へ渡す）
+type MicropostSearchHit struct {
+	Micropost Micropost
+	Rank      float64
+	Snippet   string
+}
+
+// MicropostSearchResult は検索結果1件のレスポンス構造体
+type MicropostSearchResult struct {
+	MicropostResponse
+	Rank    float64 `json:"rank" example:"0.6"`
+	Snippet string  `json:"snippet" example:"今日は<mark>東京</mark>で雨が降りました"`
+}
+
+// MicropostSearchResponse は検索結果のレスポンス構造体
+type MicropostSearchResponse struct {
+	Results  []MicropostSearchResult `json:"results"`
+	Strategy string                  `json:"strategy" example:"fulltext"`
+	PageMeta
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Tag モデル定義（Name は NormalizeTagName で正規化済み）
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:100;uniqueIndex;not null" example:"日本語"`
+	CreatedAt time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// TrendingTagQuery はトレンドタグ取得のクエリパラメータ
+type TrendingTagQuery struct {
+	Window string `form:"window" example:"24h"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50" example:"10"`
+}
+
+// TrendingTag はトレンドタグ1件のレスポンス構造体
+type TrendingTag struct {
+	Name      string `json:"name" example:"日本語"`
+	PostCount int64  `json:"post_count" example:"12"`
+	UserCount int64  `json:"user_count" example:"8"`
+}
+
+// TrendingTagsResponse はトレンドタグ一覧のレスポンス構造体
+type TrendingTagsResponse struct {
+	Window string        `json:"window" example:"24h0m0s"`
+	Tags   []TrendingTag `json:"tags"`
+}
+
+// MicropostListResponse はページネーション付きのマイクロポスト一覧レスポンス構造体
+type MicropostListResponse struct {
+	Microposts []MicropostResponse `json:"microposts"`
+	PageMeta
+}
+
+// トレンドタグの集計期間と件数
+var DefaultTrendingWindow = 24 * time.Hour
+
+const (
+	MaxTrendingWindow    = 7 * 24 * time.Hour
+	DefaultTrendingLimit = 10
+)
+
+// ErrInvalidTrendingWindow は集計期間が解釈できないか範囲外のときのエラー
+var ErrInvalidTrendingWindow = errors.New("invalid trending window")
+
+// Duration は集計期間を返す（未指定なら DefaultTrendingWindow）
+func (q TrendingTagQuery) Duration() (time.Duration, error) {
+	if q.Window == "" {
+		return DefaultTrendingWindow, nil
+	}
+	window, err := time.ParseDuration(q.Window)
+	if err != nil || window <= 0 || window > MaxTrendingWindow {
+		return 0, ErrInvalidTrendingWindow
+	}
+	return window, nil
+}
+
+// Count は取得件数を返す（未指定なら DefaultTrendingLimit）
+func (q TrendingTagQuery) Count() int {
+	if q.Limit <= 0 {
+		return DefaultTrendingLimit
+	}
+	return q.Limit
+}
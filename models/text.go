@@ -0,0 +1,36 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeText は投稿テキストを保存用に正規化する
+// (NFC 正規化、改行コードの統一、制御文字の除去、前後の空白のトリム)
+func NormalizeText(s string, multiline bool) string {
+	s = norm.NFC.String(s)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' && multiline:
+			return r
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r) && r != '\u200d':
+			// ゼロ幅結合子は絵文字の合成に使われるので残す
+			return -1
+		}
+		return r
+	}, s)
+
+	return strings.TrimSpace(s)
+}
+
+// CharCount は文字数を Unicode のコードポイント単位で数える（バイト数ではない）
+func CharCount(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,67 @@
+package models_test
+
+import (
+	"testing"
+
+	"go-gin-gorm-minimum/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		multiline bool
+		expected  string
+	}{
+		{name: "Trim", input: "  こんにちは  ", expected: "こんにちは"},
+		{name: "NFC", input: "\u304b\u3099", expected: "\u304c"},
+		{name: "Control Characters", input: "a\u0000b\u0007c\u200bd", expected: "abcd"},
+		{name: "Single Line", input: "line1\r\nline2", expected: "line1 line2"},
+		{name: "Multiline", input: "line1\r\nline2\n", multiline: true, expected: "line1\nline2"},
+		{name: "Emoji ZWJ Sequence", input: "\U0001F468\u200d\U0001F469", expected: "\U0001F468\u200d\U0001F469"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, models.NormalizeText(tt.input, tt.multiline))
+		})
+	}
+}
+
+func TestMicropostRequestValidate(t *testing.T) {
+	req := models.MicropostRequest{Title: " \t ", Body: "本文"}
+	req.Normalize()
+	assert.ErrorIs(t, req.Validate(), models.ErrMicropostTitleRequired)
+
+	original := models.MicropostBodyMaxLength
+	defer func() { models.MicropostBodyMaxLength = original }()
+	models.MicropostBodyMaxLength = 3
+
+	req = models.MicropostRequest{Title: "タイトル", Body: "日本語"}
+	req.Normalize()
+	assert.NoError(t, req.Validate())
+
+	req.Body = "日本語です"
+	assert.ErrorIs(t, req.Validate(), models.ErrMicropostBodyTooLong)
+}
+
+func TestProfileUpdateRequestValidate(t *testing.T) {
+	valid := "https://example.com/me"
+	invalid := "ftp://example.com"
+	empty := ""
+
+	for _, tt := range []struct {
+		website  *string
+		expected error
+	}{
+		{website: nil},
+		{website: &empty},
+		{website: &valid},
+		{website: &invalid, expected: models.ErrInvalidWebsite},
+	} {
+		req := models.ProfileUpdateRequest{Website: tt.website}
+		assert.Equal(t, tt.expected, req.Validate())
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TrashRetention は削除したユーザー・投稿をゴミ箱に残す期間（起動時に設定で上書きされる）。
+// この期間内なら復元でき、過ぎると purge で完全に削除される。
+var TrashRetention = 30 * 24 * time.Hour
+
+// PurgeAt は削除日時から完全に削除される日時を返す
+func PurgeAt(deletedAt gorm.DeletedAt) time.Time {
+	return deletedAt.Time.Add(TrashRetention)
+}
+
+// Restorable は削除済みの行がまだ復元できるかどうかを返す
+func Restorable(deletedAt gorm.DeletedAt, now time.Time) bool {
+	return deletedAt.Valid && now.Before(PurgeAt(deletedAt))
+}
@@ -0,0 +1,38 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRestorable(t *testing.T) {
+	now := time.Now()
+	deletedAt := func(ago time.Duration) gorm.DeletedAt {
+		return gorm.DeletedAt{Time: now.Add(-ago), Valid: true}
+	}
+
+	tests := []struct {
+		name      string
+		deletedAt gorm.DeletedAt
+		expected  bool
+	}{
+		{name: "Not Deleted", deletedAt: gorm.DeletedAt{}, expected: false},
+		{name: "Just Deleted", deletedAt: deletedAt(time.Minute), expected: true},
+		{name: "Within Retention", deletedAt: deletedAt(models.TrashRetention - time.Hour), expected: true},
+		{name: "Past Retention", deletedAt: deletedAt(models.TrashRetention + time.Hour), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, models.Restorable(tt.deletedAt, now))
+		})
+	}
+
+	deleted := deletedAt(0)
+	assert.Equal(t, now.Add(models.TrashRetention), models.PurgeAt(deleted))
+}
@@ -1,27 +1,102 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"net/url"
+	"time"
 
-// User モデル定義
+	"gorm.io/gorm"
+)
+
+// プロフィールの検証エラー
+var ErrInvalidWebsite = errors.New("invalid website")
+
+// ユーザーの権限
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// User モデル定義（Handle は大文字小文字を区別せず一意、未指定ならサインアップ時に生成する）。
+// DeletionRequestedAt は本人が退会を申請した日時で、猶予期間中はログインし直して取り消せる。
 type User struct {
-	ID         uint        `json:"id" gorm:"primaryKey" example:"1"`
-	Email      string      `json:"email" gorm:"uniqueIndex;not null" binding:"required,email" example:"user1@example.com"`
-	Password   string      `json:"password" gorm:"not null" binding:"required,min=6" example:"password123"`
-	Role       string      `json:"role" gorm:"default:'user'" example:"user"`
-	AvatarPath string      `json:"avatar_path" example:"/avatars/default.png"`
-	Microposts []Micropost `json:"microposts,omitempty" gorm:"foreignKey:UserID"`
-	CreatedAt  time.Time   `json:"-" gorm:"default:CURRENT_TIMESTAMP"`
-	UpdatedAt  time.Time   `json:"-" gorm:"default:CURRENT_TIMESTAMP;autoUpdateTime"`
+	ID                  uint           `json:"id" gorm:"primaryKey" example:"1"`
+	Email               string         `json:"email" gorm:"uniqueIndex;not null" binding:"required,email" example:"user1@example.com"`
+	Handle              string         `json:"handle" gorm:"size:30;not null;default:''" binding:"omitempty,handle" example:"user1"`
+	DisplayName         string         `json:"display_name" gorm:"size:50;not null;default:''" binding:"omitempty,max=50" example:"ユーザー1"`
+	Bio                 string         `json:"bio" gorm:"type:text;not null;default:''" binding:"omitempty,max=160" example:"Go と Gin が好きです"`
+	Location            string         `json:"location" gorm:"size:30;not null;default:''" binding:"omitempty,max=30" example:"東京"`
+	Website             string         `json:"website" gorm:"size:200;not null;default:''" binding:"omitempty,url,max=200" example:"https://example.com"`
+	Password            string         `json:"password" gorm:"not null" binding:"required,min=6" example:"password123"`
+	Role                string         `json:"role" gorm:"default:'user'" example:"user"`
+	AvatarPath          string         `json:"avatar_path" example:"/avatars/default.png"`
+	Locale              string         `json:"locale" gorm:"size:8" binding:"omitempty,oneof=ja en" example:"ja"`
+	SuspendedAt         *time.Time     `json:"-" gorm:"index"`
+	DeletionRequestedAt *time.Time     `json:"-"`
+	Microposts          []Micropost    `json:"microposts,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	CreatedAt           time.Time      `json:"-" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt           time.Time      `json:"-" gorm:"default:CURRENT_TIMESTAMP;autoUpdateTime"`
+	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
+	Stats               UserStats      `json:"-" gorm:"-"`
+}
+
+// UserStats はプロフィール表示用の集計（DB には保存しない）
+type UserStats struct {
+	PostCount      int64
+	FollowerCount  int64
+	FollowingCount int64
+	FollowedByMe   bool
+	BlockedByMe    bool
+	MutedByMe      bool
 }
 
 // UserResponse は、パスワードを除外したユーザー情報のレスポンス構造体
 type UserResponse struct {
-	ID         uint      `json:"id" example:"1"`
-	Email      string    `json:"email" example:"user1@example.com"`
-	Role       string    `json:"role" example:"user"`
-	AvatarPath string    `json:"avatar_path" example:"/avatars/default.png"`
-	CreatedAt  time.Time `json:"created_at" example:"2024-11-09T18:00:00+09:00"`
-	UpdatedAt  time.Time `json:"updated_at" example:"2024-11-09T18:00:00+09:00"`
+	ID          uint       `json:"id" example:"1"`
+	Email       string     `json:"email" example:"user1@example.com"`
+	Handle      string     `json:"handle" example:"user1"`
+	DisplayName string     `json:"display_name" example:"ユーザー1"`
+	Bio         string     `json:"bio" example:"Go と Gin が好きです"`
+	Location    string     `json:"location" example:"東京"`
+	Website     string     `json:"website" example:"https://example.com"`
+	Role        string     `json:"role" example:"user"`
+	AvatarPath  string     `json:"avatar_path" example:"/avatars/default.png"`
+	Locale      string     `json:"locale" example:"ja"`
+	SuspendedAt *time.Time `json:"suspended_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-11-09T18:00:00+09:00"`
+	UpdatedAt   time.Time  `json:"updated_at" example:"2024-11-09T18:00:00+09:00"`
+}
+
+// ProfileResponse はプロフィール表示用のレスポンス構造体（投稿数・フォロー数と閲覧者との関係を含む）
+type ProfileResponse struct {
+	UserResponse
+	PostCount      int64 `json:"post_count" example:"42"`
+	FollowerCount  int64 `json:"follower_count" example:"10"`
+	FollowingCount int64 `json:"following_count" example:"5"`
+	FollowedByMe   bool  `json:"followed_by_me" example:"false"`
+	BlockedByMe    bool  `json:"blocked_by_me" example:"false"`
+	MutedByMe      bool  `json:"muted_by_me" example:"false"`
+}
+
+// Name は表示名（未設定なら @handle）を返す
+func (u *User) Name() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return "@" + u.Handle
+}
+
+// ToProfileResponse は User モデルを集計付きの ProfileResponse に変換する
+func (u *User) ToProfileResponse() ProfileResponse {
+	return ProfileResponse{
+		UserResponse:   u.ToResponse(),
+		PostCount:      u.Stats.PostCount,
+		FollowerCount:  u.Stats.FollowerCount,
+		FollowingCount: u.Stats.FollowingCount,
+		FollowedByMe:   u.Stats.FollowedByMe,
+		BlockedByMe:    u.Stats.BlockedByMe,
+		MutedByMe:      u.Stats.MutedByMe,
+	}
 }
 
 // LoginResponse は、ログイン時のレスポンス構造体
@@ -33,12 +108,19 @@ type LoginResponse struct {
 // ToResponse は User モデルを UserResponse に変換するヘルパー関数
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:         u.ID,
-		Email:      u.Email,
-		Role:       u.Role,
-		AvatarPath: u.AvatarPath,
-		CreatedAt:  u.CreatedAt,
-		UpdatedAt:  u.UpdatedAt,
+		ID:          u.ID,
+		Email:       u.Email,
+		Handle:      u.Handle,
+		DisplayName: u.DisplayName,
+		Bio:         u.Bio,
+		Location:    u.Location,
+		Website:     u.Website,
+		Role:        u.Role,
+		AvatarPath:  u.AvatarPath,
+		Locale:      u.Locale,
+		SuspendedAt: u.SuspendedAt,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
 	}
 }
 
@@ -47,3 +129,70 @@ type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email" example:"user1@example.com"`
 	Password string `json:"password" binding:"required,min=6" example:"password123"`
 }
+
+// AccountDeletionRequest は退会リクエスト用の構造体（本人確認のためパスワードを再入力する）
+type AccountDeletionRequest struct {
+	Password string `json:"password" binding:"required" example:"password123"`
+}
+
+// AccountDeletionResponse は退会受付時のレスポンス構造体（PurgeAt までは取り消せる）
+type AccountDeletionResponse struct {
+	PurgeAt time.Time `json:"purge_at" example:"2024-12-09T18:00:00+09:00"`
+}
+
+// LocaleRequest は表示言語の設定リクエスト用の構造体（空文字で Accept-Language に従う）
+type LocaleRequest struct {
+	Locale string `json:"locale" binding:"omitempty,oneof=ja en" example:"en"`
+}
+
+// ProfileUpdateRequest はプロフィール更新リクエスト用の構造体（省略した項目は変更しない、空文字で消去）
+type ProfileUpdateRequest struct {
+	Handle      *string `json:"handle" binding:"omitempty,handle" example:"user1"`
+	DisplayName *string `json:"display_name" binding:"omitempty,max=50" example:"ユーザー1"`
+	Bio         *string `json:"bio" binding:"omitempty,max=160" example:"Go と Gin が好きです"`
+	Location    *string `json:"location" binding:"omitempty,max=30" example:"東京"`
+	Website     *string `json:"website" binding:"omitempty,max=200" example:"https://example.com"`
+}
+
+// Normalize は各項目を保存用に正規化する
+func (r *ProfileUpdateRequest) Normalize() {
+	for _, field := range []*string{r.DisplayName, r.Location, r.Website} {
+		if field != nil {
+			*field = NormalizeText(*field, false)
+		}
+	}
+	if r.Bio != nil {
+		*r.Bio = NormalizeText(*r.Bio, true)
+	}
+}
+
+// Validate は正規化後の内容を検証する（Website は空か http/https の URL）
+func (r *ProfileUpdateRequest) Validate() error {
+	if r.Website == nil || *r.Website == "" {
+		return nil
+	}
+	u, err := url.Parse(*r.Website)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebsite
+	}
+	return nil
+}
+
+// Apply は指定された項目だけをユーザーに反映する
+func (r *ProfileUpdateRequest) Apply(user *User) {
+	if r.Handle != nil {
+		user.Handle = *r.Handle
+	}
+	if r.DisplayName != nil {
+		user.DisplayName = *r.DisplayName
+	}
+	if r.Bio != nil {
+		user.Bio = *r.Bio
+	}
+	if r.Location != nil {
+		user.Location = *r.Location
+	}
+	if r.Website != nil {
+		user.Website = *r.Website
+	}
+}
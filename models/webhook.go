@@ -0,0 +1,185 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+var ErrInvalidWebhookURL = errors.New("invalid webhook url")
+
+// Webhook の配信の状態（pending は送信待ち・再試行待ち、succeeded は 2xx の応答、failed は再試行の上限に達したもの）
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookMaxAttempts は Webhook の配信を試行する回数
+var WebhookMaxAttempts = 8
+
+// WebhookResponseBodyMax は配信ログに残す応答本文の最大バイト数
+const WebhookResponseBodyMax = 1024
+
+// Webhook モデル定義（外部のシステムへのドメインイベントの送信先）
+// 利用者が登録したものは本人に関するイベント（自分の投稿・自分への follow など）だけを受け取る。
+// Global は管理者だけが設定でき、すべてのユーザーのイベントを受け取る。
+// Secret は配信の署名に使い、作成時のレスポンスでだけ返す。
+type Webhook struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	User       User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	URL        string    `json:"url" gorm:"size:2048;not null"`
+	EventTypes []string  `json:"event_types" gorm:"type:jsonb;serializer:json;not null"`
+	Secret     string    `json:"-" gorm:"size:128;not null"`
+	Global     bool      `json:"global" gorm:"not null;default:false"`
+	Active     bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP;autoUpdateTime"`
+}
+
+// WebhookDelivery モデル定義（Webhook へのイベントの配信ログ、1イベント1件）
+// 直近の試行の応答コード・応答本文・エラーを残す。Payload は送信した本文そのもので、署名の確認に使える。
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	WebhookID      uint       `json:"webhook_id" gorm:"not null;index"`
+	Webhook        Webhook    `json:"-" gorm:"foreignKey:WebhookID;references:ID;constraint:OnDelete:CASCADE"`
+	EventID        uint       `json:"event_id" gorm:"not null"`
+	EventType      string     `json:"event_type" gorm:"size:100;not null"`
+	Payload        string     `json:"payload" gorm:"type:jsonb;not null"`
+	Status         string     `json:"status" gorm:"size:16;not null;default:'pending'"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int        `json:"response_status" gorm:"not null;default:0"`
+	ResponseBody   string     `json:"response_body" gorm:"type:text;not null;default:''"`
+	Error          string     `json:"error" gorm:"type:text;not null;default:''"`
+	DurationMs     int64      `json:"duration_ms" gorm:"not null;default:0"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP;autoUpdateTime"`
+}
+
+// WebhookRequest は Webhook の登録リクエスト用の構造体（Secret を省略するとサーバーで生成する）
+type WebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url,max=2048" example:"https://example.com/hooks/microposts"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,max=10,dive,oneof=user.signed_up user.deleted micropost.created micropost.deleted micropost.liked user.followed" example:"micropost.created"`
+	Secret     string   `json:"secret" binding:"omitempty,min=16,max=128" example:"4f1c9a2e7b6d3c8a5e0f1b2c3d4e5f60"`
+	Global     bool     `json:"global" example:"false"`
+}
+
+// WebhookUpdateRequest は Webhook の更新リクエスト用の構造体（指定した項目だけ更新する）
+type WebhookUpdateRequest struct {
+	URL        *string  `json:"url" binding:"omitempty,url,max=2048" example:"https://example.com/hooks/microposts"`
+	EventTypes []string `json:"event_types" binding:"omitempty,min=1,max=10,dive,oneof=user.signed_up user.deleted micropost.created micropost.deleted micropost.liked user.followed" example:"micropost.created"`
+	Active     *bool    `json:"active" example:"true"`
+}
+
+// Validate は URL が http/https の URL かどうかを検証する
+func (r *WebhookRequest) Validate() error {
+	return validateWebhookURL(r.URL)
+}
+
+// Validate は URL を指定した場合に http/https の URL かどうかを検証する
+func (r *WebhookUpdateRequest) Validate() error {
+	if r.URL == nil {
+		return nil
+	}
+	return validateWebhookURL(*r.URL)
+}
+
+// Apply は指定された項目だけを Webhook に反映する
+func (r *WebhookUpdateRequest) Apply(webhook *Webhook) {
+	if r.URL != nil {
+		webhook.URL = *r.URL
+	}
+	if r.EventTypes != nil {
+		webhook.EventTypes = r.EventTypes
+	}
+	if r.Active != nil {
+		webhook.Active = *r.Active
+	}
+}
+
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// WebhookResponse は Webhook のレスポンス構造体（Secret は作成時だけ返す）
+type WebhookResponse struct {
+	ID         uint      `json:"id" example:"1"`
+	URL        string    `json:"url" example:"https://example.com/hooks/microposts"`
+	EventTypes []string  `json:"event_types" example:"micropost.created"`
+	Secret     string    `json:"secret,omitempty" example:"4f1c9a2e7b6d3c8a5e0f1b2c3d4e5f60"`
+	Global     bool      `json:"global" example:"false"`
+	Active     bool      `json:"active" example:"true"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-11-09T18:00:00+09:00"`
+}
+
+// ToResponse は Webhook モデルを WebhookResponse に変換する（Secret は含めない）
+func (w *Webhook) ToResponse() WebhookResponse {
+	return WebhookResponse{
+		ID:         w.ID,
+		URL:        w.URL,
+		EventTypes: w.EventTypes,
+		Global:     w.Global,
+		Active:     w.Active,
+		CreatedAt:  w.CreatedAt,
+	}
+}
+
+// WebhookListResponse はページネーション付きの Webhook 一覧レスポンス構造体
+type WebhookListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+	PageMeta
+}
+
+// WebhookDeliveryResponse は配信ログのレスポンス構造体
+type WebhookDeliveryResponse struct {
+	ID             uint       `json:"id" example:"1"`
+	EventID        uint       `json:"event_id" example:"42"`
+	EventType      string     `json:"event_type" example:"micropost.created"`
+	Payload        string     `json:"payload" example:"{\"id\":42,\"type\":\"micropost.created\",\"data\":{}}"`
+	Status         string     `json:"status" example:"succeeded"`
+	Attempts       int        `json:"attempts" example:"1"`
+	ResponseStatus int        `json:"response_status" example:"200"`
+	ResponseBody   string     `json:"response_body" example:"ok"`
+	Error          string     `json:"error" example:""`
+	DurationMs     int64      `json:"duration_ms" example:"120"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" example:"2024-11-09T18:00:00+09:00"`
+	CreatedAt      time.Time  `json:"created_at" example:"2024-11-09T18:00:00+09:00"`
+}
+
+// ToResponse は WebhookDelivery モデルを WebhookDeliveryResponse に変換する
+func (d *WebhookDelivery) ToResponse() WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		ResponseBody:   d.ResponseBody,
+		Error:          d.Error,
+		DurationMs:     d.DurationMs,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+// WebhookDeliveryListResponse はページネーション付きの配信ログ一覧レスポンス構造体
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	PageMeta
+}
+
+// WebhookPayload は Webhook で送る本文
+type WebhookPayload struct {
+	ID        uint        `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
@@ -0,0 +1,150 @@
+// Package realtime delivers server-side events (new microposts, notifications)
+// to connected clients through an in-process hub. A Broker fans events out
+// between server instances so every hub sees every event.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// Event types
+const (
+	EventMicropost    = "micropost"
+	EventNotification = "notification"
+	EventMessage      = "message"
+)
+
+// subscriptionBuffer is the number of events queued per connection before
+// events are dropped for that (slow) client.
+const subscriptionBuffer = 32
+
+// Event is a single message delivered to subscribers.
+// UserID targets one user; when it is zero the hub's Audience decides who receives it.
+type Event struct {
+	Type    string          `json:"type" example:"micropost"`
+	ID      uint            `json:"id" example:"1"`
+	UserID  uint            `json:"user_id,omitempty" example:"0"`
+	ActorID uint            `json:"actor_id" example:"2"`
+	Data    json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+}
+
+// Publisher accepts events for delivery.
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Broker carries events between hubs, possibly across processes.
+type Broker interface {
+	Publish(ctx context.Context, event Event) error
+	// Subscribe calls handler for every published event until ctx is done.
+	Subscribe(ctx context.Context, handler func(Event)) error
+}
+
+// Audience picks which of the locally connected users receive an untargeted event.
+type Audience interface {
+	Recipients(event Event, candidates []uint) ([]uint, error)
+}
+
+// Subscription is one client connection.
+type Subscription struct {
+	UserID uint
+	C      <-chan Event
+	ch     chan Event
+}
+
+// Hub keeps the local subscriptions and dispatches events received from the broker.
+type Hub struct {
+	broker   Broker
+	audience Audience
+
+	mu   sync.RWMutex
+	subs map[uint]map[*Subscription]struct{}
+}
+
+func NewHub(broker Broker, audience Audience) *Hub {
+	return &Hub{
+		broker:   broker,
+		audience: audience,
+		subs:     make(map[uint]map[*Subscription]struct{}),
+	}
+}
+
+// Publish sends the event through the broker. Delivery is best effort: errors are logged.
+func (h *Hub) Publish(event Event) {
+	if err := h.broker.Publish(context.Background(), event); err != nil {
+		log.Printf("realtime: publish %s %d: %v", event.Type, event.ID, err)
+	}
+}
+
+// Run receives events from the broker and dispatches them until ctx is done.
+func (h *Hub) Run(ctx context.Context) error {
+	return h.broker.Subscribe(ctx, h.dispatch)
+}
+
+// Subscribe registers a connection for the user.
+func (h *Hub) Subscribe(userID uint) *Subscription {
+	ch := make(chan Event, subscriptionBuffer)
+	sub := &Subscription{UserID: userID, C: ch, ch: ch}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe removes the connection and closes its channel.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub.UserID][sub]; !ok {
+		return
+	}
+	delete(h.subs[sub.UserID], sub)
+	if len(h.subs[sub.UserID]) == 0 {
+		delete(h.subs, sub.UserID)
+	}
+	close(sub.ch)
+}
+
+func (h *Hub) dispatch(event Event) {
+	recipients := []uint{event.UserID}
+	if event.UserID == 0 {
+		candidates := h.connectedUsers()
+		if len(candidates) == 0 {
+			return
+		}
+		var err error
+		if recipients, err = h.audience.Recipients(event, candidates); err != nil {
+			log.Printf("realtime: resolve recipients for %s %d: %v", event.Type, event.ID, err)
+			return
+		}
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, userID := range recipients {
+		for sub := range h.subs[userID] {
+			select {
+			case sub.ch <- event:
+			default:
+				log.Printf("realtime: dropping %s %d for slow subscriber (user %d)", event.Type, event.ID, userID)
+			}
+		}
+	}
+}
+
+func (h *Hub) connectedUsers() []uint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	users := make([]uint, 0, len(h.subs))
+	for userID := range h.subs {
+		users = append(users, userID)
+	}
+	return users
+}
@@ -0,0 +1,88 @@
+package realtime_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-gin-gorm-minimum/realtime"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// followers is an Audience where each key is followed by the listed users
+type followers map[uint][]uint
+
+func (f followers) Recipients(event realtime.Event, candidates []uint) ([]uint, error) {
+	var recipients []uint
+	for _, id := range candidates {
+		if id == event.ActorID {
+			recipients = append(recipients, id)
+		}
+		for _, follower := range f[event.ActorID] {
+			if id == follower {
+				recipients = append(recipients, id)
+			}
+		}
+	}
+	return recipients, nil
+}
+
+func startHub(t *testing.T, audience realtime.Audience) *realtime.Hub {
+	broker := realtime.NewMemoryBroker()
+	hub := realtime.NewHub(broker, audience)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go hub.Run(ctx)
+	// Run が購読を登録するまで待つ
+	time.Sleep(10 * time.Millisecond)
+	return hub
+}
+
+func receive(sub *realtime.Subscription) (realtime.Event, bool) {
+	select {
+	case event := <-sub.C:
+		return event, true
+	case <-time.After(50 * time.Millisecond):
+		return realtime.Event{}, false
+	}
+}
+
+func TestHubDelivery(t *testing.T) {
+	hub := startHub(t, followers{1: {2}})
+	author := hub.Subscribe(1)
+	follower := hub.Subscribe(2)
+	stranger := hub.Subscribe(3)
+
+	// 投稿は投稿者とフォロワーに届く
+	hub.Publish(realtime.Event{Type: realtime.EventMicropost, ID: 10, ActorID: 1})
+	for _, sub := range []*realtime.Subscription{author, follower} {
+		event, ok := receive(sub)
+		assert.True(t, ok)
+		assert.Equal(t, uint(10), event.ID)
+	}
+	_, ok := receive(stranger)
+	assert.False(t, ok)
+
+	// 通知は受信者だけに届く
+	hub.Publish(realtime.Event{Type: realtime.EventNotification, ID: 20, UserID: 3, ActorID: 1})
+	event, ok := receive(stranger)
+	assert.True(t, ok)
+	assert.Equal(t, realtime.EventNotification, event.Type)
+	_, ok = receive(author)
+	assert.False(t, ok)
+}
+
+func TestHubUnsubscribe(t *testing.T) {
+	hub := startHub(t, followers{})
+	sub := hub.Subscribe(1)
+	hub.Unsubscribe(sub)
+	hub.Unsubscribe(sub)
+
+	_, open := <-sub.C
+	assert.False(t, open)
+
+	// 購読を解除した後のイベントで panic しない
+	hub.Publish(realtime.Event{Type: realtime.EventNotification, ID: 1, UserID: 1})
+}
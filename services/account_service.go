@@ -0,0 +1,69 @@
+package services
+
+import (
+	"time"
+
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/utils"
+
+	"gorm.io/gorm"
+)
+
+// DeleteAccount はパスワードを確認して本人の退会を受け付け、アカウントを投稿ごとゴミ箱に移す。
+// 猶予期間（models.TrashRetention）が過ぎると purge でいいね・フォローなどと一緒に完全に削除され、
+// それまでは CancelDeletion で取り消せる。完全に削除される日時を返す。
+func (s *AuthService) DeleteAccount(userID uint, password string) (time.Time, error) {
+	var purgeAt time.Time
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		if err := utils.CheckPassword(user.Password, password); err != nil {
+			return ErrIncorrectPassword
+		}
+
+		now := time.Now()
+		if err := tx.Model(&user).Update("deletion_requested_at", now).Error; err != nil {
+			return err
+		}
+		if err := trashUser(tx, &user, now); err != nil {
+			return err
+		}
+		purgeAt = models.PurgeAt(gorm.DeletedAt{Time: now, Valid: true})
+		return nil
+	})
+	return purgeAt, err
+}
+
+// CancelDeletion は猶予期間中の退会を取り消してログインする（本人が退会したアカウントのみ、管理者による削除は取り消せない）
+func (s *AuthService) CancelDeletion(email, password string) (*models.LoginResponse, error) {
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Where("email = ? AND deleted_at IS NOT NULL AND deletion_requested_at IS NOT NULL", email).
+			First(&user).Error
+		if err != nil {
+			return err
+		}
+		if err := utils.CheckPassword(user.Password, password); err != nil {
+			return err
+		}
+		if user.SuspendedAt != nil {
+			return ErrAccountSuspended
+		}
+		return restoreUser(tx, &user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokenString, err := utils.GenerateJWTToken(user)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		Token:        tokenString,
+		UserResponse: user.ToResponse(),
+	}, nil
+}
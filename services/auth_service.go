@@ -1,6 +1,8 @@
 package services
 
 import (
+	"fmt"
+
 	"go-gin-gorm-minimum/models"
 	"go-gin-gorm-minimum/utils"
 
@@ -21,19 +23,65 @@ func (s *AuthService) SignUp(user *models.User) error {
 		return err
 	}
 	user.Password = hashedPassword
+	// 権限・利用停止・アバターはリクエストから受け付けない
+	user.Role = models.RoleUser
+	user.SuspendedAt = nil
+	user.AvatarPath = utils.DefaultAvatarPath
+
+	if user.Handle == "" {
+		handle, err := s.availableHandle(models.HandleFromEmail(user.Email))
+		if err != nil {
+			return err
+		}
+		user.Handle = handle
+	}
 
-	return s.db.Create(user).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return RecordEvent(tx, models.UserSignedUp{UserID: user.ID, Handle: user.Handle})
+	})
 }
 
+// availableHandle は候補のハンドルが使われていれば連番を付けて空いているものを返す
+func (s *AuthService) availableHandle(base string) (string, error) {
+	for i := 1; i <= 100; i++ {
+		handle := base
+		if i > 1 {
+			handle = fmt.Sprintf("%s%d", base, i)
+		}
+		taken, err := handleInUse(s.db, handle, 0)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return handle, nil
+		}
+	}
+	return "", ErrHandleUnavailable
+}
+
+// Login はメールアドレスとパスワードでログインする。
+// 退会の猶予期間中のアカウントはパスワードが正しければ ErrAccountDeleted を返す（CancelDeletion で取り消せる）。
 func (s *AuthService) Login(email, password string) (*models.LoginResponse, error) {
 	var user models.User
-	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := s.db.Unscoped().Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, err
 	}
+	if user.DeletedAt.Valid && user.DeletionRequestedAt == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
 
 	if err := utils.CheckPassword(user.Password, password); err != nil {
 		return nil, err
 	}
+	if user.SuspendedAt != nil {
+		return nil, ErrAccountSuspended
+	}
+	if user.DeletedAt.Valid {
+		return nil, ErrAccountDeleted
+	}
 
 	tokenString, err := utils.GenerateJWTToken(user)
 	if err != nil {
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BlockService struct {
+	db *gorm.DB
+}
+
+func NewBlockService(db *gorm.DB) *BlockService {
+	return &BlockService{db: db}
+}
+
+// hiddenUsersSQL は閲覧者から見えなくするユーザー（ミュートしたユーザー、どちらかがブロックしているユーザーと削除済みのユーザー）。
+// プレースホルダーには閲覧者の ID を3回渡す。
+const hiddenUsersSQL = `SELECT muted_id FROM mutes WHERE muter_id = ?
+	UNION SELECT blocked_id FROM blocks WHERE blocker_id = ?
+	UNION SELECT blocker_id FROM blocks WHERE blocked_id = ?
+	UNION ` + deletedUsersSQL
+
+// excludeHiddenUsers は column のユーザーが閲覧者から見えないものを除く（未ログインなら何もしない）
+func excludeHiddenUsers(viewerID uint, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db
+		}
+		return db.Where(column+" NOT IN ("+hiddenUsersSQL+")", viewerID, viewerID, viewerID)
+	}
+}
+
+// excludeHiddenMicroposts は閲覧者から見えないユーザーの投稿と、その投稿の再投稿・引用を除く
+func excludeHiddenMicroposts(viewerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db
+		}
+		return db.Scopes(excludeHiddenUsers(viewerID, "microposts.user_id")).
+			Where("(microposts.repost_of_id IS NULL OR microposts.repost_of_id NOT IN (SELECT id FROM microposts WHERE user_id IN ("+hiddenUsersSQL+")))",
+				viewerID, viewerID, viewerID)
+	}
+}
+
+// blockedWith は userIDs のうち userID との間にどちらかからのブロックがあるユーザーを返す
+func blockedWith(db *gorm.DB, userID uint, userIDs []uint) (map[uint]bool, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var ids []uint
+	err := db.Raw(`SELECT blocked_id FROM blocks WHERE blocker_id = ? AND blocked_id IN ?
+		UNION SELECT blocker_id FROM blocks WHERE blocked_id = ? AND blocker_id IN ?`,
+		userID, userIDs, userID, userIDs).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		blocked[id] = true
+	}
+	return blocked, nil
+}
+
+// blockedAmong は userIDs のユーザーどうしの間にどちらかからのブロックが1つでもあるかどうかを返す
+func blockedAmong(db *gorm.DB, userIDs []uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.Block{}).
+		Where("blocker_id IN ? AND blocked_id IN ?", userIDs, userIDs).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// checkNotBlocked は2人のユーザーの間にどちらかからのブロックがあれば ErrBlocked を返す
+func checkNotBlocked(db *gorm.DB, userID, otherID uint) error {
+	blocked, err := blockedWith(db, userID, []uint{otherID})
+	if err != nil {
+		return err
+	}
+	if blocked[otherID] {
+		return ErrBlocked
+	}
+	return nil
+}
+
+// Block はユーザーをブロックし、お互いのフォローとフォロー通知を解除して、集計付きのブロック先を返す（既にブロック済みなら何もしない）
+func (s *BlockService) Block(blockerID, blockedID uint) (models.User, error) {
+	if blockerID == blockedID {
+		return models.User{}, ErrCannotBlockSelf
+	}
+
+	var user models.User
+	if err := s.db.First(&user, blockedID).Error; err != nil {
+		return user, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		block := models.Block{BlockerID: blockerID, BlockedID: blockedID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&block).Error; err != nil {
+			return err
+		}
+		err := tx.Where("(follower_id = ? AND followed_id = ?) OR (follower_id = ? AND followed_id = ?)",
+			blockerID, blockedID, blockedID, blockerID).Delete(&models.Follow{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("type = ? AND micropost_id IS NULL", models.NotificationTypeFollow).
+			Where("(user_id = ? AND actor_id = ?) OR (user_id = ? AND actor_id = ?)", blockerID, blockedID, blockedID, blockerID).
+			Delete(&models.Notification{}).Error
+	})
+	if err != nil {
+		return user, err
+	}
+	err = loadUserStats(s.db, blockerID, &user)
+	return user, err
+}
+
+// Unblock はブロックを解除し、集計付きの相手を返す（ブロックしていなければ何もしない）。解除したフォローは戻さない。
+func (s *BlockService) Unblock(blockerID, blockedID uint) (models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, blockedID).Error; err != nil {
+		return user, err
+	}
+	if err := s.db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&models.Block{}).Error; err != nil {
+		return user, err
+	}
+	err := loadUserStats(s.db, blockerID, &user)
+	return user, err
+}
+
+// Mute はユーザーをミュートし、集計付きのミュート先を返す（既にミュート済みなら何もしない）
+func (s *BlockService) Mute(muterID, mutedID uint) (models.User, error) {
+	if muterID == mutedID {
+		return models.User{}, ErrCannotMuteSelf
+	}
+
+	var user models.User
+	if err := s.db.First(&user, mutedID).Error; err != nil {
+		return user, err
+	}
+	mute := models.Mute{MuterID: muterID, MutedID: mutedID}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&mute).Error; err != nil {
+		return user, err
+	}
+	err := loadUserStats(s.db, muterID, &user)
+	return user, err
+}
+
+// Unmute はミュートを解除し、集計付きの相手を返す（ミュートしていなければ何もしない）
+func (s *BlockService) Unmute(muterID, mutedID uint) (models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, mutedID).Error; err != nil {
+		return user, err
+	}
+	if err := s.db.Where("muter_id = ? AND muted_id = ?", muterID, mutedID).Delete(&models.Mute{}).Error; err != nil {
+		return user, err
+	}
+	err := loadUserStats(s.db, muterID, &user)
+	return user, err
+}
+
+// Blocked はブロックしているユーザーを新しい順に返す
+func (s *BlockService) Blocked(userID uint, page models.PageQuery) ([]models.User, int64, error) {
+	return s.listUsers(&models.Block{}, "blocks", "blocked_id", "blocker_id", userID, page)
+}
+
+// Muted はミュートしているユーザーを新しい順に返す
+func (s *BlockService) Muted(userID uint, page models.PageQuery) ([]models.User, int64, error) {
+	return s.listUsers(&models.Mute{}, "mutes", "muted_id", "muter_id", userID, page)
+}
+
+// listUsers は table の ownerColumn が userID の行の targetColumn のユーザーを新しい順に返す
+func (s *BlockService) listUsers(model interface{}, table, targetColumn, ownerColumn string, userID uint, page models.PageQuery) ([]models.User, int64, error) {
+	var total int64
+	if err := s.db.Model(model).Where(ownerColumn+" = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err := s.db.Joins("JOIN "+table+" ON "+table+"."+targetColumn+" = users.id").
+		Where(table+"."+ownerColumn+" = ?", userID).
+		Order(table + ".created_at DESC, " + table + ".id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Find(&users).Error
+	return users, total, err
+}
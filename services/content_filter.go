@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+// ContentRejectedError は自動モデレーションで投稿が拒否されたことを表す（errors.Is(err, ErrContentRejected) が真になる）
+type ContentRejectedError struct {
+	Verdict models.ContentVerdict
+}
+
+func (e *ContentRejectedError) Error() string {
+	return fmt.Sprintf("content rejected by %s rule", e.Verdict.Rule)
+}
+
+func (e *ContentRejectedError) Is(target error) bool {
+	return target == ErrContentRejected
+}
+
+// ContentRule は投稿内容を検査するルール。作成・更新のトランザクション内で呼ばれる
+// (更新時の micropost は ID が設定済み)。
+type ContentRule interface {
+	Check(tx *gorm.DB, micropost *models.Micropost) (models.ContentVerdict, error)
+}
+
+// ContentFilter は投稿の作成・更新時に実行するルールの一覧
+type ContentFilter struct {
+	rules []ContentRule
+}
+
+func NewContentFilter(rules ...ContentRule) *ContentFilter {
+	return &ContentFilter{rules: rules}
+}
+
+// Check はすべてのルールを実行し、最も厳しい判定を返す（ルールがなければ allow）
+func (f *ContentFilter) Check(tx *gorm.DB, micropost *models.Micropost) (models.ContentVerdict, error) {
+	verdict := models.ContentVerdict{Action: models.ContentActionAllow}
+	if f == nil {
+		return verdict, nil
+	}
+	for _, rule := range f.rules {
+		v, err := rule.Check(tx, micropost)
+		if err != nil {
+			return verdict, err
+		}
+		if v.StricterThan(verdict) {
+			verdict = v
+		}
+	}
+	return verdict, nil
+}
+
+// contentFilter は投稿の作成・更新時の自動モデレーション。未設定なら検査しない
+var contentFilter *ContentFilter
+
+// SetContentFilter は自動モデレーションのルールを設定する（起動時に1回だけ呼ぶ）
+func SetContentFilter(filter *ContentFilter) {
+	contentFilter = filter
+}
+
+// WordFilterRule は禁止語を含む投稿を語ごとの判定で扱う（全角・半角、カタカナ・ひらがなの違いは区別しない）
+type WordFilterRule struct {
+	Words *models.WordFilter
+}
+
+func (r WordFilterRule) Check(_ *gorm.DB, micropost *models.Micropost) (models.ContentVerdict, error) {
+	return r.Words.Match(micropost.Title, micropost.Body), nil
+}
+
+// LinkLimitRule は URL が Max 個を超える投稿を Action で扱う
+type LinkLimitRule struct {
+	Max    int
+	Action string
+}
+
+func (r LinkLimitRule) Check(_ *gorm.DB, micropost *models.Micropost) (models.ContentVerdict, error) {
+	count := models.CountLinks(micropost.Title, micropost.Body)
+	if count <= r.Max {
+		return models.ContentVerdict{Action: models.ContentActionAllow}, nil
+	}
+	return models.ContentVerdict{
+		Action: r.Action,
+		Rule:   models.ContentRuleLinkLimit,
+		Detail: fmt.Sprintf("%d links (max %d)", count, r.Max),
+	}, nil
+}
+
+// DuplicateRule は同じユーザーが Window 内に同じ内容を Limit 回以上投稿していれば Action で扱う
+// (文字種の違いと空白は無視して比較する、コメントなしの再投稿と下書き・予約投稿は数えない)
+type DuplicateRule struct {
+	Window time.Duration
+	Limit  int
+	Action string
+}
+
+// duplicateScanLimit は重複の比較対象にする直近の投稿数の上限
+const duplicateScanLimit = 100
+
+func (r DuplicateRule) Check(tx *gorm.DB, micropost *models.Micropost) (models.ContentVerdict, error) {
+	allow := models.ContentVerdict{Action: models.ContentActionAllow}
+	if micropost.Kind == models.MicropostKindRepost {
+		return allow, nil
+	}
+
+	var recent []models.Micropost
+	err := tx.Select("title", "body").
+		Where("user_id = ? AND id <> ? AND kind <> ? AND created_at >= ?",
+			micropost.UserID, micropost.ID, models.MicropostKindRepost, time.Now().Add(-r.Window)).
+		Scopes(published).
+		Order("id DESC").
+		Limit(duplicateScanLimit).
+		Find(&recent).Error
+	if err != nil {
+		return allow, err
+	}
+
+	content := duplicateKey(micropost)
+	count := 0
+	for i := range recent {
+		if duplicateKey(&recent[i]) == content {
+			count++
+		}
+	}
+	if count < r.Limit {
+		return allow, nil
+	}
+	return models.ContentVerdict{
+		Action: r.Action,
+		Rule:   models.ContentRuleDuplicate,
+		Detail: fmt.Sprintf("%d identical posts within %s", count, r.Window),
+	}, nil
+}
+
+func duplicateKey(m *models.Micropost) string {
+	return strings.Join(strings.Fields(models.FoldText(m.Title+"\n"+m.Body)), " ")
+}
+
+// screenContent は投稿内容を自動モデレーションにかけ、reject なら ContentRejectedError を返す。
+// shadow_hide なら投稿を投稿者本人以外から隠す（解除はしない）。
+func screenContent(tx *gorm.DB, micropost *models.Micropost) (models.ContentVerdict, error) {
+	verdict, err := contentFilter.Check(tx, micropost)
+	if err != nil {
+		return verdict, err
+	}
+	switch verdict.Action {
+	case models.ContentActionReject:
+		return verdict, &ContentRejectedError{Verdict: verdict}
+	case models.ContentActionShadowHide:
+		micropost.ShadowHidden = true
+	}
+	return verdict, nil
+}
+
+// fileContentReport は flag・shadow_hide と判定された投稿をモデレーションキューに載せる
+// (同じ投稿への自動通報が未対応のまま残っていれば追加しない)
+func fileContentReport(tx *gorm.DB, micropost *models.Micropost, verdict models.ContentVerdict) error {
+	if verdict.Allowed() {
+		return nil
+	}
+	var count int64
+	err := tx.Model(&models.Report{}).
+		Where("reporter_id IS NULL AND micropost_id = ? AND status = ?", micropost.ID, models.ReportStatusOpen).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+	return tx.Create(&models.Report{
+		TargetType:   models.ReportTargetMicropost,
+		MicropostID:  &micropost.ID,
+		TargetUserID: micropost.UserID,
+		Reason:       verdict.Rule,
+		Comment:      verdict.Action + ": " + verdict.Detail,
+	}).Error
+}
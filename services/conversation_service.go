@@ -0,0 +1,288 @@
+package services
+
+import (
+	"errors"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ConversationService struct {
+	db *gorm.DB
+}
+
+func NewConversationService(db *gorm.DB) *ConversationService {
+	return &ConversationService{db: db}
+}
+
+// participantOf は userID が参加している会話に絞り込む条件
+const participantOf = "conversations.id IN (SELECT conversation_id FROM conversation_participants WHERE user_id = ?)"
+
+// unreadMessages は参加者ごとの未読メッセージ（既読位置より新しい、他の参加者のメッセージ）を結合する
+const unreadMessages = `JOIN messages ON messages.conversation_id = conversation_participants.conversation_id
+	AND messages.id > conversation_participants.last_read_message_id
+	AND messages.sender_id <> conversation_participants.user_id`
+
+// Start は会話を開始し、作成した場合は true を返す。相手が1人なら既存の1対1の会話を返す。
+// req は Normalize と Validate を済ませておくこと。存在しないユーザーを含む場合は models.ErrInvalidParticipants、
+// 参加者（開始するユーザーを含む）どうしの間にブロックがある場合は ErrBlocked を返す。
+func (s *ConversationService) Start(userID uint, req models.ConversationRequest) (*models.Conversation, bool, error) {
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id IN ?", req.ParticipantIDs).Count(&count).Error; err != nil {
+		return nil, false, err
+	}
+	if int(count) != len(req.ParticipantIDs) {
+		return nil, false, models.ErrInvalidParticipants
+	}
+	blocked, err := blockedAmong(s.db, append([]uint{userID}, req.ParticipantIDs...))
+	if err != nil {
+		return nil, false, err
+	}
+	if blocked {
+		return nil, false, ErrBlocked
+	}
+
+	conversation := models.Conversation{
+		IsGroup:     len(req.ParticipantIDs) > 1,
+		CreatedByID: &userID,
+	}
+	if conversation.IsGroup {
+		conversation.Title = req.Title
+	} else {
+		key := models.DirectConversationKey(userID, req.ParticipantIDs[0])
+		conversation.DirectKey = &key
+	}
+
+	created := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit("Participants").
+			Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "direct_key"}}, DoNothing: true}).
+			Create(&conversation)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// 同じ2人の1対1の会話が既にある
+			return tx.Where("direct_key = ?", *conversation.DirectKey).First(&conversation).Error
+		}
+
+		participants := []models.ConversationParticipant{{ConversationID: conversation.ID, UserID: userID}}
+		for _, id := range req.ParticipantIDs {
+			participants = append(participants, models.ConversationParticipant{ConversationID: conversation.ID, UserID: id})
+		}
+		created = true
+		return tx.Create(&participants).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	loaded, err := s.Get(userID, conversation.ID)
+	return loaded, created, err
+}
+
+// List は参加している会話を最後のメッセージが新しい順に返す
+func (s *ConversationService) List(userID uint, page models.PageQuery) ([]models.Conversation, int64, error) {
+	query := s.db.Model(&models.Conversation{}).Where(participantOf, userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var conversations []models.Conversation
+	err := query.Session(&gorm.Session{}).
+		Preload("Participants", orderByID).
+		Preload("Participants.User").
+		Order("COALESCE(last_message_at, created_at) DESC, id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Find(&conversations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = s.loadStats(userID, conversations)
+	return conversations, total, err
+}
+
+// Get は参加している会話を返す。参加していなければ gorm.ErrRecordNotFound を返す。
+func (s *ConversationService) Get(userID, id uint) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := s.db.Where(participantOf, userID).
+		Preload("Participants", orderByID).
+		Preload("Participants.User").
+		First(&conversation, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	conversations := []models.Conversation{conversation}
+	if err := s.loadStats(userID, conversations); err != nil {
+		return nil, err
+	}
+	return &conversations[0], nil
+}
+
+// loadStats は閲覧者の未読数と最後のメッセージを設定する
+func (s *ConversationService) loadStats(userID uint, conversations []models.Conversation) error {
+	if len(conversations) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(conversations))
+	for _, c := range conversations {
+		ids = append(ids, c.ID)
+	}
+
+	var unread []struct {
+		ConversationID uint
+		Count          int64
+	}
+	err := s.db.Model(&models.ConversationParticipant{}).
+		Select("conversation_participants.conversation_id, COUNT(messages.id) AS count").
+		Joins(unreadMessages).
+		Where("conversation_participants.user_id = ? AND conversation_participants.conversation_id IN ?", userID, ids).
+		Group("conversation_participants.conversation_id").
+		Scan(&unread).Error
+	if err != nil {
+		return err
+	}
+
+	var lastMessages []models.Message
+	err = s.db.Select("DISTINCT ON (conversation_id) *").
+		Preload("Sender").
+		Where("conversation_id IN ?", ids).
+		Order("conversation_id, id DESC").
+		Find(&lastMessages).Error
+	if err != nil {
+		return err
+	}
+
+	unreadByID := make(map[uint]int64, len(unread))
+	for _, row := range unread {
+		unreadByID[row.ConversationID] = row.Count
+	}
+	lastByID := make(map[uint]*models.Message, len(lastMessages))
+	for i := range lastMessages {
+		lastByID[lastMessages[i].ConversationID] = &lastMessages[i]
+	}
+	for i := range conversations {
+		conversations[i].Stats = models.ConversationStats{
+			UnreadCount: unreadByID[conversations[i].ID],
+			LastMessage: lastByID[conversations[i].ID],
+		}
+	}
+	return nil
+}
+
+// UnreadCount は参加しているすべての会話の未読メッセージ数を返す
+func (s *ConversationService) UnreadCount(userID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&models.ConversationParticipant{}).
+		Joins(unreadMessages).
+		Where("conversation_participants.user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// participant は会話の参加者を返す。参加していなければ gorm.ErrRecordNotFound を返す。
+func (s *ConversationService) participant(db *gorm.DB, userID, conversationID uint) (models.ConversationParticipant, error) {
+	var participant models.ConversationParticipant
+	err := db.Where("conversation_id = ? AND user_id = ?", conversationID, userID).First(&participant).Error
+	return participant, err
+}
+
+// Messages は会話のメッセージを新しい順に返す（query.Before より古いもの）。次があれば true を返す。
+func (s *ConversationService) Messages(userID, id uint, query models.CursorQuery) ([]models.Message, bool, error) {
+	if _, err := s.participant(s.db, userID, id); err != nil {
+		return nil, false, err
+	}
+
+	find := s.db.Preload("Sender").Where("conversation_id = ?", id)
+	if query.Before > 0 {
+		find = find.Where("id < ?", query.Before)
+	}
+
+	var messages []models.Message
+	size := query.Size()
+	if err := find.Order("id DESC").Limit(size + 1).Find(&messages).Error; err != nil {
+		return nil, false, err
+	}
+	if len(messages) > size {
+		return messages[:size], true, nil
+	}
+	return messages, false, nil
+}
+
+// Send はメッセージを送信し、送信者の既読位置を進めて参加者全員に配信する。
+// 1対1でもグループでも、送信者との間にブロックがある参加者がいれば ErrBlocked を返す。
+func (s *ConversationService) Send(userID, id uint, req models.MessageRequest) (*models.Message, error) {
+	message := models.Message{ConversationID: id, SenderID: userID, Body: req.Body}
+	var recipients []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.participant(tx, userID, id); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ConversationParticipant{}).
+			Where("conversation_id = ?", id).
+			Pluck("user_id", &recipients).Error; err != nil {
+			return err
+		}
+		blocked, err := blockedWith(tx, userID, recipients)
+		if err != nil {
+			return err
+		}
+		if len(blocked) > 0 {
+			return ErrBlocked
+		}
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Conversation{}).Where("id = ?", id).
+			Update("last_message_at", message.CreatedAt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ConversationParticipant{}).
+			Where("conversation_id = ? AND user_id = ?", id, userID).
+			Update("last_read_message_id", message.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Preload("Sender").First(&message, message.ID).Error; err != nil {
+		return nil, err
+	}
+	publishMessage(&message, recipients)
+	return &message, nil
+}
+
+// MarkRead は既読位置を messageID まで進め（0 なら最新のメッセージまで）、更新後の会話を返す。既読位置は戻さない。
+func (s *ConversationService) MarkRead(userID, id, messageID uint) (*models.Conversation, error) {
+	if _, err := s.participant(s.db, userID, id); err != nil {
+		return nil, err
+	}
+
+	var message models.Message
+	find := s.db.Where("conversation_id = ?", id)
+	if messageID > 0 {
+		find = find.Where("id = ?", messageID)
+	}
+	err := find.Order("id DESC").First(&message).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound) && messageID == 0:
+		// メッセージがまだない
+		return s.Get(userID, id)
+	case err != nil:
+		return nil, err
+	}
+
+	err = s.db.Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND last_read_message_id < ?", id, userID, message.ID).
+		Update("last_read_message_id", message.ID).Error
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(userID, id)
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// service 層のエラー（handler でステータスコードとエラーコードに対応付ける）
+var (
+	ErrAlreadyReposted         = errors.New("already reposted")
+	ErrForbidden               = errors.New("forbidden")
+	ErrHandleUnavailable       = errors.New("no available handle")
+	ErrHandleTaken             = errors.New("handle already taken")
+	ErrCannotFollowSelf        = errors.New("cannot follow yourself")
+	ErrCannotBlockSelf         = errors.New("cannot block yourself")
+	ErrCannotMuteSelf          = errors.New("cannot mute yourself")
+	ErrBlocked                 = errors.New("blocked")
+	ErrCannotReportSelf        = errors.New("cannot report yourself")
+	ErrAlreadyReported         = errors.New("already reported")
+	ErrReportResolved          = errors.New("report already resolved")
+	ErrInvalidModerationAction = errors.New("invalid moderation action")
+	ErrAccountSuspended        = errors.New("account suspended")
+	ErrContentRejected         = errors.New("content rejected")
+	ErrAccountDeleted          = errors.New("account deleted")
+	ErrRestoreExpired          = errors.New("restore period expired")
+	ErrIncorrectPassword       = errors.New("incorrect password")
+	ErrExportInProgress        = errors.New("export already in progress")
+	ErrExportNotReady          = errors.New("export not ready")
+	ErrExportExpired           = errors.New("export expired")
+	ErrWebhookDeliveryPending  = errors.New("webhook delivery pending")
+	ErrWebhookAddressBlocked   = errors.New("webhook address not allowed")
+	ErrJobNotRetryable         = errors.New("job not retryable")
+)
+
+// isUniqueViolation は一意制約違反（SQLSTATE 23505）のエラーかどうかを返す
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
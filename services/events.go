@@ -0,0 +1,82 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/realtime"
+)
+
+// eventPublisher はコミット後のイベント（新しい投稿・通知）の配信先。未設定なら配信しない
+var eventPublisher realtime.Publisher
+
+// SetEventPublisher はリアルタイム配信のハブを設定する（起動時に1回だけ呼ぶ）
+func SetEventPublisher(publisher realtime.Publisher) {
+	eventPublisher = publisher
+}
+
+// publishNotifications は作成済みの通知を受信者に配信する
+func publishNotifications(notifications []models.Notification) {
+	if eventPublisher == nil {
+		return
+	}
+	for _, n := range notifications {
+		data, err := json.Marshal(n)
+		if err != nil {
+			log.Printf("realtime: marshal notification %d: %v", n.ID, err)
+			continue
+		}
+		eventPublisher.Publish(realtime.Event{
+			Type:    realtime.EventNotification,
+			ID:      n.ID,
+			UserID:  n.UserID,
+			ActorID: n.ActorID,
+			Data:    data,
+		})
+	}
+}
+
+// publishMicropost は新しい投稿を投稿者とフォロワーに配信する（閲覧者ごとの集計は含めない）
+func (s *MicropostService) publishMicropost(id uint) {
+	if eventPublisher == nil {
+		return
+	}
+	microposts, err := s.findByIDs(0, []uint{id})
+	if err != nil || len(microposts) == 0 {
+		log.Printf("realtime: load micropost %d: %v", id, err)
+		return
+	}
+	data, err := json.Marshal(microposts[0].ToResponse())
+	if err != nil {
+		log.Printf("realtime: marshal micropost %d: %v", id, err)
+		return
+	}
+	eventPublisher.Publish(realtime.Event{
+		Type:    realtime.EventMicropost,
+		ID:      id,
+		ActorID: microposts[0].UserID,
+		Data:    data,
+	})
+}
+
+// publishMessage は新しいメッセージを会話の参加者それぞれに配信する（送信者の他の接続にも届ける）
+func publishMessage(message *models.Message, recipients []uint) {
+	if eventPublisher == nil {
+		return
+	}
+	data, err := json.Marshal(message.ToResponse())
+	if err != nil {
+		log.Printf("realtime: marshal message %d: %v", message.ID, err)
+		return
+	}
+	for _, userID := range recipients {
+		eventPublisher.Publish(realtime.Event{
+			Type:    realtime.EventMessage,
+			ID:      message.ID,
+			UserID:  userID,
+			ActorID: message.SenderID,
+			Data:    data,
+		})
+	}
+}
@@ -0,0 +1,431 @@
+package services
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExportService struct {
+	db  *gorm.DB
+	dir string
+}
+
+// NewExportService は dir にアーカイブを保存する ExportService を返す
+func NewExportService(db *gorm.DB, dir string) *ExportService {
+	return &ExportService{db: db, dir: dir}
+}
+
+// Request はデータエクスポートを受け付け、アーカイブを作成するジョブを登録する。
+// 作成中のエクスポートがあれば ErrExportInProgress を返す。
+func (s *ExportService) Request(userID uint) (*models.DataExport, error) {
+	export := models.DataExport{UserID: userID, Status: models.ExportStatusPending}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 一意制約 idx_data_exports_active に違反した場合は挿入されない
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&export)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrExportInProgress
+		}
+		_, err := EnqueueJob(tx, JobTypeExport, exportJob{ExportID: export.ID}, JobOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// List はユーザーのデータエクスポートを新しい順に返す
+func (s *ExportService) List(userID uint, page models.PageQuery) ([]models.DataExport, int64, error) {
+	query := s.db.Model(&models.DataExport{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var exports []models.DataExport
+	err := query.Session(&gorm.Session{}).
+		Order("id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Find(&exports).Error
+	return exports, total, err
+}
+
+// Get はユーザー本人のデータエクスポートを返す（他のユーザーのものは見つからないものとして扱う）
+func (s *ExportService) Get(id, userID uint) (*models.DataExport, error) {
+	var export models.DataExport
+	err := s.db.Where("user_id = ?", userID).First(&export, id).Error
+	return &export, err
+}
+
+// Download はダウンロードするアーカイブのパスを返す（作成中なら ErrExportNotReady、期限切れ・失敗なら ErrExportExpired）
+func (s *ExportService) Download(id, userID uint) (string, error) {
+	export, err := s.Get(id, userID)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case export.Status == models.ExportStatusPending || export.Status == models.ExportStatusRunning:
+		return "", ErrExportNotReady
+	case !export.Downloadable(time.Now()):
+		return "", ErrExportExpired
+	}
+	return export.FilePath, nil
+}
+
+// Run は受付済みのデータエクスポートのアーカイブを作成する（作成済みなら何もしない）。
+// 失敗したときは受付済みに戻すので、ジョブの再試行で作り直せる。
+func (s *ExportService) Run(id uint) error {
+	// 前回の試行中にワーカーが落ちた場合は running のまま残っている
+	claimed := s.db.Model(&models.DataExport{}).
+		Where("id = ? AND status IN ?", id, []string{models.ExportStatusPending, models.ExportStatusRunning}).
+		Update("status", models.ExportStatusRunning)
+	if claimed.Error != nil || claimed.RowsAffected == 0 {
+		return claimed.Error
+	}
+
+	var export models.DataExport
+	if err := s.db.First(&export, id).Error; err != nil {
+		return err
+	}
+
+	filePath, size, err := s.writeArchive(&export)
+	if err != nil {
+		s.db.Model(&export).Updates(map[string]interface{}{"status": models.ExportStatusPending, "error": err.Error()})
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(models.ExportTTL)
+	return s.db.Model(&export).Updates(map[string]interface{}{
+		"status":       models.ExportStatusCompleted,
+		"file_path":    filePath,
+		"size":         size,
+		"error":        "",
+		"completed_at": now,
+		"expires_at":   expiresAt,
+	}).Error
+}
+
+// MarkFailed は作成を諦めたデータエクスポートを failed にする（ジョブの再試行が上限に達したとき）
+func (s *ExportService) MarkFailed(id uint, cause error) error {
+	return s.db.Model(&models.DataExport{}).
+		Where("id = ? AND status IN ?", id, []string{models.ExportStatusPending, models.ExportStatusRunning}).
+		Updates(map[string]interface{}{"status": models.ExportStatusFailed, "error": cause.Error()}).Error
+}
+
+// Cleanup はダウンロード期限を過ぎたアーカイブを削除し、エクスポートを expired にする。削除した件数を返す。
+// 削除に失敗したエクスポートはログに残して飛ばし、次の実行で再試行する。
+func (s *ExportService) Cleanup(now time.Time) (int, error) {
+	var exports []models.DataExport
+	err := s.db.Where("status = ? AND expires_at < ?", models.ExportStatusCompleted, now).Find(&exports).Error
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for _, export := range exports {
+		if err := removeExportFile(export.FilePath); err != nil {
+			log.Printf("exports: export %d: %v", export.ID, err)
+			continue
+		}
+		err := s.db.Model(&export).Updates(map[string]interface{}{"status": models.ExportStatusExpired, "file_path": ""}).Error
+		if err != nil {
+			log.Printf("exports: export %d: %v", export.ID, err)
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// removeExportFile はアーカイブを削除する（削除済みなら何もしない）
+func removeExportFile(filePath string) error {
+	if filePath == "" {
+		return nil
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// writeArchive はユーザーの保有データを ZIP に書き出し、ファイルのパスとサイズを返す
+func (s *ExportService) writeArchive(export *models.DataExport) (string, int64, error) {
+	archive, err := s.collect(export.UserID)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", 0, err
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", 0, err
+	}
+	filePath := filepath.Join(s.dir, fmt.Sprintf("export_%d_%d_%s.zip", export.UserID, export.ID, hex.EncodeToString(suffix)))
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	err = writeZip(file, archive)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filePath)
+		return "", 0, err
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	return filePath, info.Size(), nil
+}
+
+// exportTable はアーカイブに JSON と CSV（header があるときのみ）で書き出すデータ
+type exportTable struct {
+	name    string
+	records interface{}
+	header  []string
+	rows    [][]string
+}
+
+// exportFile はアーカイブにそのまま入れるアップロード済みのファイル
+type exportFile struct {
+	name   string
+	source string
+}
+
+type exportMicropost struct {
+	ID         uint       `json:"id"`
+	Kind       string     `json:"kind"`
+	Status     string     `json:"status"`
+	PublishAt  *time.Time `json:"publish_at"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body"`
+	ParentID   *uint      `json:"parent_id"`
+	RepostOfID *uint      `json:"repost_of_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+}
+
+type exportLike struct {
+	MicropostID uint      `json:"micropost_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// exportRelation はフォロー・ブロック・ミュートの相手
+type exportRelation struct {
+	UserID    uint      `json:"user_id"`
+	Handle    string    `json:"handle"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type exportMessage struct {
+	ID             uint      `json:"id"`
+	ConversationID uint      `json:"conversation_id"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// exportArchive はアーカイブの内容
+type exportArchive struct {
+	tables []exportTable
+	files  []exportFile
+}
+
+// collect はユーザーのプロフィール・投稿（ゴミ箱の中と下書きを含む）・いいね・フォロー・ブロック・ミュート・
+// 送信したメッセージとアップロードしたアバター画像を集める
+func (s *ExportService) collect(userID uint) (exportArchive, error) {
+	var archive exportArchive
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return archive, err
+	}
+	archive.tables = append(archive.tables, exportTable{name: "profile", records: user.ToResponse()})
+	// アップロード先の外を指すパスのファイルは含めない
+	if source, ok := utils.UploadedAvatarFile(user.AvatarPath); ok {
+		archive.files = append(archive.files, exportFile{
+			name:   "files/avatar" + path.Ext(user.AvatarPath),
+			source: source,
+		})
+	}
+
+	var microposts []models.Micropost
+	if err := s.db.Unscoped().Where("user_id = ?", userID).Order("id").Find(&microposts).Error; err != nil {
+		return archive, err
+	}
+	posts := make([]exportMicropost, 0, len(microposts))
+	postRows := make([][]string, 0, len(microposts))
+	for _, m := range microposts {
+		post := exportMicropost{ID: m.ID, Kind: m.Kind, Status: m.Status, PublishAt: m.PublishAt, Title: m.Title, Body: m.Body, ParentID: m.ParentID, RepostOfID: m.RepostOfID, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt}
+		if m.DeletedAt.Valid {
+			post.DeletedAt = &m.DeletedAt.Time
+		}
+		posts = append(posts, post)
+		postRows = append(postRows, []string{formatID(post.ID), post.Kind, post.Status, formatTime(post.PublishAt), post.Title, post.Body, formatOptionalID(post.ParentID), formatOptionalID(post.RepostOfID), formatTime(&post.CreatedAt), formatTime(&post.UpdatedAt), formatTime(post.DeletedAt)})
+	}
+	archive.tables = append(archive.tables, exportTable{
+		name:    "microposts",
+		records: posts,
+		header:  []string{"id", "kind", "status", "publish_at", "title", "body", "parent_id", "repost_of_id", "created_at", "updated_at", "deleted_at"},
+		rows:    postRows,
+	})
+
+	var likes []exportLike
+	if err := s.db.Model(&models.Like{}).Select("micropost_id", "created_at").Where("user_id = ?", userID).Order("id").Scan(&likes).Error; err != nil {
+		return archive, err
+	}
+	likeRows := make([][]string, 0, len(likes))
+	for _, like := range likes {
+		likeRows = append(likeRows, []string{formatID(like.MicropostID), formatTime(&like.CreatedAt)})
+	}
+	archive.tables = append(archive.tables, exportTable{name: "likes", records: emptyIfNil(likes), header: []string{"micropost_id", "created_at"}, rows: likeRows})
+
+	relations := []struct {
+		name, table, ownerColumn, otherColumn string
+	}{
+		{"following", "follows", "follower_id", "followed_id"},
+		{"followers", "follows", "followed_id", "follower_id"},
+		{"blocks", "blocks", "blocker_id", "blocked_id"},
+		{"mutes", "mutes", "muter_id", "muted_id"},
+	}
+	for _, r := range relations {
+		var records []exportRelation
+		err := s.db.Table(r.table).
+			Select(r.table+"."+r.otherColumn+" AS user_id, users.handle, "+r.table+".created_at").
+			Joins("JOIN users ON users.id = "+r.table+"."+r.otherColumn).
+			Where(r.table+"."+r.ownerColumn+" = ?", userID).
+			Order(r.table + ".id").
+			Scan(&records).Error
+		if err != nil {
+			return archive, err
+		}
+		rows := make([][]string, 0, len(records))
+		for _, record := range records {
+			rows = append(rows, []string{formatID(record.UserID), record.Handle, formatTime(&record.CreatedAt)})
+		}
+		archive.tables = append(archive.tables, exportTable{name: r.name, records: emptyIfNil(records), header: []string{"user_id", "handle", "created_at"}, rows: rows})
+	}
+
+	var messages []exportMessage
+	if err := s.db.Model(&models.Message{}).Select("id", "conversation_id", "body", "created_at").Where("sender_id = ?", userID).Order("id").Scan(&messages).Error; err != nil {
+		return archive, err
+	}
+	messageRows := make([][]string, 0, len(messages))
+	for _, message := range messages {
+		messageRows = append(messageRows, []string{formatID(message.ID), formatID(message.ConversationID), message.Body, formatTime(&message.CreatedAt)})
+	}
+	archive.tables = append(archive.tables, exportTable{name: "messages", records: emptyIfNil(messages), header: []string{"id", "conversation_id", "body", "created_at"}, rows: messageRows})
+
+	return archive, nil
+}
+
+// writeZip は表を name.json と name.csv として、ファイルをそのまま ZIP に書き出す
+func writeZip(w io.Writer, archive exportArchive) error {
+	zw := zip.NewWriter(w)
+	for _, table := range archive.tables {
+		f, err := zw.Create(table.name + ".json")
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(table.records); err != nil {
+			return err
+		}
+
+		if table.header == nil {
+			continue
+		}
+		f, err = zw.Create(table.name + ".csv")
+		if err != nil {
+			return err
+		}
+		cw := csv.NewWriter(f)
+		if err := cw.Write(table.header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(table.rows); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range archive.files {
+		if err := copyToZip(zw, file); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// copyToZip はファイルを ZIP に追加する（アップロード済みのファイルが見つからなければ飛ばす）
+func copyToZip(zw *zip.Writer, file exportFile) error {
+	src, err := os.Open(file.source)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zw.Create(file.name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// emptyIfNil は JSON で null ではなく [] と書き出すために nil のスライスを空にする
+func emptyIfNil[T any](records []T) []T {
+	if records == nil {
+		return []T{}
+	}
+	return records
+}
+
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func formatOptionalID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return formatID(*id)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
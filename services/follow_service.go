@@ -0,0 +1,73 @@
+package services
+
+import (
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FollowService struct {
+	db *gorm.DB
+}
+
+func NewFollowService(db *gorm.DB) *FollowService {
+	return &FollowService{db: db}
+}
+
+// Follow はユーザーをフォローして通知し、集計付きのフォロー先を返す（既にフォロー済みなら何もしない、ブロックがあれば ErrBlocked）
+func (s *FollowService) Follow(followerID, followedID uint) (models.User, error) {
+	if followerID == followedID {
+		return models.User{}, ErrCannotFollowSelf
+	}
+
+	var user models.User
+	if err := s.db.First(&user, followedID).Error; err != nil {
+		return user, err
+	}
+	if err := checkNotBlocked(s.db, followerID, followedID); err != nil {
+		return user, err
+	}
+
+	var notifications []models.Notification
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		follow := models.Follow{FollowerID: followerID, FollowedID: followedID}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		var err error
+		notifications, err = notify(tx, models.NewNotification(models.NotificationTypeFollow, followedID, followerID, nil))
+		if err != nil {
+			return err
+		}
+		return RecordEvent(tx, models.UserFollowed{FollowerID: followerID, FollowedID: followedID})
+	})
+	if err != nil {
+		return user, err
+	}
+	publishNotifications(notifications)
+	err = loadUserStats(s.db, followerID, &user)
+	return user, err
+}
+
+// Unfollow はフォローを解除して通知を削除し、集計付きのフォロー先を返す（フォローしていなければ何もしない）
+func (s *FollowService) Unfollow(followerID, followedID uint) (models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, followedID).Error; err != nil {
+		return user, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("follower_id = ? AND followed_id = ?", followerID, followedID).Delete(&models.Follow{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return retractNotification(tx, models.NotificationTypeFollow, followedID, followerID, nil)
+	})
+	if err != nil {
+		return user, err
+	}
+	err = loadUserStats(s.db, followerID, &user)
+	return user, err
+}
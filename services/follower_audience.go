@@ -0,0 +1,39 @@
+package services
+
+import (
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/realtime"
+
+	"gorm.io/gorm"
+)
+
+// FollowerAudience は新しい投稿を投稿者本人とそのフォロワー（投稿者をミュートしていない）に配信する
+type FollowerAudience struct {
+	db *gorm.DB
+}
+
+func NewFollowerAudience(db *gorm.DB) *FollowerAudience {
+	return &FollowerAudience{db: db}
+}
+
+// Recipients は接続中のユーザー（candidates）のうち配信先を返す
+func (a *FollowerAudience) Recipients(event realtime.Event, candidates []uint) ([]uint, error) {
+	if event.Type != realtime.EventMicropost {
+		return nil, nil
+	}
+
+	var recipients []uint
+	err := a.db.Model(&models.Follow{}).
+		Where("followed_id = ? AND follower_id IN ?", event.ActorID, candidates).
+		Where("follower_id NOT IN (SELECT muter_id FROM mutes WHERE muted_id = ?)", event.ActorID).
+		Pluck("follower_id", &recipients).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range candidates {
+		if id == event.ActorID {
+			recipients = append(recipients, id)
+		}
+	}
+	return recipients, nil
+}
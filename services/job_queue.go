@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobHandler はジョブを処理する。エラーを返すとバックオフの後に再試行し、上限に達すると dead にする
+type JobHandler func(ctx context.Context, job *models.Job) error
+
+// TypedJob は Payload を T に読み込んでから handle を呼ぶ JobHandler を返す（読み込めないジョブは再試行しても無駄なので dead にする）
+func TypedJob[T any](handle func(ctx context.Context, job *models.Job, payload T) error) JobHandler {
+	return func(ctx context.Context, job *models.Job) error {
+		var payload T
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return &permanentJobError{err: fmt.Errorf("decode payload: %w", err)}
+		}
+		return handle(ctx, job, payload)
+	}
+}
+
+// permanentJobError は再試行せずに dead にするエラー
+type permanentJobError struct {
+	err error
+}
+
+func (e *permanentJobError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentJobError) Unwrap() error {
+	return e.err
+}
+
+// JobOptions はジョブの登録オプション（ゼロ値は既定のキュー・すぐに実行・既定の試行回数）
+type JobOptions struct {
+	Queue       string
+	RunAt       time.Time
+	MaxAttempts int
+	// UniqueKey が同じ未完了のジョブがあれば登録しない
+	UniqueKey string
+}
+
+// EnqueueJob はジョブを登録する。tx にトランザクションを渡すと、そのコミットと一緒に確定する。
+// UniqueKey が同じ未完了のジョブがすでにあれば登録せず nil を返す。
+func EnqueueJob(tx *gorm.DB, jobType string, payload interface{}, opts JobOptions) (*models.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := models.Job{
+		Queue:       opts.Queue,
+		Type:        jobType,
+		Payload:     string(data),
+		Status:      models.JobStatusPending,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+	}
+	if job.Queue == "" {
+		job.Queue = models.DefaultJobQueue
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = models.DefaultJobMaxAttempts
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	if opts.UniqueKey != "" {
+		job.UniqueKey = &opts.UniqueKey
+	}
+
+	// 一意制約 idx_jobs_unique_key に違反した場合は挿入されない
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&job)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &job, nil
+}
+
+// JobQueue はジョブの種類ごとの処理を登録し、ワーカーとして実行する
+type JobQueue struct {
+	db       *gorm.DB
+	handlers map[string]JobHandler
+}
+
+func NewJobQueue(db *gorm.DB) *JobQueue {
+	return &JobQueue{db: db, handlers: make(map[string]JobHandler)}
+}
+
+// Register はジョブの種類の処理を登録する（ワーカーを起動する前に呼ぶ）
+func (q *JobQueue) Register(jobType string, handler JobHandler) {
+	q.handlers[jobType] = handler
+}
+
+// WorkerOptions はワーカーの設定
+type WorkerOptions struct {
+	// Queues は処理するキュー（空なら既定のキュー）
+	Queues []string
+	// Concurrency は同時に処理するジョブの数
+	Concurrency int
+	// PollInterval は実行できるジョブがないときに待つ時間
+	PollInterval time.Duration
+	// LockTimeout を過ぎても終わらない実行中のジョブは、ワーカーが落ちたものとして実行待ちに戻す
+	LockTimeout time.Duration
+}
+
+func (o WorkerOptions) withDefaults() WorkerOptions {
+	if len(o.Queues) == 0 {
+		o.Queues = []string{models.DefaultJobQueue}
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 1
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Second
+	}
+	if o.LockTimeout <= 0 {
+		o.LockTimeout = 15 * time.Minute
+	}
+	return o
+}
+
+// RunWorker は ctx が終わるまでジョブを取り出して処理する。
+// 複数のプロセスで同時に動かしても、FOR UPDATE SKIP LOCKED で同じジョブを二重に取り出さない。
+func (q *JobQueue) RunWorker(ctx context.Context, opts WorkerOptions) {
+	opts = opts.withDefaults()
+	hostname, _ := os.Hostname()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		runEvery(ctx, opts.LockTimeout/2, func() {
+			if n, err := q.RescueStale(opts.LockTimeout); err != nil {
+				log.Printf("jobs: rescue stale jobs: %v", err)
+			} else if n > 0 {
+				log.Printf("jobs: rescued %d stale jobs", n)
+			}
+		})
+	}()
+	for i := 0; i < opts.Concurrency; i++ {
+		workerID := fmt.Sprintf("%s:%d:%d", hostname, os.Getpid(), i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				processed, err := q.RunNext(ctx, workerID, opts.Queues)
+				if err != nil {
+					log.Printf("jobs: %v", err)
+				}
+				if processed {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+				case <-time.After(opts.PollInterval):
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Drain は実行できるジョブがなくなるまで順に処理し、処理した件数を返す（テストやツールから1回だけ実行するとき用）
+func (q *JobQueue) Drain(ctx context.Context, queues ...string) (int, error) {
+	if len(queues) == 0 {
+		queues = []string{models.DefaultJobQueue}
+	}
+	count := 0
+	for ctx.Err() == nil {
+		processed, err := q.RunNext(ctx, "drain", queues)
+		if err != nil {
+			return count, err
+		}
+		if !processed {
+			break
+		}
+		count++
+	}
+	return count, nil
+}
+
+// RunNext は実行できるジョブを1件取り出して処理する（ジョブがなければ false を返す）
+func (q *JobQueue) RunNext(ctx context.Context, workerID string, queues []string) (bool, error) {
+	job, err := q.claim(workerID, queues)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	err = q.execute(ctx, job)
+	return true, q.finish(job, err)
+}
+
+// claim は実行時刻を過ぎた実行待ちのジョブを1件 running にして返す。
+// 他のワーカーがロックしている行は SKIP LOCKED で飛ばすので、待たずに次のジョブを取り出せる。
+func (q *JobQueue) claim(workerID string, queues []string) (*models.Job, error) {
+	var jobs []models.Job
+	err := q.db.Raw(`UPDATE jobs SET status = ?, attempts = attempts + 1, locked_at = now(), locked_by = ?, updated_at = now()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = ? AND queue IN ? AND run_at <= now()
+			ORDER BY run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`,
+		models.JobStatusRunning, workerID, models.JobStatusPending, queues).
+		Scan(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
+
+// execute はジョブの処理を呼ぶ（登録されていない種類と panic はエラーとして扱う）
+func (q *JobQueue) execute(ctx context.Context, job *models.Job) (err error) {
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		return &permanentJobError{err: fmt.Errorf("no handler for job type %q", job.Type)}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// finish はジョブの結果を記録する。失敗したジョブは試行回数が残っていればバックオフの後に再試行し、なければ dead にする。
+// 時間がかかりすぎて RescueStale で戻された後に別のワーカーが取り出したジョブは、その実行の状態を上書きしないように何もしない。
+func (q *JobQueue) finish(job *models.Job, jobErr error) error {
+	now := time.Now()
+	updates := map[string]interface{}{"locked_at": nil, "locked_by": ""}
+	var permanent *permanentJobError
+	switch {
+	case jobErr == nil:
+		updates["status"] = models.JobStatusSucceeded
+		updates["finished_at"] = now
+	case errors.As(jobErr, &permanent) || job.LastAttempt():
+		updates["status"] = models.JobStatusDead
+		updates["finished_at"] = now
+		updates["last_error"] = jobErr.Error()
+		log.Printf("jobs: job %d (%s) is dead after %d attempts: %v", job.ID, job.Type, job.Attempts, jobErr)
+	default:
+		updates["status"] = models.JobStatusPending
+		updates["run_at"] = now.Add(models.JobBackoff(job.Attempts))
+		updates["last_error"] = jobErr.Error()
+	}
+	return q.db.Model(job).
+		Where("status = ? AND locked_by = ? AND attempts = ?", models.JobStatusRunning, job.LockedBy, job.Attempts).
+		Updates(updates).Error
+}
+
+// RescueStale は timeout を過ぎても終わらない実行中のジョブを実行待ちに戻し、戻した件数を返す
+func (q *JobQueue) RescueStale(timeout time.Duration) (int64, error) {
+	result := q.db.Model(&models.Job{}).
+		Where("status = ? AND locked_at < ?", models.JobStatusRunning, time.Now().Add(-timeout)).
+		Updates(map[string]interface{}{"status": models.JobStatusPending, "locked_at": nil, "locked_by": ""})
+	return result.RowsAffected, result.Error
+}
+
+// Schedule は ctx が終わるまで interval ごとに jobType のジョブを登録する（起動時にも1回登録する）。
+// 前回のジョブが終わっていなければ登録しないので、複数のプロセスで動かしても重複しない。
+func (q *JobQueue) Schedule(ctx context.Context, interval time.Duration, jobType string) {
+	runEvery(ctx, interval, func() {
+		if _, err := EnqueueJob(q.db, jobType, struct{}{}, JobOptions{UniqueKey: "schedule:" + jobType}); err != nil {
+			log.Printf("jobs: schedule %s: %v", jobType, err)
+		}
+	})
+}
+
+// Jobs は管理者向けにジョブを新しい順に返す（状態の既定は dead）
+func (q *JobQueue) Jobs(query models.JobQuery) ([]models.Job, int64, error) {
+	status := query.Status
+	if status == "" {
+		status = models.JobStatusDead
+	}
+	db := q.db.Model(&models.Job{}).Where("status = ?", status)
+	if query.Type != "" {
+		db = db.Where("type = ?", query.Type)
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var jobs []models.Job
+	err := db.Session(&gorm.Session{}).
+		Order("id DESC").
+		Limit(query.Limit()).
+		Offset(query.Offset()).
+		Find(&jobs).Error
+	return jobs, total, err
+}
+
+// Retry は dead のジョブを試行回数を戻して実行待ちにする。
+// dead 以外のジョブと、同じ UniqueKey のジョブが実行待ち・実行中のものは ErrJobNotRetryable。
+func (q *JobQueue) Retry(id uint) (*models.Job, error) {
+	var job models.Job
+	if err := q.db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	result := q.db.Model(&job).Where("status = ?", models.JobStatusDead).Updates(map[string]interface{}{
+		"status":      models.JobStatusPending,
+		"attempts":    0,
+		"run_at":      time.Now(),
+		"finished_at": nil,
+	})
+	if isUniqueViolation(result.Error) {
+		return nil, ErrJobNotRetryable
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrJobNotRetryable
+	}
+	err := q.db.First(&job, id).Error
+	return &job, err
+}
+
+// Prune は before より前に完了したジョブを削除し、削除した件数を返す（dead は調査のため残す）
+func (q *JobQueue) Prune(before time.Time) (int64, error) {
+	result := q.db.Where("status = ? AND finished_at < ?", models.JobStatusSucceeded, before).Delete(&models.Job{})
+	return result.RowsAffected, result.Error
+}
+
+// runEvery は ctx が終わるまで interval ごとに fn を実行する（すぐに1回目を実行する）
+func runEvery(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		fn()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
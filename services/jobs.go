@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+// ジョブの種類
+const (
+	JobTypeExport         = "export.create"
+	JobTypeExportCleanup  = "export.cleanup"
+	JobTypeTrashPurge     = "trash.purge"
+	JobTypeJobPrune       = "jobs.prune"
+	JobTypeOutboxPrune    = "outbox.prune"
+	JobTypeWebhookDeliver = "webhook.deliver"
+	JobTypePublishPosts   = "microposts.publish"
+)
+
+// JobRetention は完了したジョブを残す期間（jobs.prune で削除する）
+var JobRetention = 7 * 24 * time.Hour
+
+// exportJob は export.create ジョブの Payload
+type exportJob struct {
+	ExportID uint `json:"export_id"`
+}
+
+// RegisterJobs はアプリケーションのジョブの処理を登録する
+func RegisterJobs(q *JobQueue, db *gorm.DB, exports *ExportService, dispatcher *EventDispatcher) {
+	q.Register(JobTypeExport, TypedJob(func(ctx context.Context, job *models.Job, payload exportJob) error {
+		err := exports.Run(payload.ExportID)
+		if err != nil && job.LastAttempt() {
+			if markErr := exports.MarkFailed(payload.ExportID, err); markErr != nil {
+				log.Printf("export %d: %v", payload.ExportID, markErr)
+			}
+		}
+		return err
+	}))
+
+	q.Register(JobTypeExportCleanup, func(ctx context.Context, job *models.Job) error {
+		count, err := exports.Cleanup(time.Now())
+		if count > 0 {
+			log.Printf("export cleanup: expired %d exports", count)
+		}
+		return err
+	})
+
+	webhooks := NewWebhookService(db)
+	q.Register(JobTypeWebhookDeliver, TypedJob(webhooks.Deliver))
+
+	microposts := NewMicropostService(db)
+	q.Register(JobTypePublishPosts, func(ctx context.Context, job *models.Job) error {
+		count, err := microposts.PublishScheduled(time.Now())
+		if count > 0 {
+			log.Printf("scheduled: published %d microposts", count)
+		}
+		return err
+	})
+
+	trash := NewTrashService(db)
+	q.Register(JobTypeTrashPurge, func(ctx context.Context, job *models.Job) error {
+		result, err := trash.Purge(time.Now())
+		if result.Microposts > 0 || result.Users > 0 {
+			log.Printf("purge: removed %d microposts and %d users", result.Microposts, result.Users)
+		}
+		return err
+	})
+
+	q.Register(JobTypeJobPrune, func(ctx context.Context, job *models.Job) error {
+		count, err := q.Prune(time.Now().Add(-JobRetention))
+		if count > 0 {
+			log.Printf("jobs: pruned %d finished jobs", count)
+		}
+		return err
+	})
+
+	q.Register(JobTypeOutboxPrune, func(ctx context.Context, job *models.Job) error {
+		count, err := dispatcher.Prune(time.Now().Add(-OutboxRetention))
+		if count > 0 {
+			log.Printf("outbox: pruned %d delivered events", count)
+		}
+		return err
+	})
+}
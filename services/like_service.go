@@ -0,0 +1,100 @@
+package services
+
+import (
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LikeService struct {
+	db *gorm.DB
+}
+
+func NewLikeService(db *gorm.DB) *LikeService {
+	return &LikeService{db: db}
+}
+
+// Like はいいねを登録し、投稿者に通知する（既にいいね済みなら何もしない）
+func (s *LikeService) Like(userID, micropostID uint) (models.LikeStatusResponse, error) {
+	var micropost models.Micropost
+	if err := s.db.Select("id", "user_id").Scopes(published).First(&micropost, micropostID).Error; err != nil {
+		return models.LikeStatusResponse{}, err
+	}
+
+	var notifications []models.Notification
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		like := models.Like{UserID: userID, MicropostID: micropostID}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&like)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		var err error
+		notifications, err = notify(tx, models.NewNotification(models.NotificationTypeLike, micropost.UserID, userID, &micropost.ID))
+		if err != nil {
+			return err
+		}
+		return RecordEvent(tx, models.MicropostLiked{MicropostID: micropost.ID, UserID: userID, AuthorID: micropost.UserID})
+	})
+	if err != nil {
+		return models.LikeStatusResponse{}, err
+	}
+	publishNotifications(notifications)
+	return s.Status(userID, micropostID)
+}
+
+// Unlike はいいねを取り消し、その通知も削除する（いいねしていなければ何もしない）
+func (s *LikeService) Unlike(userID, micropostID uint) (models.LikeStatusResponse, error) {
+	var micropost models.Micropost
+	if err := s.db.Select("id", "user_id").Scopes(published).First(&micropost, micropostID).Error; err != nil {
+		return models.LikeStatusResponse{}, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND micropost_id = ?", userID, micropostID).Delete(&models.Like{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return retractNotification(tx, models.NotificationTypeLike, micropost.UserID, userID, &micropostID)
+	})
+	if err != nil {
+		return models.LikeStatusResponse{}, err
+	}
+	return s.Status(userID, micropostID)
+}
+
+// Status はマイクロポストのいいね数と閲覧者のいいね状態を返す（ゴミ箱の中のユーザーのいいねは数えない）
+func (s *LikeService) Status(userID, micropostID uint) (models.LikeStatusResponse, error) {
+	status := models.LikeStatusResponse{MicropostID: micropostID}
+	err := s.db.Model(&models.Like{}).
+		Select("COUNT(*) AS like_count, COALESCE(BOOL_OR(user_id = ?), false) AS liked_by_me", userID).
+		Where("micropost_id = ?", micropostID).
+		Scopes(excludeDeletedUsers("user_id")).
+		Scan(&status).Error
+	return status, err
+}
+
+// Likers はマイクロポストにいいねしたユーザーを新しい順に返す（ゴミ箱の中のユーザーは数えず、返さない）
+func (s *LikeService) Likers(micropostID uint, page models.PageQuery) ([]models.User, int64, error) {
+	if err := s.db.Select("id").Scopes(published).First(&models.Micropost{}, micropostID).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err := s.db.Model(&models.Like{}).
+		Where("micropost_id = ?", micropostID).
+		Scopes(excludeDeletedUsers("user_id")).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err = s.db.Joins("JOIN likes ON likes.user_id = users.id").
+		Where("likes.micropost_id = ?", micropostID).
+		Order("likes.created_at DESC, likes.id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Find(&users).Error
+	return users, total, err
+}
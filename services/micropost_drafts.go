@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// published は公開済みの投稿だけに絞る（下書き・予約投稿は投稿者本人の下書き一覧にだけ出す）
+func published(db *gorm.DB) *gorm.DB {
+	return db.Where("microposts.status = ?", models.MicropostStatusPublished)
+}
+
+// unpublished は userID の下書き・予約投稿だけに絞る
+func unpublished(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("microposts.user_id = ? AND microposts.status <> ?", userID, models.MicropostStatusPublished)
+	}
+}
+
+// CreateDraft は下書き・予約投稿を作成する。
+// ハッシュタグ・メンションの保存と自動モデレーション、通知は公開するときに行う。
+func (s *MicropostService) CreateDraft(userID uint, req models.DraftRequest) (*models.Micropost, error) {
+	draft := models.Micropost{UserID: userID, Kind: models.MicropostKindPost}
+	req.Apply(&draft)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkNotSuspended(tx, userID); err != nil {
+			return err
+		}
+		return tx.Omit("Tags", "Mentions").Create(&draft).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Draft(draft.ID, userID)
+}
+
+// Drafts は投稿者本人の下書き・予約投稿を返す（予約投稿を公開予定の早い順に、その後に下書きを更新の新しい順に）
+func (s *MicropostService) Drafts(userID uint, page models.PageQuery) ([]models.Micropost, int64, error) {
+	query := s.db.Model(&models.Micropost{}).Scopes(unpublished(userID))
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var drafts []models.Micropost
+	err := preloadRelations(query.Session(&gorm.Session{})).
+		Order("publish_at ASC NULLS LAST, updated_at DESC, id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Find(&drafts).Error
+	return drafts, total, err
+}
+
+// Draft は投稿者本人の下書き・予約投稿を返す（他人の下書きは見つからないものとして扱う）
+func (s *MicropostService) Draft(id, userID uint) (*models.Micropost, error) {
+	var draft models.Micropost
+	if err := preloadRelations(s.db).Scopes(unpublished(userID)).First(&draft, id).Error; err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// UpdateDraft は下書き・予約投稿のタイトル・本文と公開予定の日時を更新する。
+// publish_at を省略すると下書きに戻す。公開済みになった投稿は見つからないものとして扱う。
+func (s *MicropostService) UpdateDraft(id, userID uint, req models.DraftRequest) (*models.Micropost, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var draft models.Micropost
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scopes(unpublished(userID)).
+			First(&draft, id).Error
+		if err != nil {
+			return err
+		}
+		req.Apply(&draft)
+		return tx.Model(&draft).Select("Title", "Body", "Status", "PublishAt").Updates(&draft).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Draft(id, userID)
+}
+
+// DeleteDraft は下書き・予約投稿を完全に削除する（公開していないのでゴミ箱には移さない）
+func (s *MicropostService) DeleteDraft(id, userID uint) error {
+	result := s.db.Unscoped().Scopes(unpublished(userID)).Delete(&models.Micropost{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PublishDraft は下書き・予約投稿をすぐに公開する
+func (s *MicropostService) PublishDraft(id, userID uint) (*models.Micropost, error) {
+	var draft models.Micropost
+	var notifications []models.Notification
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scopes(unpublished(userID)).
+			First(&draft, id).Error
+		if err != nil {
+			return err
+		}
+		if err := checkNotSuspended(tx, userID); err != nil {
+			return err
+		}
+		notifications, err = publishDraft(tx, &draft, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	publishNotifications(notifications)
+	if !draft.ShadowHidden {
+		s.publishMicropost(draft.ID)
+	}
+	return s.reload(userID, draft.ID)
+}
+
+// publishDraft は tx でロックした下書き・予約投稿を自動モデレーションにかけて公開し、コミット後に配信する通知を返す。
+// 公開した日時を CreatedAt にするので、タイムラインでは公開した時点の新着として並ぶ。
+func publishDraft(tx *gorm.DB, draft *models.Micropost, now time.Time) ([]models.Notification, error) {
+	verdict, err := screenContent(tx, draft)
+	if err != nil {
+		return nil, err
+	}
+	draft.Status = models.MicropostStatusPublished
+	draft.PublishAt = nil
+	draft.CreatedAt = now
+	if err := tx.Model(draft).Select("Status", "PublishAt", "CreatedAt", "ShadowHidden").Updates(draft).Error; err != nil {
+		return nil, err
+	}
+	return finishPublish(tx, draft, verdict)
+}
+
+// PublishScheduled は公開予定の日時が now を過ぎた予約投稿を古い順に1件ずつ公開し、公開した件数を返す。
+// 行を SKIP LOCKED でロックして公開までを1つのトランザクションで行うので、複数のワーカーが同時に動いても二重に公開しない。
+// 自動モデレーションで拒否された投稿は下書きに戻し、利用停止中のユーザーの投稿は停止が解除されるまで公開しない。
+func (s *MicropostService) PublishScheduled(now time.Time) (int, error) {
+	count := 0
+	for {
+		var draft models.Micropost
+		var notifications []models.Notification
+		found, rejected := false, false
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND publish_at <= ?", models.MicropostStatusScheduled, now).
+				Where("user_id NOT IN (SELECT id FROM users WHERE suspended_at IS NOT NULL)").
+				Order("publish_at, id").
+				Take(&draft).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			found = true
+
+			notifications, err = publishDraft(tx, &draft, now)
+			var contentErr *ContentRejectedError
+			if errors.As(err, &contentErr) {
+				rejected = true
+				return tx.Model(&models.Micropost{}).Where("id = ?", draft.ID).
+					Updates(map[string]interface{}{"status": models.MicropostStatusDraft, "publish_at": nil}).Error
+			}
+			return err
+		})
+		if err != nil || !found {
+			return count, err
+		}
+		if rejected {
+			log.Printf("scheduled: micropost %d rejected by the content filter, moved back to drafts", draft.ID)
+			continue
+		}
+		count++
+		publishNotifications(notifications)
+		if !draft.ShadowHidden {
+			s.publishMicropost(draft.ID)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+// syncMentions はタイトルと本文の @handle をユーザーに解決して mentions を置き換え、
+// 新たにメンションされたユーザー（投稿者本人を除く）への通知を返す（通知の作成は呼び出し側で行う）。
+// 投稿者との間にブロックがあるユーザーはメンションしない。
+func syncMentions(tx *gorm.DB, micropost *models.Micropost) ([]models.Notification, error) {
+	var previous []uint
+	if err := tx.Model(&models.Mention{}).Where("micropost_id = ?", micropost.ID).Distinct().Pluck("user_id", &previous).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("micropost_id = ?", micropost.ID).Delete(&models.Mention{}).Error; err != nil {
+		return nil, err
+	}
+
+	entities := append(models.FindMentions("title", micropost.Title), models.FindMentions("body", micropost.Body)...)
+	usersByHandle, err := resolveHandles(tx, entities)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]uint, 0, len(usersByHandle))
+	for _, id := range usersByHandle {
+		userIDs = append(userIDs, id)
+	}
+	blocked, err := blockedWith(tx, micropost.UserID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions := make([]models.Mention, 0, len(entities))
+	for _, entity := range entities {
+		userID, ok := usersByHandle[models.HandleKey(entity.Text)]
+		if !ok || blocked[userID] {
+			continue
+		}
+		mentions = append(mentions, models.Mention{
+			MicropostID: micropost.ID,
+			UserID:      userID,
+			Field:       entity.Field,
+			Start:       entity.Start,
+			End:         entity.End,
+			Text:        entity.Text,
+		})
+	}
+	micropost.Mentions = mentions
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+	if err := tx.Create(&mentions).Error; err != nil {
+		return nil, err
+	}
+
+	notified := make(map[uint]bool, len(previous))
+	for _, id := range previous {
+		notified[id] = true
+	}
+	var notifications []models.Notification
+	for _, mention := range mentions {
+		if notified[mention.UserID] {
+			continue
+		}
+		notified[mention.UserID] = true
+		notifications = append(notifications, models.NewNotification(models.NotificationTypeMention, mention.UserID, micropost.UserID, &micropost.ID))
+	}
+	return notifications, nil
+}
+
+// resolveHandles はメンションのハンドルをユーザー ID に解決する（先頭から MaxMentionsPerMicropost 人まで）
+func resolveHandles(tx *gorm.DB, entities []models.TextEntity) (map[string]uint, error) {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, entity := range entities {
+		key := models.HandleKey(entity.Text)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+		if len(keys) == models.MaxMentionsPerMicropost {
+			break
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	var users []models.User
+	if err := tx.Select("id", "handle").Where("lower(handle) IN ?", keys).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	usersByHandle := make(map[string]uint, len(users))
+	for _, user := range users {
+		usersByHandle[models.HandleKey(user.Handle)] = user.ID
+	}
+	return usersByHandle, nil
+}
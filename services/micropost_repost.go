@@ -0,0 +1,120 @@
+package services
+
+import (
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// excludeOrphanReposts は元投稿が削除された（ゴミ箱の中を含む）コメントなしの再投稿を除外する
+func excludeOrphanReposts(db *gorm.DB) *gorm.DB {
+	return db.Where("NOT (microposts.kind = ? AND (microposts.repost_of_id IS NULL OR microposts.repost_of_id IN ("+deletedMicropostsSQL+")))",
+		models.MicropostKindRepost)
+}
+
+// resolveRepostTarget は再投稿の対象を返す。コメントなしの再投稿を再投稿した場合は元投稿を対象にする
+func (s *MicropostService) resolveRepostTarget(originalID uint) (uint, error) {
+	var original models.Micropost
+	if err := s.db.Select("id", "kind", "repost_of_id").Scopes(published).First(&original, originalID).Error; err != nil {
+		return 0, err
+	}
+	if original.Kind == models.MicropostKindRepost {
+		if original.RepostOfID == nil {
+			return 0, gorm.ErrRecordNotFound
+		}
+		return *original.RepostOfID, nil
+	}
+	return original.ID, nil
+}
+
+// Repost はコメントなしで再投稿する。同じ投稿の再投稿は1ユーザー1回まで
+func (s *MicropostService) Repost(userID, originalID uint) (*models.Micropost, error) {
+	targetID, err := s.resolveRepostTarget(originalID)
+	if err != nil {
+		return nil, err
+	}
+
+	var original models.Micropost
+	if err := s.db.Select("id", "user_id").First(&original, targetID).Error; err != nil {
+		return nil, err
+	}
+
+	repost := models.Micropost{
+		UserID:     userID,
+		Kind:       models.MicropostKindRepost,
+		RepostOfID: &targetID,
+	}
+	var notifications []models.Notification
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkNotSuspended(tx, userID); err != nil {
+			return err
+		}
+		// 一意制約 idx_microposts_unique_repost に違反した場合は挿入されない
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&repost)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadyReposted
+		}
+		notifications, err = notify(tx, models.NewNotification(models.NotificationTypeRepost, original.UserID, userID, &targetID))
+		if err != nil {
+			return err
+		}
+		return RecordEvent(tx, micropostCreated(&repost))
+	})
+	if err != nil {
+		return nil, err
+	}
+	publishNotifications(notifications)
+	s.publishMicropost(repost.ID)
+	return s.reload(userID, repost.ID)
+}
+
+// Quote はコメント付きで引用投稿する
+func (s *MicropostService) Quote(quote *models.Micropost, originalID uint) (*models.Micropost, error) {
+	targetID, err := s.resolveRepostTarget(originalID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.Kind = models.MicropostKindQuote
+	quote.RepostOfID = &targetID
+	if err := s.Create(quote); err != nil {
+		return nil, err
+	}
+	return s.reload(quote.UserID, quote.ID)
+}
+
+// Unrepost はコメントなしの再投稿を取り消し、その通知も削除する（再投稿していなければ何もしない）
+func (s *MicropostService) Unrepost(userID, originalID uint) error {
+	targetID, err := s.resolveRepostTarget(originalID)
+	if err != nil {
+		return err
+	}
+	var original models.Micropost
+	if err := s.db.Select("id", "user_id").First(&original, targetID).Error; err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("user_id = ? AND repost_of_id = ? AND kind = ?", userID, targetID, models.MicropostKindRepost).
+			Delete(&models.Micropost{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return retractNotification(tx, models.NotificationTypeRepost, original.UserID, userID, &targetID)
+	})
+}
+
+// reload はレスポンス用に関連と集計情報を含めて読み込み直す
+func (s *MicropostService) reload(viewerID, id uint) (*models.Micropost, error) {
+	microposts, err := s.findByIDs(viewerID, []uint{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(microposts) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &microposts[0], nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+// recordRevision は投稿の現在のタイトルと本文を次の版として保存する（コメントなしの再投稿は内容がないので保存しない）。
+// createdAt はその版になった日時。
+func recordRevision(tx *gorm.DB, micropost *models.Micropost, createdAt time.Time) error {
+	if micropost.Kind == models.MicropostKindRepost {
+		return nil
+	}
+	var version int
+	err := tx.Model(&models.MicropostRevision{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("micropost_id = ?", micropost.ID).
+		Scan(&version).Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.MicropostRevision{
+		MicropostID: micropost.ID,
+		Version:     version + 1,
+		Title:       micropost.Title,
+		Body:        micropost.Body,
+		CreatedAt:   createdAt,
+	}).Error
+}
+
+// recordOriginalRevision は版の履歴がない投稿（履歴を残す前に作成した投稿）を編集する前に、
+// 編集前の内容を作成した日時の版 1 として保存する
+func recordOriginalRevision(tx *gorm.DB, micropost *models.Micropost) error {
+	var count int64
+	if err := tx.Model(&models.MicropostRevision{}).Where("micropost_id = ?", micropost.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return recordRevision(tx, micropost, micropost.CreatedAt)
+}
+
+// Revisions は投稿の版を新しい順に返す。続けて、ページの最も古い版の1つ前の版（版 1 までなら nil）を差分用に返す。
+// 閲覧者に見えない投稿は見つからないものとして扱うが、管理者はゴミ箱の中や非表示にされた投稿の履歴も見られる。
+func (s *MicropostService) Revisions(id, viewerID uint, isAdmin bool, page models.PageQuery) ([]models.MicropostRevision, *models.MicropostRevision, int64, error) {
+	query := s.db.Select("id").Scopes(published)
+	if isAdmin {
+		query = query.Unscoped()
+	} else {
+		query = query.Where("(hidden_at IS NULL AND NOT shadow_hidden) OR user_id = ?", viewerID)
+	}
+	if err := query.First(&models.Micropost{}, id).Error; err != nil {
+		return nil, nil, 0, err
+	}
+
+	revisions := s.db.Model(&models.MicropostRevision{}).Where("micropost_id = ?", id)
+	var total int64
+	if err := revisions.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, 0, err
+	}
+
+	// 差分の計算のために1件多く読む
+	var found []models.MicropostRevision
+	err := revisions.Session(&gorm.Session{}).
+		Order("version DESC").
+		Limit(page.Limit() + 1).
+		Offset(page.Offset()).
+		Find(&found).Error
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(found) <= page.Limit() {
+		return found, nil, total, nil
+	}
+	return found[:page.Limit()], &found[page.Limit()], total, nil
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+// 検索戦略の名前（レスポンスの strategy に入る）
+const (
+	SearchStrategyFullText = "fulltext"
+	SearchStrategyTrigram  = "trigram"
+)
+
+// スニペット中のハイライト位置を示すマーカー（HTML エスケープ後に <mark> へ置き換える）
+const (
+	highlightStart = "\ue000"
+	highlightStop  = "\ue001"
+)
+
+// 部分一致検索のスニペットに含める前後の文字数
+const snippetRadius = 40
+
+// searchStrategy はクエリのトークン化と順位付けの方法
+type searchStrategy interface {
+	name() string
+	where(db *gorm.DB) *gorm.DB
+	// rank と snippet 列を返す SELECT 式
+	selectExpr() (string, []interface{})
+	snippet(m *models.Micropost, sqlSnippet string) string
+}
+
+// newSearchStrategy は空白で単語を区切らない言語（日本語・中国語・韓国語）を含むクエリには
+// pg_trgm による部分一致を、それ以外には tsvector の全文検索を使う
+func newSearchStrategy(query string) searchStrategy {
+	terms := strings.Fields(query)
+	for _, r := range query {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return trigramSearch{query: query, terms: terms}
+		}
+	}
+	return fullTextSearch{query: query}
+}
+
+// fullTextSearch は search_vector 列（GIN インデックス）を使う全文検索
+type fullTextSearch struct {
+	query string
+}
+
+func (fullTextSearch) name() string { return SearchStrategyFullText }
+
+func (s fullTextSearch) where(db *gorm.DB) *gorm.DB {
+	return db.Where("microposts.search_vector @@ websearch_to_tsquery('simple', ?)", s.query)
+}
+
+func (s fullTextSearch) selectExpr() (string, []interface{}) {
+	options := "StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", MaxWords=30, MinWords=10, MaxFragments=2"
+	return `ts_rank_cd(microposts.search_vector, websearch_to_tsquery('simple', ?)) AS rank,
+		ts_headline('simple', coalesce(nullif(microposts.body, ''), microposts.title), websearch_to_tsquery('simple', ?), ?) AS snippet`,
+		[]interface{}{s.query, s.query, options}
+}
+
+func (fullTextSearch) snippet(_ *models.Micropost, sqlSnippet string) string {
+	return renderSnippet(sqlSnippet)
+}
+
+// trigramSearch は各語の ILIKE 部分一致（pg_trgm の GIN インデックス）で絞り込む
+type trigramSearch struct {
+	query string
+	terms []string
+}
+
+func (trigramSearch) name() string { return SearchStrategyTrigram }
+
+func (s trigramSearch) where(db *gorm.DB) *gorm.DB {
+	for _, term := range s.terms {
+		pattern := "%" + escapeLike(term) + "%"
+		db = db.Where("(microposts.title ILIKE ? OR microposts.body ILIKE ?)", pattern, pattern)
+	}
+	return db
+}
+
+func (s trigramSearch) selectExpr() (string, []interface{}) {
+	return `word_similarity(?, microposts.title || ' ' || microposts.body) AS rank, '' AS snippet`,
+		[]interface{}{s.query}
+}
+
+func (s trigramSearch) snippet(m *models.Micropost, _ string) string {
+	text := m.Body
+	if text == "" {
+		text = m.Title
+	}
+	return renderSnippet(highlightTerms(text, s.terms))
+}
+
+// highlightTerms は最初に一致した語の周辺を切り出し、一致箇所をマーカーで囲む
+func highlightTerms(text string, terms []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.Map(unicode.ToLower, text))
+
+	matched := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		needle := []rune(strings.Map(unicode.ToLower, term))
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) != string(needle) {
+				continue
+			}
+			for j := i; j < i+len(needle); j++ {
+				matched[j] = true
+			}
+			if first == -1 || i < first {
+				first = i
+			}
+		}
+	}
+
+	start, end := 0, len(runes)
+	if first > snippetRadius {
+		start = first - snippetRadius
+	}
+	if first >= 0 && first+snippetRadius*2 < end {
+		end = first + snippetRadius*2
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if matched[i] && (i == start || !matched[i-1]) {
+			b.WriteString(highlightStart)
+		}
+		b.WriteRune(runes[i])
+		if matched[i] && (i == end-1 || !matched[i+1]) {
+			b.WriteString(highlightStop)
+		}
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// renderSnippet は本文を HTML エスケープし、マーカーを <mark> タグに置き換える
+func renderSnippet(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, highlightStart, "<mark>")
+	return strings.ReplaceAll(s, highlightStop, "</mark>")
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Search は検索語とフィルター（投稿者・期間）でマイクロポストを検索し、関連度順に返す（閲覧者から見えないユーザーの投稿は除く）
+func (s *MicropostService) Search(viewerID uint, params models.MicropostSearchQuery) ([]models.MicropostSearchHit, int64, string, error) {
+	strategy := newSearchStrategy(params.Q)
+
+	query := strategy.where(s.db.Model(&models.Micropost{})).Scopes(published, excludeModerated(viewerID), excludeHiddenMicroposts(viewerID))
+	if params.UserID != 0 {
+		query = query.Where("microposts.user_id = ?", params.UserID)
+	}
+	if params.From != nil {
+		query = query.Where("microposts.created_at >= ?", *params.From)
+	}
+	if params.To != nil {
+		query = query.Where("microposts.created_at <= ?", *params.To)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, strategy.name(), err
+	}
+
+	var rows []struct {
+		ID      uint
+		Rank    float64
+		Snippet string
+	}
+	selectExpr, args := strategy.selectExpr()
+	err := query.Session(&gorm.Session{}).
+		Select("microposts.id, "+selectExpr, args...).
+		Order("rank DESC, microposts.created_at DESC").
+		Limit(params.Limit()).
+		Offset(params.Offset()).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, total, strategy.name(), err
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	microposts, err := s.findByIDs(viewerID, ids)
+	if err != nil {
+		return nil, total, strategy.name(), err
+	}
+	byID := make(map[uint]*models.Micropost, len(microposts))
+	for i := range microposts {
+		byID[microposts[i].ID] = &microposts[i]
+	}
+
+	hits := make([]models.MicropostSearchHit, 0, len(rows))
+	for _, row := range rows {
+		m, ok := byID[row.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, models.MicropostSearchHit{
+			Micropost: *m,
+			Rank:      row.Rank,
+			Snippet:   strategy.snippet(m, row.Snippet),
+		})
+	}
+	return hits, total, strategy.name(), nil
+}
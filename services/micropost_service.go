@@ -1,9 +1,12 @@
 package services
 
 import (
+	"time"
+
 	"go-gin-gorm-minimum/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MicropostService struct {
@@ -15,17 +18,181 @@ func NewMicropostService(db *gorm.DB) *MicropostService {
 }
 
 func (s *MicropostService) Create(micropost *models.Micropost) error {
-	return s.db.Create(micropost).Error
+	if micropost.Kind == "" {
+		micropost.Kind = models.MicropostKindPost
+	}
+	if micropost.Status == "" {
+		micropost.Status = models.MicropostStatusPublished
+	}
+	var notifications []models.Notification
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkNotSuspended(tx, micropost.UserID); err != nil {
+			return err
+		}
+		if err := checkReplyAllowed(tx, micropost); err != nil {
+			return err
+		}
+		verdict, err := screenContent(tx, micropost)
+		if err != nil {
+			return err
+		}
+		if err := tx.Omit("Tags", "Mentions").Create(micropost).Error; err != nil {
+			return err
+		}
+		notifications, err = finishPublish(tx, micropost, verdict)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	publishNotifications(notifications)
+	if !micropost.ShadowHidden {
+		s.publishMicropost(micropost.ID)
+	}
+	return nil
+}
+
+// finishPublish は公開した投稿の最初の版とハッシュタグ・メンションを保存し、自動モデレーションの通報・通知・ドメインイベントを作成する。
+// コミット後に配信する通知を返す（隠した投稿は通知しない）。
+func finishPublish(tx *gorm.DB, micropost *models.Micropost, verdict models.ContentVerdict) ([]models.Notification, error) {
+	if err := recordRevision(tx, micropost, micropost.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := syncTags(tx, micropost); err != nil {
+		return nil, err
+	}
+	if err := fileContentReport(tx, micropost, verdict); err != nil {
+		return nil, err
+	}
+	mentioned, err := syncMentions(tx, micropost)
+	if err != nil {
+		return nil, err
+	}
+	created, err := createdNotifications(tx, micropost)
+	if err != nil || micropost.ShadowHidden {
+		return nil, err
+	}
+	notifications, err := notify(tx, append(mentioned, created...)...)
+	if err != nil {
+		return nil, err
+	}
+	return notifications, RecordEvent(tx, micropostCreated(micropost))
+}
+
+// micropostCreated は作成した投稿のドメインイベントを返す
+func micropostCreated(micropost *models.Micropost) models.MicropostCreated {
+	return models.MicropostCreated{
+		MicropostID: micropost.ID,
+		UserID:      micropost.UserID,
+		Kind:        micropost.Kind,
+		ParentID:    micropost.ParentID,
+		RepostOfID:  micropost.RepostOfID,
+		Title:       micropost.Title,
+		Body:        micropost.Body,
+	}
+}
+
+// createdNotifications は返信先・引用元の投稿者への通知を返す（通知の作成は呼び出し側で行う）
+func createdNotifications(tx *gorm.DB, micropost *models.Micropost) ([]models.Notification, error) {
+	var notificationType string
+	var targetID *uint
+	switch {
+	case micropost.ParentID != nil:
+		notificationType, targetID = models.NotificationTypeReply, micropost.ParentID
+	case micropost.Kind == models.MicropostKindQuote && micropost.RepostOfID != nil:
+		notificationType, targetID = models.NotificationTypeQuote, micropost.RepostOfID
+	default:
+		return nil, nil
+	}
+
+	var target models.Micropost
+	if err := tx.Select("id", "user_id").First(&target, *targetID).Error; err != nil {
+		return nil, err
+	}
+	return []models.Notification{models.NewNotification(notificationType, target.UserID, micropost.UserID, &target.ID)}, nil
+}
+
+// Update は投稿者本人のマイクロポストのタイトルと本文を更新する（コメントなしの再投稿は編集できず、下書き・予約投稿は UpdateDraft で編集する）。
+// 更新後の内容も自動モデレーションにかける。内容が変わったときは新しい版を保存し、編集した日時を記録する。
+func (s *MicropostService) Update(id, userID uint, req models.MicropostRequest) (*models.Micropost, error) {
+	var notifications []models.Notification
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var micropost models.Micropost
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(published).First(&micropost, id).Error; err != nil {
+			return err
+		}
+		if micropost.UserID != userID || micropost.Kind == models.MicropostKindRepost {
+			return ErrForbidden
+		}
+
+		edited := micropost.Title != req.Title || micropost.Body != req.Body
+		if edited {
+			if err := recordOriginalRevision(tx, &micropost); err != nil {
+				return err
+			}
+		}
+		micropost.Title = req.Title
+		micropost.Body = req.Body
+		verdict, err := screenContent(tx, &micropost)
+		if err != nil {
+			return err
+		}
+		if edited {
+			now := time.Now()
+			micropost.EditedAt = &now
+			if err := recordRevision(tx, &micropost, now); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&micropost).Select("Title", "Body", "ShadowHidden", "EditedAt").Updates(&micropost).Error; err != nil {
+			return err
+		}
+		if err := syncTags(tx, &micropost); err != nil {
+			return err
+		}
+		if err := fileContentReport(tx, &micropost, verdict); err != nil {
+			return err
+		}
+		mentioned, err := syncMentions(tx, &micropost)
+		if err != nil || micropost.ShadowHidden {
+			return err
+		}
+		notifications, err = notify(tx, mentioned...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	publishNotifications(notifications)
+	return s.reload(userID, id)
 }
 
-func (s *MicropostService) GetByID(id string) (*models.Micropost, error) {
+// GetByID はマイクロポストを返す（非表示にされた投稿・自動モデレーションで隠した投稿は投稿者本人にだけ返し、
+// 下書き・予約投稿と利用停止中のユーザーの投稿は投稿者本人にも返さない）
+func (s *MicropostService) GetByID(id, viewerID uint) (*models.Micropost, error) {
 	var micropost models.Micropost
-	err := s.db.First(&micropost, id).Error
-	return &micropost, err
+	err := preloadRelations(s.db).
+		Scopes(published, excludeSuspendedUsers("microposts.user_id")).
+		Where("(hidden_at IS NULL AND NOT shadow_hidden) OR user_id = ?", viewerID).
+		First(&micropost, id).Error
+	if err != nil {
+		return &micropost, err
+	}
+
+	microposts := []models.Micropost{micropost}
+	err = s.loadStats(viewerID, microposts)
+	return &microposts[0], err
 }
 
-func (s *MicropostService) GetAll() ([]models.Micropost, error) {
+func (s *MicropostService) GetAll(viewerID uint) ([]models.Micropost, error) {
 	var microposts []models.Micropost
-	err := s.db.Preload("User").Find(&microposts).Error
+	err := preloadRelations(s.db).
+		Scopes(published, excludeOrphanReposts, excludeModerated(viewerID), excludeHiddenMicroposts(viewerID)).
+		Order("created_at DESC, id DESC").
+		Find(&microposts).Error
+	if err != nil {
+		return microposts, err
+	}
+	err = s.loadStats(viewerID, microposts)
 	return microposts, err
 }
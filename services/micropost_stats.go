@@ -0,0 +1,131 @@
+package services
+
+import (
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+// preloadRelations はレスポンスに必要な関連（投稿者、タグ、メンション、再投稿・引用元とその関連）を読み込む
+func preloadRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("User").Preload("Tags").Preload("Mentions", orderByID).
+		Preload("RepostOf.User").Preload("RepostOf.Tags").Preload("RepostOf.Mentions", orderByID)
+}
+
+// loadStats は一覧のマイクロポスト（と再投稿・引用元）にいいね数などを集計して設定する。
+// 投稿ごとに問い合わせず、対象 ID 全体をテーブルごとに1回の GROUP BY で集計する（N+1 を避ける）。
+func (s *MicropostService) loadStats(viewerID uint, microposts []models.Micropost) error {
+	stats := make(map[uint][]*models.MicropostStats)
+	for i := range microposts {
+		stats[microposts[i].ID] = append(stats[microposts[i].ID], &microposts[i].Stats)
+		if original := microposts[i].RepostOf; original != nil {
+			stats[original.ID] = append(stats[original.ID], &original.Stats)
+		}
+	}
+	if len(stats) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(stats))
+	for id := range stats {
+		ids = append(ids, id)
+	}
+
+	var likeRows []struct {
+		MicropostID uint
+		LikeCount   int64
+		LikedByMe   bool
+	}
+	err := s.db.Model(&models.Like{}).
+		Select("micropost_id, COUNT(*) AS like_count, BOOL_OR(user_id = ?) AS liked_by_me", viewerID).
+		Where("micropost_id IN ?", ids).
+		Scopes(excludeDeletedUsers("user_id")).
+		Group("micropost_id").
+		Scan(&likeRows).Error
+	if err != nil {
+		return err
+	}
+
+	var replyRows []struct {
+		ParentID   uint
+		ReplyCount int64
+	}
+	err = s.db.Model(&models.Micropost{}).
+		Select("parent_id, COUNT(*) AS reply_count").
+		Where("parent_id IN ?", ids).
+		Group("parent_id").
+		Scan(&replyRows).Error
+	if err != nil {
+		return err
+	}
+
+	var repostRows []struct {
+		RepostOfID   uint
+		RepostCount  int64
+		QuoteCount   int64
+		RepostedByMe bool
+	}
+	err = s.db.Model(&models.Micropost{}).
+		Select(`repost_of_id,
+			COUNT(*) FILTER (WHERE kind = ?) AS repost_count,
+			COUNT(*) FILTER (WHERE kind = ?) AS quote_count,
+			COALESCE(BOOL_OR(kind = ? AND user_id = ?), false) AS reposted_by_me`,
+			models.MicropostKindRepost, models.MicropostKindQuote, models.MicropostKindRepost, viewerID).
+		Where("repost_of_id IN ?", ids).
+		Group("repost_of_id").
+		Scan(&repostRows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range likeRows {
+		for _, st := range stats[row.MicropostID] {
+			st.LikeCount = row.LikeCount
+			st.LikedByMe = row.LikedByMe
+		}
+	}
+	for _, row := range replyRows {
+		for _, st := range stats[row.ParentID] {
+			st.ReplyCount = row.ReplyCount
+		}
+	}
+	for _, row := range repostRows {
+		for _, st := range stats[row.RepostOfID] {
+			st.RepostCount = row.RepostCount
+			st.QuoteCount = row.QuoteCount
+			st.RepostedByMe = row.RepostedByMe
+		}
+	}
+	return nil
+}
+
+// findByIDs は ID の並び順を保ったままマイクロポストを読み込み、集計情報を設定する
+func (s *MicropostService) findByIDs(viewerID uint, ids []uint) ([]models.Micropost, error) {
+	if len(ids) == 0 {
+		return []models.Micropost{}, nil
+	}
+
+	var found []models.Micropost
+	if err := preloadRelations(s.db).Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Micropost, len(found))
+	for _, m := range found {
+		byID[m.ID] = m
+	}
+
+	microposts := make([]models.Micropost, 0, len(ids))
+	for _, id := range ids {
+		if m, ok := byID[id]; ok {
+			microposts = append(microposts, m)
+		}
+	}
+	if err := s.loadStats(viewerID, microposts); err != nil {
+		return nil, err
+	}
+	return microposts, nil
+}
+
+func orderByID(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// syncTags はタイトルと本文から抽出したハッシュタグで micropost_tags を置き換える
+func syncTags(tx *gorm.DB, micropost *models.Micropost) error {
+	names := models.ExtractHashtags(micropost.Title, micropost.Body)
+
+	tags := make([]models.Tag, 0, len(names))
+	if len(names) > 0 {
+		for _, name := range names {
+			tags = append(tags, models.Tag{Name: name})
+		}
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&tags).Error; err != nil {
+			return err
+		}
+		// 既存のタグは INSERT されず ID が埋まらないので読み直す
+		tags = tags[:0]
+		if err := tx.Where("name IN ?", names).Find(&tags).Error; err != nil {
+			return err
+		}
+	}
+
+	micropost.Tags = tags
+	return tx.Model(micropost).Association("Tags").Replace(tags)
+}
+
+// GetByTag はハッシュタグの付いたマイクロポストを新しい順に返す（閲覧者から見えないユーザーの投稿は除く）
+func (s *MicropostService) GetByTag(name string, viewerID uint, page models.PageQuery) ([]models.Micropost, int64, error) {
+	query := s.db.Model(&models.Micropost{}).
+		Joins("JOIN micropost_tags ON micropost_tags.micropost_id = microposts.id").
+		Joins("JOIN tags ON tags.id = micropost_tags.tag_id").
+		Where("tags.name = ?", models.NormalizeTagName(name)).
+		Scopes(published, excludeModerated(viewerID), excludeHiddenMicroposts(viewerID))
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var ids []uint
+	err := query.Session(&gorm.Session{}).
+		Order("microposts.created_at DESC, microposts.id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Pluck("microposts.id", &ids).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	microposts, err := s.findByIDs(viewerID, ids)
+	return microposts, total, err
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+// スレッドをたどる最大の深さ（循環や極端に深いスレッドへの保険）
+const maxThreadDepth = 50
+
+// CreateReply は parentID のマイクロポストへの返信を作成する
+func (s *MicropostService) CreateReply(parentID uint, reply *models.Micropost) error {
+	if err := s.db.Select("id").Scopes(published).First(&models.Micropost{}, parentID).Error; err != nil {
+		return err
+	}
+	reply.ParentID = &parentID
+	return s.Create(reply)
+}
+
+// checkReplyAllowed は返信先の投稿者との間にどちらかからのブロックがあれば ErrBlocked を返す
+func checkReplyAllowed(tx *gorm.DB, micropost *models.Micropost) error {
+	if micropost.ParentID == nil {
+		return nil
+	}
+	var parent models.Micropost
+	if err := tx.Select("id", "user_id").First(&parent, *micropost.ParentID).Error; err != nil {
+		return err
+	}
+	return checkNotBlocked(tx, micropost.UserID, parent.UserID)
+}
+
+// threadReplyVisibleSQL は返信 m が閲覧者に見えるかどうかの条件（published・excludeModerated・excludeHiddenUsers と同じ）。
+// 引数は公開の状態と閲覧者の ID 4つ
+const threadReplyVisibleSQL = `m.deleted_at IS NULL AND m.status = ? AND m.hidden_at IS NULL
+	AND (NOT m.shadow_hidden OR m.user_id = ?)
+	AND m.user_id NOT IN (SELECT id FROM users WHERE suspended_at IS NOT NULL)
+	AND m.user_id NOT IN (` + hiddenUsersSQL + `)`
+
+// Thread は起点の投稿、祖先（ルートから順に）と子孫（深さ優先順、ページネーション付き）を返す。
+// 閲覧者から見えない投稿は起点なら見つからないものとして扱い、祖先なら飛ばし、子孫ならその子孫ごと除く。
+func (s *MicropostService) Thread(id uint, viewerID uint, page models.PageQuery) (*models.MicropostThread, error) {
+	visible := func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(published, excludeModerated(viewerID), excludeHiddenMicroposts(viewerID))
+	}
+	if err := s.db.Select("id").Scopes(visible).First(&models.Micropost{}, id).Error; err != nil {
+		return nil, err
+	}
+
+	var ancestorIDs []uint
+	err := s.db.Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 1 AS depth FROM microposts WHERE id = ?
+			UNION ALL
+			SELECT m.id, m.parent_id, a.depth + 1
+			FROM microposts m JOIN ancestors a ON m.id = a.parent_id
+			WHERE a.depth < ?
+		)
+		SELECT id FROM ancestors WHERE id <> ? ORDER BY depth DESC`,
+		id, maxThreadDepth, id).Scan(&ancestorIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(ancestorIDs) > 0 {
+		var visibleIDs []uint
+		err := s.db.Model(&models.Micropost{}).Scopes(visible).Where("id IN ?", ancestorIDs).Pluck("id", &visibleIDs).Error
+		if err != nil {
+			return nil, err
+		}
+		shown := make(map[uint]bool, len(visibleIDs))
+		for _, visibleID := range visibleIDs {
+			shown[visibleID] = true
+		}
+		filtered := ancestorIDs[:0]
+		for _, ancestorID := range ancestorIDs {
+			if shown[ancestorID] {
+				filtered = append(filtered, ancestorID)
+			}
+		}
+		ancestorIDs = filtered
+	}
+
+	// 閲覧者から見えない返信（削除済み・非表示・利用停止中やブロック・ミュートしたユーザーの返信）はその子孫ごと除く
+	const descendantsCTE = `
+		WITH RECURSIVE descendants AS (
+			SELECT m.id, 1 AS depth, ARRAY[m.id] AS path FROM microposts m
+			WHERE m.parent_id = ? AND ` + threadReplyVisibleSQL + `
+			UNION ALL
+			SELECT m.id, d.depth + 1, d.path || m.id
+			FROM microposts m JOIN descendants d ON m.parent_id = d.id
+			WHERE d.depth < ? AND ` + threadReplyVisibleSQL + `
+		)`
+	replyVisible := []interface{}{models.MicropostStatusPublished, viewerID, viewerID, viewerID, viewerID}
+	args := append([]interface{}{id}, replyVisible...)
+	args = append(args, maxThreadDepth)
+	args = append(args, replyVisible...)
+
+	var total int64
+	err = s.db.Raw(descendantsCTE+` SELECT COUNT(*) FROM descendants`, args...).Scan(&total).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		ID    uint
+		Depth int
+	}
+	err = s.db.Raw(descendantsCTE+` SELECT id, depth FROM descendants ORDER BY path LIMIT ? OFFSET ?`,
+		append(args, page.Limit(), page.Offset())...).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := append([]uint{id}, ancestorIDs...)
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	loaded, err := s.findByIDs(viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Micropost, len(loaded))
+	for _, m := range loaded {
+		byID[m.ID] = m
+	}
+
+	thread := &models.MicropostThread{
+		Micropost:   byID[id],
+		Ancestors:   make([]models.Micropost, 0, len(ancestorIDs)),
+		Descendants: make([]models.ThreadNode, 0, len(rows)),
+		Total:       total,
+	}
+	for _, ancestorID := range ancestorIDs {
+		if m, ok := byID[ancestorID]; ok {
+			thread.Ancestors = append(thread.Ancestors, m)
+		}
+	}
+	for _, row := range rows {
+		if m, ok := byID[row.ID]; ok {
+			thread.Descendants = append(thread.Descendants, models.ThreadNode{Micropost: m, Depth: row.Depth})
+		}
+	}
+	return thread, nil
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+// Delete はマイクロポストをゴミ箱に移す（投稿者本人か管理者だけが削除できる）。
+// コメントなしの再投稿は復元の対象にせず、取り消しと同じく完全に削除する。
+func (s *MicropostService) Delete(id, userID uint, isAdmin bool) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var micropost models.Micropost
+		if err := tx.Select("id", "user_id", "kind", "repost_of_id").Scopes(published).First(&micropost, id).Error; err != nil {
+			return err
+		}
+		if micropost.UserID != userID && !isAdmin {
+			return ErrForbidden
+		}
+
+		if micropost.Kind == models.MicropostKindRepost {
+			if err := tx.Unscoped().Delete(&micropost).Error; err != nil {
+				return err
+			}
+			if micropost.RepostOfID != nil {
+				var original models.Micropost
+				if err := tx.Unscoped().Select("id", "user_id").First(&original, *micropost.RepostOfID).Error; err != nil {
+					return err
+				}
+				if err := retractNotification(tx, models.NotificationTypeRepost, original.UserID, micropost.UserID, micropost.RepostOfID); err != nil {
+					return err
+				}
+			}
+		} else if err := tx.Delete(&micropost).Error; err != nil {
+			return err
+		}
+		return RecordEvent(tx, models.MicropostDeleted{MicropostID: micropost.ID, UserID: micropost.UserID})
+	})
+}
+
+// Trash はゴミ箱の中のマイクロポストを削除した新しい順に返す
+func (s *MicropostService) Trash(userID uint, page models.PageQuery) ([]models.Micropost, int64, error) {
+	query := s.db.Unscoped().Model(&models.Micropost{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var microposts []models.Micropost
+	err := preloadRelations(query.Session(&gorm.Session{})).
+		Order("deleted_at DESC, id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Find(&microposts).Error
+	return microposts, total, err
+}
+
+// Restore はゴミ箱の中のマイクロポストを元に戻す（投稿者本人か管理者だけが復元でき、保存期間を過ぎていれば ErrRestoreExpired）。
+// 投稿者ごと削除された投稿はユーザーの復元でまとめて戻すので、ここでは見つからないものとして扱う。
+func (s *MicropostService) Restore(id, userID uint, isAdmin bool) (*models.Micropost, error) {
+	var micropost models.Micropost
+	err := s.db.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Scopes(excludeDeletedUsers("user_id")).
+		First(&micropost, id).Error
+	if err != nil {
+		return nil, err
+	}
+	if micropost.UserID != userID && !isAdmin {
+		return nil, ErrForbidden
+	}
+	if !models.Restorable(micropost.DeletedAt, time.Now()) {
+		return nil, ErrRestoreExpired
+	}
+
+	if err := s.db.Unscoped().Model(&micropost).Update("deleted_at", nil).Error; err != nil {
+		return nil, err
+	}
+	return s.reload(userID, id)
+}
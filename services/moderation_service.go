@@ -0,0 +1,293 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+type ModerationService struct {
+	db *gorm.DB
+}
+
+func NewModerationService(db *gorm.DB) *ModerationService {
+	return &ModerationService{db: db}
+}
+
+// excludeSuspendedUsers は column のユーザーが利用停止中のものを除く
+func excludeSuspendedUsers(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(column + " NOT IN (SELECT id FROM users WHERE suspended_at IS NOT NULL)")
+	}
+}
+
+// excludeModerated は非表示にされた投稿と利用停止中のユーザーの投稿、
+// 自動モデレーションで隠した他人の投稿を除く
+func excludeModerated(viewerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("microposts.hidden_at IS NULL").
+			Where("(NOT microposts.shadow_hidden OR microposts.user_id = ?)", viewerID).
+			Scopes(excludeSuspendedUsers("microposts.user_id"))
+	}
+}
+
+// ReportMicropost は投稿を通報する（自分の投稿は通報できず、同じ投稿への未対応の通報があれば ErrAlreadyReported）
+func (s *ModerationService) ReportMicropost(reporterID, micropostID uint, req models.ReportRequest) (*models.Report, error) {
+	var micropost models.Micropost
+	if err := s.db.Select("id", "user_id").Scopes(published).First(&micropost, micropostID).Error; err != nil {
+		return nil, err
+	}
+	report := models.Report{
+		ReporterID:   &reporterID,
+		TargetType:   models.ReportTargetMicropost,
+		MicropostID:  &micropost.ID,
+		TargetUserID: micropost.UserID,
+		Reason:       req.Reason,
+		Comment:      req.Comment,
+	}
+	return s.createReport(&report)
+}
+
+// ReportUser はユーザーを通報する（自分自身は通報できず、同じユーザーへの未対応の通報があれば ErrAlreadyReported）
+func (s *ModerationService) ReportUser(reporterID, userID uint, req models.ReportRequest) (*models.Report, error) {
+	if err := s.db.Select("id").First(&models.User{}, userID).Error; err != nil {
+		return nil, err
+	}
+	report := models.Report{
+		ReporterID:   &reporterID,
+		TargetType:   models.ReportTargetUser,
+		TargetUserID: userID,
+		Reason:       req.Reason,
+		Comment:      req.Comment,
+	}
+	return s.createReport(&report)
+}
+
+func (s *ModerationService) createReport(report *models.Report) (*models.Report, error) {
+	if *report.ReporterID == report.TargetUserID {
+		return nil, ErrCannotReportSelf
+	}
+
+	duplicate := s.db.Model(&models.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_user_id = ? AND status = ?",
+			*report.ReporterID, report.TargetType, report.TargetUserID, models.ReportStatusOpen)
+	if report.MicropostID != nil {
+		duplicate = duplicate.Where("micropost_id = ?", *report.MicropostID)
+	}
+	var count int64
+	if err := duplicate.Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrAlreadyReported
+	}
+
+	if err := s.db.Create(report).Error; err != nil {
+		return nil, err
+	}
+	return s.findReport(report.ID)
+}
+
+// preloadReport は通報の表示に必要な関連を読み込む
+func preloadReport(db *gorm.DB) *gorm.DB {
+	return db.Preload("Reporter").
+		Preload("TargetUser").
+		Preload("Micropost").
+		Preload("Micropost.User")
+}
+
+func (s *ModerationService) findReport(id uint) (*models.Report, error) {
+	var report models.Report
+	if err := preloadReport(s.db).First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	reports := []models.Report{report}
+	if err := s.loadReportStats(reports); err != nil {
+		return nil, err
+	}
+	return &reports[0], nil
+}
+
+// Queue はモデレーションキューを返す。未対応の通報は古い順、対応済みの通報は対応が新しい順に並べる。
+func (s *ModerationService) Queue(query models.ReportQuery) ([]models.Report, int64, error) {
+	status := query.Status
+	if status == "" {
+		status = models.ReportStatusOpen
+	}
+	find := s.db.Model(&models.Report{}).Where("status = ?", status)
+
+	var total int64
+	if err := find.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "created_at, id"
+	if status != models.ReportStatusOpen {
+		order = "resolved_at DESC, id DESC"
+	}
+	var reports []models.Report
+	err := preloadReport(find.Session(&gorm.Session{})).
+		Order(order).
+		Limit(query.Limit()).
+		Offset(query.Offset()).
+		Find(&reports).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = s.loadReportStats(reports)
+	return reports, total, err
+}
+
+// loadReportStats は同じ対象（投稿またはユーザー）への未対応の通報数を設定する
+func (s *ModerationService) loadReportStats(reports []models.Report) error {
+	if len(reports) == 0 {
+		return nil
+	}
+	userIDs := make([]uint, 0, len(reports))
+	for _, r := range reports {
+		userIDs = append(userIDs, r.TargetUserID)
+	}
+
+	var rows []struct {
+		TargetType   string
+		MicropostID  uint
+		TargetUserID uint
+		Count        int64
+	}
+	err := s.db.Model(&models.Report{}).
+		Select("target_type, COALESCE(micropost_id, 0) AS micropost_id, target_user_id, COUNT(*) AS count").
+		Where("status = ? AND target_user_id IN ?", models.ReportStatusOpen, userIDs).
+		Group("target_type, COALESCE(micropost_id, 0), target_user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	type target struct {
+		kind        string
+		micropostID uint
+		userID      uint
+	}
+	counts := make(map[target]int64, len(rows))
+	for _, row := range rows {
+		counts[target{row.TargetType, row.MicropostID, row.TargetUserID}] = row.Count
+	}
+	for i := range reports {
+		var micropostID uint
+		if reports[i].MicropostID != nil {
+			micropostID = *reports[i].MicropostID
+		}
+		reports[i].Stats.ReportCount = counts[target{reports[i].TargetType, micropostID, reports[i].TargetUserID}]
+	}
+	return nil
+}
+
+// Resolve は通報に対応し、判断を監査ログに記録する。
+// hide_post は対象の投稿への未対応の通報を、suspend_user は対象のユーザー（とその投稿）への未対応の通報をまとめて措置済みにする。
+// 自動モデレーションの通報を dismiss すると、隠した投稿を元に戻す。
+func (s *ModerationService) Resolve(moderatorID, reportID uint, req models.ModerationRequest) (*models.Report, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var report models.Report
+		if err := tx.First(&report, reportID).Error; err != nil {
+			return err
+		}
+		if report.Status != models.ReportStatusOpen {
+			return ErrReportResolved
+		}
+
+		now := time.Now()
+		action := models.ModerationAction{
+			ModeratorID: &moderatorID,
+			Action:      req.Action,
+			ReportID:    &report.ID,
+			Note:        req.Note,
+		}
+		resolved := tx.Model(&models.Report{}).Where("status = ?", models.ReportStatusOpen)
+		status := models.ReportStatusActioned
+
+		switch req.Action {
+		case models.ModerationActionDismiss:
+			resolved = resolved.Where("id = ?", report.ID)
+			status = models.ReportStatusDismissed
+			// 自動モデレーションの誤判定なら、隠した投稿を元に戻す
+			if report.ReporterID == nil && report.MicropostID != nil {
+				err := tx.Model(&models.Micropost{}).Where("id = ?", *report.MicropostID).Update("shadow_hidden", false).Error
+				if err != nil {
+					return err
+				}
+			}
+		case models.ModerationActionHidePost:
+			if report.MicropostID == nil {
+				return ErrInvalidModerationAction
+			}
+			err := tx.Model(&models.Micropost{}).
+				Where("id = ? AND hidden_at IS NULL", *report.MicropostID).
+				Update("hidden_at", now).Error
+			if err != nil {
+				return err
+			}
+			action.MicropostID = report.MicropostID
+			action.TargetUserID = &report.TargetUserID
+			resolved = resolved.Where("micropost_id = ?", *report.MicropostID)
+		case models.ModerationActionSuspendUser:
+			err := tx.Model(&models.User{}).
+				Where("id = ? AND suspended_at IS NULL", report.TargetUserID).
+				Update("suspended_at", now).Error
+			if err != nil {
+				return err
+			}
+			action.TargetUserID = &report.TargetUserID
+			resolved = resolved.Where("target_user_id = ?", report.TargetUserID)
+		default:
+			return ErrInvalidModerationAction
+		}
+
+		err := resolved.Updates(map[string]interface{}{
+			"status":         status,
+			"resolved_by_id": moderatorID,
+			"resolved_at":    now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&action).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.findReport(reportID)
+}
+
+// Actions は監査ログを新しい順に返す
+func (s *ModerationService) Actions(page models.PageQuery) ([]models.ModerationAction, int64, error) {
+	var total int64
+	if err := s.db.Model(&models.ModerationAction{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var actions []models.ModerationAction
+	err := s.db.Preload("Moderator").
+		Order("created_at DESC, id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Find(&actions).Error
+	return actions, total, err
+}
+
+// checkNotSuspended はユーザーが利用停止中なら ErrAccountSuspended、削除済み（ゴミ箱の中）なら ErrAccountDeleted を返す
+func checkNotSuspended(db *gorm.DB, userID uint) error {
+	var user models.User
+	err := db.Select("id", "suspended_at").First(&user, userID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrAccountDeleted
+	case err != nil:
+		return err
+	case user.SuspendedAt != nil:
+		return ErrAccountSuspended
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+type NotificationService struct {
+	db *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// notify は通知を作成し、作成した通知を返す。自分自身の操作と、受信者から見えないユーザー（ミュート・ブロック）の操作による通知は作らない。
+// 他の service から元の操作と同じトランザクションで呼び出し、コミット後に publishNotifications で配信する。
+func notify(tx *gorm.DB, notifications ...models.Notification) ([]models.Notification, error) {
+	filtered := make([]models.Notification, 0, len(notifications))
+	for _, n := range notifications {
+		if n.UserID == 0 || n.UserID == n.ActorID {
+			continue
+		}
+		var hidden bool
+		err := tx.Raw("SELECT ? IN ("+hiddenUsersSQL+")", n.ActorID, n.UserID, n.UserID, n.UserID).Scan(&hidden).Error
+		if err != nil {
+			return nil, err
+		}
+		if !hidden {
+			filtered = append(filtered, n)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, nil
+	}
+	err := tx.Create(&filtered).Error
+	return filtered, err
+}
+
+// retractNotification は取り消された操作（いいね解除・フォロー解除など）で userID に届いた通知を削除する
+func retractNotification(tx *gorm.DB, notificationType string, userID, actorID uint, micropostID *uint) error {
+	query := tx.Where("type = ? AND user_id = ? AND actor_id = ?", notificationType, userID, actorID)
+	if micropostID != nil {
+		query = query.Where("micropost_id = ?", *micropostID)
+	} else {
+		query = query.Where("micropost_id IS NULL")
+	}
+	return query.Delete(&models.Notification{}).Error
+}
+
+// groupColumns は通知をまとめる単位（同じ GroupKey でも既読と未読は別にまとめる）
+const groupColumns = "group_key, type, micropost_id, (read_at IS NULL)"
+
+// List は通知を GroupKey と既読状態でまとめ、新しい順に返す（ミュート・ブロックしたユーザーの操作は除く）
+func (s *NotificationService) List(userID uint, page models.PageQuery) ([]models.NotificationGroup, int64, error) {
+	grouped := s.db.Model(&models.Notification{}).
+		Select(`MAX(id) AS id, group_key, type, micropost_id, (read_at IS NULL) AS unread,
+			COUNT(DISTINCT actor_id) AS actor_count, MAX(created_at) AS created_at`).
+		Where("user_id = ?", userID).
+		Scopes(excludeHiddenUsers(userID, "actor_id"), excludeDeletedMicroposts("micropost_id")).
+		Group(groupColumns)
+
+	var total int64
+	if err := s.db.Table("(?) AS notification_groups", grouped).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var groups []models.NotificationGroup
+	err := grouped.Session(&gorm.Session{}).
+		Order("created_at DESC, id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Scan(&groups).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = s.loadActors(userID, groups)
+	return groups, total, err
+}
+
+// loadActors は各グループの直近のユーザーを NotificationActorsShown 人まで設定する
+func (s *NotificationService) loadActors(userID uint, groups []models.NotificationGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	type groupID struct {
+		key    string
+		unread bool
+	}
+	keys := make([]string, 0, len(groups))
+	for _, g := range groups {
+		keys = append(keys, g.GroupKey)
+	}
+
+	var rows []struct {
+		GroupKey string
+		Unread   bool
+		ActorID  uint
+	}
+	err := s.db.Model(&models.Notification{}).
+		Select("group_key, (read_at IS NULL) AS unread, actor_id, MAX(created_at) AS latest").
+		Where("user_id = ? AND group_key IN ?", userID, keys).
+		Scopes(excludeHiddenUsers(userID, "actor_id"), excludeDeletedMicroposts("micropost_id")).
+		Group("group_key, (read_at IS NULL), actor_id").
+		Order("latest DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	actorIDs := make(map[groupID][]uint)
+	var ids []uint
+	for _, row := range rows {
+		id := groupID{row.GroupKey, row.Unread}
+		if len(actorIDs[id]) < models.NotificationActorsShown {
+			actorIDs[id] = append(actorIDs[id], row.ActorID)
+			ids = append(ids, row.ActorID)
+		}
+	}
+
+	var users []models.User
+	if err := s.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	for i := range groups {
+		for _, id := range actorIDs[groupID{groups[i].GroupKey, groups[i].Unread}] {
+			if user, ok := byID[id]; ok {
+				groups[i].Actors = append(groups[i].Actors, user)
+			}
+		}
+	}
+	return nil
+}
+
+// UnreadCount は未読の通知をまとめた単位で数える
+func (s *NotificationService) UnreadCount(userID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Scopes(excludeHiddenUsers(userID, "actor_id"), excludeDeletedMicroposts("micropost_id")).
+		Distinct("group_key").
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead は通知と同じグループの未読の通知をすべて既読にする
+func (s *NotificationService) MarkRead(userID, id uint) error {
+	var notification models.Notification
+	if err := s.db.Where("user_id = ?", userID).First(&notification, id).Error; err != nil {
+		return err
+	}
+	return s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND group_key = ? AND read_at IS NULL", userID, notification.GroupKey).
+		Update("read_at", time.Now()).Error
+}
+
+// MarkAllRead は未読の通知をすべて既読にする
+func (s *NotificationService) MarkAllRead(userID uint) error {
+	return s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRetention は全員が処理したイベントをアウトボックスに残す期間（outbox.prune で削除する）
+var OutboxRetention = 7 * 24 * time.Hour
+
+// RecordEvent は tx のトランザクションでイベントをアウトボックスに書き込む（コミットされたときだけ配信される）。
+// イベントには書き込んだトランザクションの ID を記録する（配信する順番と位置に使う）。
+// 書き込みどうしはロックしないので、イベントは ID の順にコミットされるとは限らない。tx はトランザクションを渡す。
+func RecordEvent(tx *gorm.DB, event models.DomainEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var txID uint64
+	if err := tx.Raw("SELECT pg_current_xact_id()::text::bigint").Scan(&txID).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.OutboxEvent{
+		TxID:        txID,
+		Type:        event.EventType(),
+		AggregateID: event.AggregateID(),
+		Payload:     string(payload),
+	}).Error
+}
+
+// EventHandler はアウトボックスのイベントを処理する。tx で書き込んだ変更は購読者の位置と一緒にコミットされるので、
+// DB への反映は1回だけになる。エラーを返すと位置を進めず、次の配信で同じイベントから再試行する。
+type EventHandler func(ctx context.Context, tx *gorm.DB, event *models.OutboxEvent) error
+
+// EventSink はイベントを外部のシステムへまとめて送る。送った後に位置を進めるので、
+// 位置の保存に失敗すると同じイベントを再送することがある（受け取る側はイベントの ID で重複を除く）。
+type EventSink interface {
+	Send(ctx context.Context, events []models.OutboxEvent) error
+}
+
+// eventSubscription は購読者（位置を持つ単位）ごとの配信先
+type eventSubscription struct {
+	consumer string
+	types    []string
+	handler  EventHandler
+	sink     EventSink
+}
+
+// EventDispatcher はアウトボックスのイベントを購読者に順に配信する
+type EventDispatcher struct {
+	db            *gorm.DB
+	batchSize     int
+	subscriptions []eventSubscription
+}
+
+func NewEventDispatcher(db *gorm.DB) *EventDispatcher {
+	return &EventDispatcher{db: db, batchSize: 100}
+}
+
+// Subscribe はプロセス内の購読者を登録する（types を省略するとすべての種類を受け取る）。
+// consumer は位置の保存に使うので、一度決めたら変えない。
+func (d *EventDispatcher) Subscribe(consumer string, handler EventHandler, types ...string) {
+	d.subscriptions = append(d.subscriptions, eventSubscription{consumer: consumer, types: types, handler: handler})
+}
+
+// AddSink は外部のシステムへの送信先を登録する（types を省略するとすべての種類を送る）
+func (d *EventDispatcher) AddSink(consumer string, sink EventSink, types ...string) {
+	d.subscriptions = append(d.subscriptions, eventSubscription{consumer: consumer, types: types, sink: sink})
+}
+
+// Run は ctx が終わるまで interval ごとに未配信のイベントを配信する
+func (d *EventDispatcher) Run(ctx context.Context, interval time.Duration) {
+	runEvery(ctx, interval, func() {
+		if _, err := d.Dispatch(ctx); err != nil {
+			log.Printf("outbox: %v", err)
+		}
+	})
+}
+
+// Dispatch は購読者ごとに未配信のイベントがなくなるまで配信し、配信した件数を返す。
+// ある購読者で失敗しても他の購読者には配信し、最初のエラーを返す。
+func (d *EventDispatcher) Dispatch(ctx context.Context) (int, error) {
+	total := 0
+	var firstErr error
+	for _, sub := range d.subscriptions {
+		for ctx.Err() == nil {
+			delivered, more, err := d.dispatchBatch(ctx, sub)
+			total += delivered
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				break
+			}
+			if !more {
+				break
+			}
+		}
+	}
+	return total, firstErr
+}
+
+// accepts は購読者がイベントの種類を受け取るかどうかを返す
+func (sub eventSubscription) accepts(eventType string) bool {
+	if len(sub.types) == 0 {
+		return true
+	}
+	for _, t := range sub.types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatchBatch は購読者の位置の次から最大 batchSize 件を読み、受け取る種類のイベントを配信して位置を進める。
+// 実行中で最も古いトランザクションより前のトランザクションが書いたイベントだけを (TxID, ID) の順に読むので、
+// 処理済みの位置より前にあとからイベントがコミットされることはない（長いトランザクションがあるとその間は配信が遅れる）。
+// 位置の行を SKIP LOCKED でロックするので、他のプロセスが配信中の購読者は飛ばす。
+// 配信した件数と、続きのイベントがありそうかを返す。
+func (d *EventDispatcher) dispatchBatch(ctx context.Context, sub eventSubscription) (int, bool, error) {
+	delivered := 0
+	more := false
+	var deliverErr error
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		offset := models.OutboxOffset{Consumer: sub.consumer}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&offset).Error; err != nil {
+			return err
+		}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("consumer = ?", sub.consumer).
+			First(&offset).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// 受け取らない種類のイベントも読んで位置を進める（古いイベントを削除できるように）
+		var events []models.OutboxEvent
+		err = tx.Where("(tx_id, id) > (?, ?)", offset.TxID, offset.EventID).
+			Where("tx_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint").
+			Order("tx_id, id").
+			Limit(d.batchSize).
+			Find(&events).Error
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		more = len(events) == d.batchSize
+
+		position := offset
+		if sub.sink != nil {
+			var matched []models.OutboxEvent
+			for _, event := range events {
+				if sub.accepts(event.Type) {
+					matched = append(matched, event)
+				}
+			}
+			if len(matched) > 0 {
+				if deliverErr = sub.sink.Send(ctx, matched); deliverErr != nil {
+					return nil
+				}
+			}
+			delivered = len(matched)
+			position.TxID, position.EventID = events[len(events)-1].TxID, events[len(events)-1].ID
+		} else {
+			// 1件ずつセーブポイントで処理し、失敗したイベントの手前まで位置を進める
+			for i := range events {
+				if sub.accepts(events[i].Type) {
+					deliverErr = tx.Transaction(func(etx *gorm.DB) error {
+						return sub.handler(ctx, etx, &events[i])
+					})
+					if deliverErr != nil {
+						break
+					}
+					delivered++
+				}
+				position.TxID, position.EventID = events[i].TxID, events[i].ID
+			}
+		}
+		if position.TxID == offset.TxID && position.EventID == offset.EventID {
+			return nil
+		}
+		return tx.Model(&offset).Updates(map[string]interface{}{"tx_id": position.TxID, "event_id": position.EventID}).Error
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if deliverErr != nil {
+		return delivered, false, fmt.Errorf("%s: %w", sub.consumer, deliverErr)
+	}
+	return delivered, more, nil
+}
+
+// Prune は登録済みの購読者全員が処理し、before より前に作成したイベントを削除し、削除した件数を返す
+func (d *EventDispatcher) Prune(before time.Time) (int64, error) {
+	if len(d.subscriptions) == 0 {
+		return 0, nil
+	}
+	consumers := make([]string, 0, len(d.subscriptions))
+	for _, sub := range d.subscriptions {
+		consumers = append(consumers, sub.consumer)
+	}
+
+	var offsets []models.OutboxOffset
+	if err := d.db.Where("consumer IN ?", consumers).Find(&offsets).Error; err != nil {
+		return 0, err
+	}
+	// まだ一度も配信していない購読者がいれば削除しない
+	if len(offsets) < len(consumers) {
+		return 0, nil
+	}
+	processed := offsets[0]
+	for _, offset := range offsets[1:] {
+		if offset.TxID < processed.TxID || (offset.TxID == processed.TxID && offset.EventID < processed.EventID) {
+			processed = offset
+		}
+	}
+
+	result := d.db.Where("(tx_id, id) <= (?, ?) AND created_at < ?", processed.TxID, processed.EventID, before).Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// JSONLinesSink はイベントを1行1件の JSON で書き出す（ログ収集基盤へ送るファイルなど）
+type JSONLinesSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{w: w}
+}
+
+func (s *JSONLinesSink) Send(ctx context.Context, events []models.OutboxEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	encoder := json.NewEncoder(s.w)
+	for _, event := range events {
+		line := struct {
+			ID          uint            `json:"id"`
+			Type        string          `json:"type"`
+			AggregateID uint            `json:"aggregate_id"`
+			Payload     json.RawMessage `json:"payload"`
+			CreatedAt   time.Time       `json:"created_at"`
+		}{event.ID, event.Type, event.AggregateID, json.RawMessage(event.Payload), event.CreatedAt}
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+type TagService struct {
+	db *gorm.DB
+}
+
+func NewTagService(db *gorm.DB) *TagService {
+	return &TagService{db: db}
+}
+
+// Trending は直近 window の投稿で使われたタグを、使ったユーザー数・投稿数の多い順に返す。
+// 誰にでも見える投稿だけを数える（下書き・非表示にされた投稿・自動モデレーションで隠した投稿と、
+// 利用停止中やゴミ箱の中のユーザーの投稿は数えない）。
+func (s *TagService) Trending(window time.Duration, limit int) ([]models.TrendingTag, error) {
+	tags := []models.TrendingTag{}
+	err := s.db.Table("micropost_tags").
+		Select("tags.name, COUNT(DISTINCT microposts.id) AS post_count, COUNT(DISTINCT microposts.user_id) AS user_count").
+		Joins("JOIN microposts ON microposts.id = micropost_tags.micropost_id").
+		Joins("JOIN tags ON tags.id = micropost_tags.tag_id").
+		Where("microposts.created_at >= ? AND microposts.deleted_at IS NULL", time.Now().Add(-window)).
+		Scopes(published, excludeModerated(0), excludeDeletedUsers("microposts.user_id")).
+		Group("tags.id, tags.name").
+		Order("user_count DESC, post_count DESC, tags.name").
+		Limit(limit).
+		Scan(&tags).Error
+	return tags, err
+}
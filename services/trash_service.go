@@ -0,0 +1,101 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/utils"
+
+	"gorm.io/gorm"
+)
+
+// ゴミ箱の中（論理削除済み）のユーザーと投稿。
+// GORM の論理削除は生 SQL・サブクエリ・結合先のテーブルには効かないので、これらで明示的に除く。
+const (
+	deletedUsersSQL      = `SELECT id FROM users WHERE deleted_at IS NOT NULL`
+	deletedMicropostsSQL = `SELECT id FROM microposts WHERE deleted_at IS NOT NULL`
+)
+
+// excludeDeletedUsers は column のユーザーが削除済みのものを除く
+func excludeDeletedUsers(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(column + " NOT IN (" + deletedUsersSQL + ")")
+	}
+}
+
+// excludeDeletedMicroposts は column の投稿が削除済みのものを除く（column が NULL の行は残す）
+func excludeDeletedMicroposts(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(" + column + " IS NULL OR " + column + " NOT IN (" + deletedMicropostsSQL + "))")
+	}
+}
+
+type TrashService struct {
+	db *gorm.DB
+}
+
+func NewTrashService(db *gorm.DB) *TrashService {
+	return &TrashService{db: db}
+}
+
+// PurgeResult は purge で完全に削除した件数
+type PurgeResult struct {
+	Microposts int64
+	Users      int64
+}
+
+// Purge は保存期間（models.TrashRetention）を過ぎたゴミ箱の投稿とユーザーを完全に削除する。
+// いいね・フォロー・タグ・メンション・通知などは外部キーの CASCADE で一緒に削除される。
+// ユーザーは投稿ごと1人ずつトランザクションで削除し、コミット後にアバター画像とデータエクスポートのアーカイブを消す。
+// モデレーターとして監査ログに残っているユーザーも削除し、監査ログのモデレーターは NULL になる。
+func (s *TrashService) Purge(now time.Time) (PurgeResult, error) {
+	var result PurgeResult
+	cutoff := now.Add(-models.TrashRetention)
+
+	purged := s.db.Unscoped().Where("deleted_at < ?", cutoff).Delete(&models.Micropost{})
+	if purged.Error != nil {
+		return result, purged.Error
+	}
+	result.Microposts = purged.RowsAffected
+
+	var users []models.User
+	err := s.db.Unscoped().Select("id", "avatar_path").
+		Where("deleted_at < ?", cutoff).
+		Find(&users).Error
+	if err != nil {
+		return result, err
+	}
+	for _, user := range users {
+		id := user.ID
+		var exportFiles []string
+		if err := s.db.Model(&models.DataExport{}).Where("user_id = ? AND file_path <> ''", id).Pluck("file_path", &exportFiles).Error; err != nil {
+			log.Printf("purge: user %d exports: %v", id, err)
+			continue
+		}
+		var microposts int64
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			posts := tx.Unscoped().Where("user_id = ?", id).Delete(&models.Micropost{})
+			if posts.Error != nil {
+				return posts.Error
+			}
+			microposts = posts.RowsAffected
+			return tx.Unscoped().Delete(&models.User{}, id).Error
+		})
+		if err != nil {
+			log.Printf("purge: user %d: %v", id, err)
+			continue
+		}
+		result.Microposts += microposts
+		result.Users++
+		if err := utils.RemoveAvatarFile(user.AvatarPath); err != nil {
+			log.Printf("purge: user %d avatar: %v", id, err)
+		}
+		for _, file := range exportFiles {
+			if err := removeExportFile(file); err != nil {
+				log.Printf("purge: user %d export: %v", id, err)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+// Profile は ID でユーザーを探し、投稿数・フォロー数と閲覧者のフォロー状態を設定する
+func (s *UserService) Profile(id, viewerID uint) (models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, id).Error; err != nil {
+		return user, err
+	}
+	err := loadUserStats(s.db, viewerID, &user)
+	return user, err
+}
+
+// ProfileByHandle はハンドルでユーザーを探し、Profile と同じ集計を設定する
+func (s *UserService) ProfileByHandle(handle string, viewerID uint) (models.User, error) {
+	user, err := s.FindByHandle(handle)
+	if err != nil {
+		return user, err
+	}
+	err = loadUserStats(s.db, viewerID, &user)
+	return user, err
+}
+
+// UpdateProfile はリクエストで指定された項目だけを更新する（ハンドルは他のユーザーと重複不可）
+func (s *UserService) UpdateProfile(userID uint, req models.ProfileUpdateRequest) (models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return user, err
+	}
+
+	if req.Handle != nil && models.HandleKey(*req.Handle) != models.HandleKey(user.Handle) {
+		taken, err := handleInUse(s.db, *req.Handle, user.ID)
+		if err != nil {
+			return user, err
+		}
+		if taken {
+			return user, ErrHandleTaken
+		}
+	}
+
+	req.Apply(&user)
+	err := s.db.Model(&user).
+		Select("Handle", "DisplayName", "Bio", "Location", "Website").
+		Updates(&user).Error
+	if isUniqueViolation(err) {
+		// 確認してから更新するまでに他のユーザーが同じハンドルにした
+		return user, ErrHandleTaken
+	}
+	if err != nil {
+		return user, err
+	}
+	err = loadUserStats(s.db, userID, &user)
+	return user, err
+}
+
+// loadUserStats はユーザーの投稿数・フォロワー数・フォロー数と、閲覧者がフォロー・ブロック・ミュートしているかを設定する
+func loadUserStats(db *gorm.DB, viewerID uint, user *models.User) error {
+	err := db.Model(&models.Micropost{}).
+		Where("user_id = ?", user.ID).
+		Scopes(published).
+		Count(&user.Stats.PostCount).Error
+	if err != nil {
+		return err
+	}
+
+	var row struct {
+		FollowerCount  int64
+		FollowingCount int64
+		FollowedByMe   bool
+	}
+	err = db.Model(&models.Follow{}).
+		Select(`COUNT(*) FILTER (WHERE followed_id = ?) AS follower_count,
+			COUNT(*) FILTER (WHERE follower_id = ?) AS following_count,
+			COALESCE(BOOL_OR(followed_id = ? AND follower_id = ?), false) AS followed_by_me`,
+			user.ID, user.ID, user.ID, viewerID).
+		Where("followed_id = ? OR follower_id = ?", user.ID, user.ID).
+		Scopes(excludeDeletedUsers("follower_id"), excludeDeletedUsers("followed_id")).
+		Scan(&row).Error
+	if err != nil {
+		return err
+	}
+	user.Stats.FollowerCount = row.FollowerCount
+	user.Stats.FollowingCount = row.FollowingCount
+	user.Stats.FollowedByMe = row.FollowedByMe
+
+	if viewerID == 0 || viewerID == user.ID {
+		return nil
+	}
+	var relation struct {
+		BlockedByMe bool
+		MutedByMe   bool
+	}
+	err = db.Raw(`SELECT EXISTS (SELECT 1 FROM blocks WHERE blocker_id = ? AND blocked_id = ?) AS blocked_by_me,
+		EXISTS (SELECT 1 FROM mutes WHERE muter_id = ? AND muted_id = ?) AS muted_by_me`,
+		viewerID, user.ID, viewerID, user.ID).Scan(&relation).Error
+	user.Stats.BlockedByMe = relation.BlockedByMe
+	user.Stats.MutedByMe = relation.MutedByMe
+	return err
+}
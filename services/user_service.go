@@ -1,6 +1,8 @@
 package services
 
 import (
+	"errors"
+
 	"go-gin-gorm-minimum/models"
 
 	"gorm.io/gorm"
@@ -20,6 +22,51 @@ func (s *UserService) FindByEmail(email string) (models.User, error) {
 	return user, err
 }
 
+// EmailTaken はメールアドレスが使われているかどうかを返す（復元できるようゴミ箱の中のユーザーも含む）
+func (s *UserService) EmailTaken(email string) (bool, error) {
+	var count int64
+	err := s.db.Unscoped().Model(&models.User{}).Where("email = ?", email).Count(&count).Error
+	return count > 0, err
+}
+
+// HandleTaken はハンドルが使われているかどうかを返す（大文字小文字を区別せず、ゴミ箱の中のユーザーも含む）
+func (s *UserService) HandleTaken(handle string) (bool, error) {
+	return handleInUse(s.db, handle, 0)
+}
+
+// handleInUse は exceptID 以外のユーザー（ゴミ箱の中を含む）がハンドルを使っているかどうかを返す
+func handleInUse(db *gorm.DB, handle string, exceptID uint) (bool, error) {
+	var count int64
+	err := db.Unscoped().Model(&models.User{}).
+		Where("lower(handle) = ? AND id <> ?", models.HandleKey(handle), exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindByHandle はハンドルでユーザーを探す（大文字小文字を区別しない）
+func (s *UserService) FindByHandle(handle string) (models.User, error) {
+	var user models.User
+	err := s.db.Where("lower(handle) = ?", models.HandleKey(handle)).First(&user).Error
+	return user, err
+}
+
+// Active はユーザーの現在のロールと、ユーザーが存在しゴミ箱の中にないかどうか、利用停止中かどうかを返す（トークンの検証で使う）。
+// 利用停止中のユーザーは管理者でも一般ユーザーのロールとして扱う。
+func (s *UserService) Active(id uint) (string, bool, bool, error) {
+	var user models.User
+	err := s.db.Select("id", "role", "suspended_at").Take(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, false, nil
+	}
+	if err != nil {
+		return "", false, false, err
+	}
+	if user.SuspendedAt != nil {
+		return models.RoleUser, true, true, nil
+	}
+	return user.Role, true, false, nil
+}
+
 func (s *UserService) FindByID(id interface{}) (models.User, error) {
 	var user models.User
 	err := s.db.First(&user, id).Error
@@ -42,3 +89,14 @@ func (s *UserService) UpdateAvatar(userID uint, avatarPath string) (models.User,
 	err := s.db.Save(&user).Error
 	return user, err
 }
+
+func (s *UserService) UpdateLocale(userID uint, locale string) (models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return user, err
+	}
+
+	user.Locale = locale
+	err := s.db.Save(&user).Error
+	return user, err
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"time"
+
+	"go-gin-gorm-minimum/models"
+
+	"gorm.io/gorm"
+)
+
+// Delete はユーザーを投稿ごとゴミ箱に移す
+func (s *UserService) Delete(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Select("id").First(&user, id).Error; err != nil {
+			return err
+		}
+		return trashUser(tx, &user, time.Now())
+	})
+}
+
+// Restore はゴミ箱の中のユーザーを、一緒に削除された投稿とともに元に戻す（保存期間を過ぎていれば ErrRestoreExpired）
+func (s *UserService) Restore(id uint) (models.User, error) {
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&user, id).Error; err != nil {
+			return err
+		}
+		return restoreUser(tx, &user)
+	})
+	return user, err
+}
+
+// trashUser はユーザーと投稿を now の日時でゴミ箱に移す。
+// 投稿にはユーザーと同じ削除日時を設定し、復元時にユーザーと一緒に削除されたものだけを戻せるようにする。
+func trashUser(tx *gorm.DB, user *models.User, now time.Time) error {
+	if err := tx.Model(user).Update("deleted_at", now).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Micropost{}).Where("user_id = ?", user.ID).Update("deleted_at", now).Error; err != nil {
+		return err
+	}
+	return RecordEvent(tx, models.UserDeleted{UserID: user.ID})
+}
+
+// restoreUser はゴミ箱の中のユーザーと一緒に削除された投稿を元に戻し、退会の申請も取り消す
+func restoreUser(tx *gorm.DB, user *models.User) error {
+	if !models.Restorable(user.DeletedAt, time.Now()) {
+		return ErrRestoreExpired
+	}
+
+	err := tx.Unscoped().Model(&models.Micropost{}).
+		Where("user_id = ? AND deleted_at = ?", user.ID, user.DeletedAt.Time).
+		Update("deleted_at", nil).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Unscoped().Model(user).
+		Updates(map[string]interface{}{"deleted_at": nil, "deletion_requested_at": nil}).Error
+	if err != nil {
+		return err
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	user.DeletionRequestedAt = nil
+	return nil
+}
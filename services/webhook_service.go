@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/utils"
+
+	"gorm.io/gorm"
+)
+
+// Webhook の配信で付けるヘッダー
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookAllowPrivateNetworks を true にすると、ループバック・プライベートアドレスへの配信を許可する（開発・テスト用）
+var WebhookAllowPrivateNetworks = false
+
+// webhookClient は Webhook の配信に使う HTTP クライアント。
+// リダイレクトは追わず、接続先のアドレスを接続時に確認して内部ネットワークへの配信を防ぐ。
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		Proxy:       nil,
+		DialContext: (&net.Dialer{Timeout: 5 * time.Second, Control: checkWebhookAddress}).DialContext,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// checkWebhookAddress は名前解決後の接続先が外部のアドレスかどうかを確認する
+func checkWebhookAddress(network, address string, _ syscall.RawConn) error {
+	if WebhookAllowPrivateNetworks {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w: %s", ErrWebhookAddressBlocked, host)
+	}
+	return nil
+}
+
+// webhookJob は webhook.deliver ジョブの Payload
+type webhookJob struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+type WebhookService struct {
+	db *gorm.DB
+}
+
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{db: db}
+}
+
+// Create は Webhook を登録する（Global は管理者だけ、Secret を省略すると生成する）
+func (s *WebhookService) Create(userID uint, isAdmin bool, req models.WebhookRequest) (*models.Webhook, error) {
+	if req.Global && !isAdmin {
+		return nil, ErrForbidden
+	}
+	secret := req.Secret
+	if secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	webhook := models.Webhook{
+		UserID:     userID,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     secret,
+		Global:     req.Global,
+		Active:     true,
+	}
+	if err := s.db.Create(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// List はユーザーが登録した Webhook を新しい順に返す
+func (s *WebhookService) List(userID uint, page models.PageQuery) ([]models.Webhook, int64, error) {
+	query := s.db.Model(&models.Webhook{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var webhooks []models.Webhook
+	err := query.Session(&gorm.Session{}).
+		Order("id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Find(&webhooks).Error
+	return webhooks, total, err
+}
+
+// Get はユーザー本人の Webhook を返す（他のユーザーのものは見つからないものとして扱う）
+func (s *WebhookService) Get(id, userID uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := s.db.Where("user_id = ?", userID).First(&webhook, id).Error
+	return &webhook, err
+}
+
+// Update はユーザー本人の Webhook の指定された項目を更新する
+func (s *WebhookService) Update(id, userID uint, req models.WebhookUpdateRequest) (*models.Webhook, error) {
+	webhook, err := s.Get(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	req.Apply(webhook)
+	err = s.db.Model(webhook).Select("url", "event_types", "active").Updates(webhook).Error
+	return webhook, err
+}
+
+// Delete はユーザー本人の Webhook と配信ログを削除する
+func (s *WebhookService) Delete(id, userID uint) error {
+	result := s.db.Where("user_id = ?", userID).Delete(&models.Webhook{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Deliveries はユーザー本人の Webhook の配信ログを新しい順に返す
+func (s *WebhookService) Deliveries(id, userID uint, page models.PageQuery) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.Get(id, userID); err != nil {
+		return nil, 0, err
+	}
+	query := s.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", id)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := query.Session(&gorm.Session{}).
+		Order("id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// Redeliver は配信を同じ本文で送り直す（新しい時刻で署名する）。送信待ちの配信は ErrWebhookDeliveryPending
+func (s *WebhookService) Redeliver(id, deliveryID, userID uint) (*models.WebhookDelivery, error) {
+	if _, err := s.Get(id, userID); err != nil {
+		return nil, err
+	}
+
+	var delivery models.WebhookDelivery
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).First(&delivery, deliveryID).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&delivery).
+			Where("status <> ?", models.WebhookDeliveryPending).
+			Update("status", models.WebhookDeliveryPending)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWebhookDeliveryPending
+		}
+		delivery.Status = models.WebhookDeliveryPending
+		return enqueueWebhookDelivery(tx, delivery.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// FanOut はドメインイベントを受け取る Webhook ごとに配信ログを作り、配信ジョブを登録する（EventDispatcher の購読者）。
+// 利用者の Webhook は本人が関わるイベントだけ、Global の Webhook はすべてのイベントを受け取る（削除済みのユーザーの Webhook には送らない）。
+func (s *WebhookService) FanOut(ctx context.Context, tx *gorm.DB, event *models.OutboxEvent) error {
+	var subject struct {
+		UserID     uint `json:"user_id"`
+		AuthorID   uint `json:"author_id"`
+		FollowerID uint `json:"follower_id"`
+		FollowedID uint `json:"followed_id"`
+	}
+	if err := json.Unmarshal([]byte(event.Payload), &subject); err != nil {
+		return err
+	}
+	var related []uint
+	for _, id := range []uint{subject.UserID, subject.AuthorID, subject.FollowerID, subject.FollowedID} {
+		if id != 0 {
+			related = append(related, id)
+		}
+	}
+
+	var webhooks []models.Webhook
+	err := tx.Where("active AND event_types @> jsonb_build_array(CAST(? AS text))", event.Type).
+		Where("global OR user_id IN ?", related).
+		Scopes(excludeDeletedUsers("user_id")).
+		Find(&webhooks).Error
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	body, err := json.Marshal(models.WebhookPayload{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      json.RawMessage(event.Payload),
+	})
+	if err != nil {
+		return err
+	}
+	for _, webhook := range webhooks {
+		delivery := models.WebhookDelivery{
+			WebhookID: webhook.ID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   string(body),
+			Status:    models.WebhookDeliveryPending,
+		}
+		if err := tx.Create(&delivery).Error; err != nil {
+			return err
+		}
+		if err := enqueueWebhookDelivery(tx, delivery.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func enqueueWebhookDelivery(tx *gorm.DB, deliveryID uint) error {
+	_, err := EnqueueJob(tx, JobTypeWebhookDeliver, webhookJob{DeliveryID: deliveryID}, JobOptions{MaxAttempts: models.WebhookMaxAttempts})
+	return err
+}
+
+// Deliver は配信ログの本文を Webhook の URL に送り、結果を記録する（webhook.deliver ジョブの処理）。
+// 2xx 以外の応答と通信エラーはエラーを返してジョブの再試行に任せ、最後の試行で失敗すると failed にする。
+func (s *WebhookService) Deliver(ctx context.Context, job *models.Job, payload webhookJob) error {
+	var delivery models.WebhookDelivery
+	err := s.db.Preload("Webhook").First(&delivery, payload.DeliveryID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Webhook ごと削除された
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delivery.Status != models.WebhookDeliveryPending {
+		return nil
+	}
+	if !delivery.Webhook.Active {
+		return s.db.Model(&delivery).Updates(map[string]interface{}{
+			"status": models.WebhookDeliveryFailed,
+			"error":  "webhook is inactive",
+		}).Error
+	}
+
+	started := time.Now()
+	status, responseBody, sendErr := sendWebhook(ctx, &delivery)
+	updates := map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"response_status": status,
+		"response_body":   responseBody,
+		"error":           "",
+		"duration_ms":     time.Since(started).Milliseconds(),
+	}
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.WebhookDeliverySucceeded
+		updates["delivered_at"] = time.Now()
+	case job.LastAttempt():
+		updates["status"] = models.WebhookDeliveryFailed
+		updates["error"] = sendErr.Error()
+	default:
+		updates["error"] = sendErr.Error()
+	}
+	if err := s.db.Model(&delivery).Updates(updates).Error; err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// sendWebhook は署名付きで本文を送り、応答コードと応答本文（先頭だけ）を返す。2xx 以外はエラーにする
+func sendWebhook(ctx context.Context, delivery *models.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-gin-gorm-minimum-webhook/1")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, utils.SignWebhook(delivery.Webhook.Secret, timestamp, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, models.WebhookResponseBodyMax))
+	// text 型に保存できるよう、不正な UTF-8 と NUL を取り除く
+	responseBody := strings.ReplaceAll(strings.ToValidUTF8(string(data), ""), "\x00", "")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, responseBody, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, responseBody, nil
+}
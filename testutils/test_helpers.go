@@ -30,11 +30,11 @@ func TestMain(m *testing.M) {
 
 func CleanupDatabase(db *gorm.DB) error {
 	db.Exec("SET CONSTRAINTS ALL DEFERRED")
-	if err := db.Exec("DELETE FROM microposts").Error; err != nil {
-		return err
-	}
-	if err := db.Exec("DELETE FROM users").Error; err != nil {
-		return err
+	// 依存する側のテーブルから順に削除する
+	for _, table := range []string{"webhook_deliveries", "webhooks", "outbox_offsets", "outbox_events", "jobs", "data_exports", "moderation_actions", "reports", "messages", "conversation_participants", "conversations", "mutes", "blocks", "follows", "notifications", "mentions", "likes", "micropost_tags", "tags", "micropost_revisions", "microposts", "users"} {
+		if err := db.Exec("DELETE FROM " + table).Error; err != nil {
+			return err
+		}
 	}
 	db.Exec("SET CONSTRAINTS ALL IMMEDIATE")
 	return nil
@@ -73,6 +73,145 @@ func SetupMicropostHandler() (*gin.Engine, *handlers.MicropostHandler, *services
 	return r, micropostHandler, authService
 }
 
+// SetupLikeHandler はLikeHandlerとその依存関係をセットアップします
+func SetupLikeHandler() (*gin.Engine, *handlers.LikeHandler) {
+	if err := CleanupDatabase(TestDB); err != nil {
+		panic(err)
+	}
+
+	likeService := services.NewLikeService(TestDB)
+	likeHandler := handlers.NewLikeHandler(likeService)
+	r := SetupTestRouter()
+
+	return r, likeHandler
+}
+
+// SetupTagHandler はTagHandlerとその依存関係をセットアップします
+func SetupTagHandler() (*gin.Engine, *handlers.TagHandler) {
+	if err := CleanupDatabase(TestDB); err != nil {
+		panic(err)
+	}
+
+	tagService := services.NewTagService(TestDB)
+	micropostService := services.NewMicropostService(TestDB)
+	tagHandler := handlers.NewTagHandler(tagService, micropostService)
+	r := SetupTestRouter()
+
+	return r, tagHandler
+}
+
+// SetupFollowHandler はFollowHandlerとその依存関係をセットアップします
+func SetupFollowHandler() (*gin.Engine, *handlers.FollowHandler) {
+	if err := CleanupDatabase(TestDB); err != nil {
+		panic(err)
+	}
+
+	followService := services.NewFollowService(TestDB)
+	followHandler := handlers.NewFollowHandler(followService)
+	r := SetupTestRouter()
+
+	return r, followHandler
+}
+
+// SetupBlockHandler はBlockHandlerとその依存関係をセットアップします
+func SetupBlockHandler() (*gin.Engine, *handlers.BlockHandler) {
+	if err := CleanupDatabase(TestDB); err != nil {
+		panic(err)
+	}
+
+	blockService := services.NewBlockService(TestDB)
+	blockHandler := handlers.NewBlockHandler(blockService)
+	r := SetupTestRouter()
+
+	return r, blockHandler
+}
+
+// SetupNotificationHandler はNotificationHandlerとその依存関係をセットアップします
+func SetupNotificationHandler() (*gin.Engine, *handlers.NotificationHandler) {
+	if err := CleanupDatabase(TestDB); err != nil {
+		panic(err)
+	}
+
+	notificationService := services.NewNotificationService(TestDB)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	r := SetupTestRouter()
+
+	return r, notificationHandler
+}
+
+// SetupConversationHandler はConversationHandlerとその依存関係をセットアップします
+func SetupConversationHandler() (*gin.Engine, *handlers.ConversationHandler) {
+	if err := CleanupDatabase(TestDB); err != nil {
+		panic(err)
+	}
+
+	conversationService := services.NewConversationService(TestDB)
+	conversationHandler := handlers.NewConversationHandler(conversationService)
+	r := SetupTestRouter()
+
+	return r, conversationHandler
+}
+
+// SetupModerationHandler はModerationHandlerとその依存関係をセットアップします
+func SetupModerationHandler() (*gin.Engine, *handlers.ModerationHandler) {
+	if err := CleanupDatabase(TestDB); err != nil {
+		panic(err)
+	}
+
+	moderationService := services.NewModerationService(TestDB)
+	moderationHandler := handlers.NewModerationHandler(moderationService)
+	r := SetupTestRouter()
+
+	return r, moderationHandler
+}
+
+// SetupExportHandler はExportHandlerとその依存関係をセットアップします（アーカイブは dir に保存する）。
+// アーカイブはジョブで作成されるので、返した JobQueue の Drain で処理する。
+func SetupExportHandler(dir string) (*gin.Engine, *handlers.ExportHandler, *services.ExportService, *services.JobQueue) {
+	if err := CleanupDatabase(TestDB); err != nil {
+		panic(err)
+	}
+
+	exportService := services.NewExportService(TestDB, dir)
+	queue := services.NewJobQueue(TestDB)
+	services.RegisterJobs(queue, TestDB, exportService, services.NewEventDispatcher(TestDB))
+	exportHandler := handlers.NewExportHandler(exportService)
+	r := SetupTestRouter()
+
+	return r, exportHandler, exportService, queue
+}
+
+// SetupJobHandler はJobHandlerとその依存関係をセットアップします（ジョブの処理は呼び出し側で登録する）
+func SetupJobHandler() (*gin.Engine, *handlers.JobHandler, *services.JobQueue) {
+	if err := CleanupDatabase(TestDB); err != nil {
+		panic(err)
+	}
+
+	queue := services.NewJobQueue(TestDB)
+	jobHandler := handlers.NewJobHandler(queue)
+	r := SetupTestRouter()
+
+	return r, jobHandler, queue
+}
+
+// SetupWebhookHandler はWebhookHandlerとその依存関係をセットアップします。
+// イベントの配信は返した EventDispatcher の Dispatch、Webhook への送信は JobQueue の Drain で行う。
+func SetupWebhookHandler() (*gin.Engine, *handlers.WebhookHandler, *services.EventDispatcher, *services.JobQueue) {
+	if err := CleanupDatabase(TestDB); err != nil {
+		panic(err)
+	}
+
+	webhookService := services.NewWebhookService(TestDB)
+	dispatcher := services.NewEventDispatcher(TestDB)
+	dispatcher.Subscribe("webhooks", webhookService.FanOut)
+	queue := services.NewJobQueue(TestDB)
+	services.RegisterJobs(queue, TestDB, services.NewExportService(TestDB, os.TempDir()), dispatcher)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	r := SetupTestRouter()
+
+	return r, webhookHandler, dispatcher, queue
+}
+
 // SetupAuthHandler はAuthHandlerとその依存関係をセットアップします
 func SetupAuthHandler() (*gin.Engine, *handlers.AuthHandler) {
 	if err := CleanupDatabase(TestDB); err != nil {
@@ -91,6 +230,7 @@ func SetupAuthHandler() (*gin.Engine, *handlers.AuthHandler) {
 func CreateTestUser() (*models.User, string, error) {
 	user := &models.User{
 		Email:    "test@example.com",
+		Handle:   "testuser",
 		Password: "password123",
 	}
 
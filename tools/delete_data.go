@@ -20,15 +20,27 @@ func DeleteData() {
 		log.Fatal("failed to connect database:", err)
 	}
 
-	// ユーザーテーブルの削除処理
+	// 監査ログはモデレーターの削除を妨げるため先に削除する
+	if err := db.Exec("DELETE FROM moderation_actions").Error; err != nil {
+		log.Printf("Error deleting moderation actions: %v\n", err)
+	}
+
+	// ジョブとアウトボックスはユーザーと外部キーでつながっていないので別に削除する
+	for _, table := range []string{"jobs", "outbox_events", "outbox_offsets"} {
+		if err := db.Exec("DELETE FROM " + table).Error; err != nil {
+			log.Printf("Error deleting %s: %v\n", table, err)
+		}
+	}
+
+	// ユーザーテーブルの削除処理（ゴミ箱の中のユーザーも完全に削除する）
 	var users []models.User
-	if result := db.Find(&users); result.Error != nil {
+	if result := db.Unscoped().Find(&users); result.Error != nil {
 		log.Fatal("failed to fetch users:", result.Error)
 	}
 
 	for _, user := range users {
 		fmt.Printf("Deleting user ID: %d, Email: %s\n", user.ID, user.Email)
-		if err := db.Delete(&user).Error; err != nil {
+		if err := db.Unscoped().Delete(&user).Error; err != nil {
 			log.Printf("Error deleting user %d: %v\n", user.ID, err)
 		}
 	}
@@ -36,20 +48,20 @@ func DeleteData() {
 
 	// マイクロポストテーブルの削除処理
 	var microposts []models.Micropost
-	if result := db.Find(&microposts); result.Error != nil {
+	if result := db.Unscoped().Find(&microposts); result.Error != nil {
 		log.Fatal("failed to fetch microposts:", result.Error)
 	}
 
 	for _, micropost := range microposts {
 		fmt.Printf("Deleting micropost ID: %d, Title: %s\n", micropost.ID, micropost.Title)
-		if err := db.Delete(&micropost).Error; err != nil {
+		if err := db.Unscoped().Delete(&micropost).Error; err != nil {
 			log.Printf("Error deleting micropost %d: %v\n", micropost.ID, err)
 		}
 	}
 	fmt.Printf("\nTotal microposts deleted: %d\n", len(microposts))
 
 	// シーケンスのリセット
-	sequences := []string{"users_id_seq", "microposts_id_seq"}
+	sequences := []string{"users_id_seq", "microposts_id_seq", "micropost_revisions_id_seq", "likes_id_seq", "tags_id_seq", "mentions_id_seq", "notifications_id_seq", "follows_id_seq", "blocks_id_seq", "mutes_id_seq", "conversations_id_seq", "conversation_participants_id_seq", "messages_id_seq", "reports_id_seq", "moderation_actions_id_seq", "data_exports_id_seq", "jobs_id_seq", "outbox_events_id_seq", "webhooks_id_seq", "webhook_deliveries_id_seq"}
 	for _, seq := range sequences {
 		if err := db.Exec(fmt.Sprintf("ALTER SEQUENCE %s RESTART WITH 1", seq)).Error; err != nil {
 			log.Printf("Error resetting sequence %s: %v\n", seq, err)
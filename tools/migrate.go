@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"log"
 
-	"go-gin-gorm-minimum/models"
+	"go-gin-gorm-minimum/infra"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -21,16 +21,16 @@ func Migrate() {
 	// マイグレーションの実行
 	fmt.Println("Starting database migration...")
 
-	// 1. まず既存のテーブルをドロップ
-	db.Migrator().DropTable(&models.Micropost{}, &models.User{})
+	// 1. まず既存のテーブルをドロップ（依存する側から順に）
+	// many2many の中間テーブルはモデルに含まれないので先に削除する
+	db.Migrator().DropTable("micropost_tags")
+	tables := infra.MigrationModels()
+	for i := len(tables) - 1; i >= 0; i-- {
+		db.Migrator().DropTable(tables[i])
+	}
 
 	// 2. テーブルを再作成
-	err = db.AutoMigrate(
-		&models.User{},
-		&models.Micropost{},
-	)
-
-	if err != nil {
+	if err := infra.Migrate(db); err != nil {
 		log.Fatal("failed to migrate database:", err)
 	}
 
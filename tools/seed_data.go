@@ -34,10 +34,13 @@ func SeedUsersAndMicroposts(db *gorm.DB, hashedPassword string) {
 	users := []models.User{
 		{
 			Email:    "user1@example.com",
+			Handle:   "user1",
 			Password: hashedPassword,
+			Role:     models.RoleAdmin,
 		},
 		{
 			Email:    "user2@example.com",
+			Handle:   "user2",
 			Password: hashedPassword,
 		},
 	}
@@ -54,10 +57,12 @@ func SeedUsersAndMicroposts(db *gorm.DB, hashedPassword string) {
 		microposts := []models.Micropost{
 			{
 				Title:  fmt.Sprintf("First post by %s", user.Email),
+				Body:   "はじめての投稿です。よろしくお願いします！",
 				UserID: user.ID, // ユーザーIDを設定
 			},
 			{
 				Title:  fmt.Sprintf("Second post by %s", user.Email),
+				Body:   "今日は良い天気ですね。",
 				UserID: user.ID, // ユーザーIDを設定
 			},
 		}
@@ -1,13 +1,22 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"mime/multipart"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 )
 
+// DefaultAvatarPath is the shared avatar new users start with. It is never removed.
+const DefaultAvatarPath = "/avatars/default.png"
+
+// AvatarUploadDir is the directory uploaded avatars are saved in, relative to the working directory.
+const AvatarUploadDir = "uploads/avatars"
+
 // 許可する画像の拡張子
 var allowedExtensions = map[string]bool{
 	".jpg":  true,
@@ -28,3 +37,30 @@ func GenerateAvatarFilename(userID uint, originalFilename string) string {
 	timestamp := time.Now().Unix()
 	return fmt.Sprintf("user_%d_%d%s", userID, timestamp, ext)
 }
+
+// UploadedAvatarFile returns the file on disk for an avatar path.
+// It reports false for the default avatar and for any path that does not resolve to a file directly inside AvatarUploadDir.
+func UploadedAvatarFile(avatarPath string) (string, bool) {
+	if avatarPath == "" || avatarPath == DefaultAvatarPath {
+		return "", false
+	}
+	file := filepath.Join(".", filepath.FromSlash(avatarPath))
+	if filepath.Dir(file) != filepath.FromSlash(AvatarUploadDir) {
+		return "", false
+	}
+	return file, true
+}
+
+// RemoveAvatarFile deletes an uploaded avatar from disk.
+// Empty paths, the default avatar, paths outside AvatarUploadDir and files that are already gone are ignored.
+func RemoveAvatarFile(avatarPath string) error {
+	file, ok := UploadedAvatarFile(avatarPath)
+	if !ok {
+		return nil
+	}
+	err := os.Remove(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
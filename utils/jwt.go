@@ -13,14 +13,17 @@ var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 
 // GenerateJWTToken creates a new JWT token for the given user
 func GenerateJWTToken(user models.User) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"sub":   user.ID,
 		"email": user.Email,
+		"role":  user.Role,
 		"exp":   time.Now().Add(time.Hour).Unix(),
-	})
-	signedToken, err := token.SignedString(jwtSecret)
-	fmt.Println("token:Bearer", signedToken)
-	return signedToken, err
+	}
+	if user.Locale != "" {
+		claims["locale"] = user.Locale
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
 }
 
 // ParseJWTToken validates and parses the given JWT token
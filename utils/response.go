@@ -1,10 +1,46 @@
 package utils
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"net/http"
 
-// Common error responses
-var (
-	ErrInvalidCredentials = gin.H{"error": "Invalid email or password"}
-	ErrUnauthorized       = gin.H{"error": "Unauthorized"}
-	ErrRecordNotFound     = gin.H{"error": "Record not found!"}
+	"go-gin-gorm-minimum/i18n"
+
+	"github.com/gin-gonic/gin"
 )
+
+// ErrorJSON writes {"error": <localized message>, "code": <code>} using the request locale
+func ErrorJSON(c *gin.Context, status int, code string, args ...interface{}) {
+	c.JSON(status, gin.H{
+		"error": i18n.T(i18n.FromContext(c), code, args...),
+		"code":  code,
+	})
+}
+
+// AbortWithErrorJSON is ErrorJSON for middlewares that must stop the chain
+func AbortWithErrorJSON(c *gin.Context, status int, code string, args ...interface{}) {
+	ErrorJSON(c, status, code, args...)
+	c.Abort()
+}
+
+// BindingErrorJSON writes a localized message for an error returned by ShouldBind*.
+// Bodies cut off by the BodySizeLimit middleware are reported as 413 regardless of status.
+func BindingErrorJSON(c *gin.Context, status int, err error) {
+	if limit, ok := RequestTooLarge(err); ok {
+		ErrorJSON(c, http.StatusRequestEntityTooLarge, i18n.ErrRequestTooLarge, limit)
+		return
+	}
+	c.JSON(status, gin.H{
+		"error": i18n.TranslateBindingError(i18n.FromContext(c), err),
+		"code":  i18n.ErrInvalidRequest,
+	})
+}
+
+// RequestTooLarge reports whether err was caused by reading past http.MaxBytesReader, and its limit
+func RequestTooLarge(err error) (int64, bool) {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return maxBytesError.Limit, true
+	}
+	return 0, false
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// WebhookSignaturePrefix prefixes the hex digest in the signature header
+const WebhookSignaturePrefix = "sha256="
+
+// SignWebhook returns the signature of a webhook delivery: HMAC-SHA256 over "<timestamp>.<body>" keyed with the secret.
+// Including the timestamp lets receivers reject replayed deliveries.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return WebhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature matches the delivery, in constant time
+func VerifyWebhookSignature(secret, timestamp string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, WebhookSignaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(SignWebhook(secret, timestamp, body)), []byte(signature))
+}